@@ -18,6 +18,7 @@ package scheme
 
 import (
 	"bytes"
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -30,6 +31,7 @@ import (
 
 	"sigs.k8s.io/scheduler-plugins/apis/config"
 	v1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
+	"sigs.k8s.io/scheduler-plugins/pkg/capacityscheduling"
 	"sigs.k8s.io/scheduler-plugins/pkg/coscheduling"
 	"sigs.k8s.io/scheduler-plugins/pkg/networkaware/networkoverhead"
 	"sigs.k8s.io/scheduler-plugins/pkg/networkaware/topologicalsort"
@@ -74,6 +76,15 @@ profiles:
 - schedulerName: scheduler-plugins
   pluginConfig:
   - name: Coscheduling # Test argument defaulting logic
+  - name: CapacityScheduling
+    args:
+      victimCostModel:
+        type: WorkLost
+        runtimeWeight: 2
+      enableBorrowing: false
+      trackedResources:
+      - cpu
+      - nvidia.com/gpu
   - name: TopologicalSort
     args:
       namespaces:
@@ -96,6 +107,23 @@ profiles:
 								PermitWaitingTimeSeconds: 60,
 							},
 						},
+						{
+							Name: capacityscheduling.Name,
+							Args: &config.CapacitySchedulingArgs{
+								VictimCostModel: config.VictimCostModel{
+									Type:                        config.VictimCostModelWorkLost,
+									RuntimeWeight:               2,
+									RestartsWeight:              1,
+									CheckpointWeight:            1,
+									QoSWeight:                   1,
+									RuntimeNormalizationSeconds: 3600,
+								},
+								EnableBorrowing:           false,
+								TrackedResources:          []corev1.ResourceName{corev1.ResourceCPU, "nvidia.com/gpu"},
+								AllowCrossQuotaPreemption: true,
+								UpperBoundOfMax:           math.MaxInt64,
+							},
+						},
 						{
 							Name: topologicalsort.Name,
 							Args: &config.TopologicalSortArgs{
@@ -378,38 +406,61 @@ profiles:
     name: NodeResourcesAllocatable
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
+      cacheCleanupIntervalMinutes: 0
       defaultRequests:
         cpu: "1"
       defaultRequestsMultiplier: "1.8"
       kind: TargetLoadPackingArgs
+      maxTargetUtilization: 0
       metricProvider:
         address: http://prometheus-k8s.monitoring.svc.cluster.local:9090
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsAgentReportingIntervalSeconds: 0
+      metricsFetchTimeoutSeconds: 0
+      metricsMaxBackoffSeconds: 0
+      metricsStalenessThresholdSeconds: 0
+      metricsUpdateIntervalSeconds: 0
+      minTargetUtilization: 0
       targetUtilization: 60
       watcherAddress: http://deadbeef:2020
     name: TargetLoadPacking
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
+      cacheCleanupIntervalMinutes: 0
+      debugAddress: ""
       kind: LoadVariationRiskBalancingArgs
+      maxSafeVarianceMargin: 0
       metricProvider:
         address: http://prometheus-k8s.monitoring.svc.cluster.local:9090
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsAgentReportingIntervalSeconds: 0
+      metricsFetchTimeoutSeconds: 0
+      metricsMaxBackoffSeconds: 0
+      metricsStalenessThresholdSeconds: 0
+      metricsUpdateIntervalSeconds: 0
+      minSafeVarianceMargin: 0
       safeVarianceMargin: 1
       safeVarianceSensitivity: 1
       watcherAddress: http://deadbeef:2020
     name: LoadVariationRiskBalancing
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
+      cacheCleanupIntervalMinutes: 0
       kind: LowRiskOverCommitmentArgs
       metricProvider:
         address: http://prometheus-k8s.monitoring.svc.cluster.local:9090
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsAgentReportingIntervalSeconds: 0
+      metricsFetchTimeoutSeconds: 0
+      metricsMaxBackoffSeconds: 0
+      metricsStalenessThresholdSeconds: 0
+      metricsUpdateIntervalSeconds: 0
       riskLimitWeights:
         cpu: 0.5
         memory: 0.5
@@ -68,6 +68,8 @@ const (
 	KubernetesMetricsServer MetricProviderType = "KubernetesMetricsServer"
 	Prometheus              MetricProviderType = "Prometheus"
 	SignalFx                MetricProviderType = "SignalFx"
+	// PrometheusNative queries Prometheus directly, without load watcher.
+	PrometheusNative MetricProviderType = "PrometheusNative"
 )
 
 // Denote the spec of the metric provider
@@ -80,14 +82,82 @@ type MetricProviderSpec struct {
 	Token string
 	// Whether to enable the InsureSkipVerify options for https requests on Metric Providers.
 	InsecureSkipVerify bool
+	// The queries of the PrometheusNative metric provider
+	PrometheusQueries *PrometheusQueries
 }
 
+// PrometheusQueries holds the PromQL queries used by the PrometheusNative metric provider.
+// The queries are Go templates, where {{.Window}} is replaced by the window. Each query must
+// return a vector of node utilization ratios, between 0 and 1, labelled with the node name.
+type PrometheusQueries struct {
+	// Window over which the queries aggregate the node utilization, e.g. 15m
+	Window string
+	// Label of the query results holding the node name
+	NodeLabel string
+	// Query of the average CPU utilization of the nodes over the window
+	CPUAverage string
+	// Query of the standard deviation of the CPU utilization of the nodes over the window
+	CPUStd string
+	// Query of the average memory utilization of the nodes over the window
+	MemoryAverage string
+	// Query of the standard deviation of the memory utilization of the nodes over the window
+	MemoryStd string
+}
+
+// StaleMetricsPolicyType is a "string" type.
+type StaleMetricsPolicyType string
+
+const (
+	// StaleMetricsMinScore gives the node the minimum score, and lets it pass the filters.
+	StaleMetricsMinScore StaleMetricsPolicyType = "MinScore"
+	// StaleMetricsFallbackToRequests scores the node based on the requests of its pods instead of its utilization.
+	StaleMetricsFallbackToRequests StaleMetricsPolicyType = "FallbackToRequests"
+	// StaleMetricsNeutralScore gives the node the score halfway between the minimum and maximum scores.
+	StaleMetricsNeutralScore StaleMetricsPolicyType = "NeutralScore"
+	// StaleMetricsFilter filters the node out, if the Filter extension point of the plugin is enabled,
+	// and gives it the minimum score otherwise.
+	StaleMetricsFilter StaleMetricsPolicyType = "Filter"
+)
+
 // TrimaranSpec holds common parameters for trimaran plugins
 type TrimaranSpec struct {
 	// Metric Provider to use when using load watcher as a library
 	MetricProvider MetricProviderSpec
 	// Address of load watcher service
 	WatcherAddress string
+	// Age, in seconds, after which the metrics of a node are stale; they are never stale if 0
+	MetricsStalenessThresholdSeconds int64
+	// Policy for the nodes whose metrics are stale or missing
+	StaleMetricsPolicy StaleMetricsPolicyType
+	// Interval, in seconds, between two updates of the metrics
+	MetricsUpdateIntervalSeconds int64
+	// Timeout, in seconds, of an update of the metrics
+	MetricsFetchTimeoutSeconds int64
+	// Maximum interval, in seconds, between two updates of the metrics when they fail
+	MetricsMaxBackoffSeconds int64
+	// Interval, in minutes, between two cleanups of the cache of the pods scheduled recently
+	CacheCleanupIntervalMinutes int64
+	// Interval, in seconds, between two ingestions of the metrics agent
+	MetricsAgentReportingIntervalSeconds int64
+	// Prediction of the utilization of the nodes from the history of their metrics; disabled if nil
+	Prediction *PredictionSpec
+}
+
+// PredictionSpec holds the parameters of the prediction of the utilization of the nodes, by Holt-Winters
+// exponential smoothing of the history of their metrics.
+type PredictionSpec struct {
+	// Number of metrics updates kept in the history of each node
+	HistorySize int64
+	// Length, in seconds, of the seasonality of the utilization, e.g. 86400 for daily; no seasonality if 0
+	SeasonSeconds int64
+	// Time, in seconds, over which the utilization is forecast, i.e. the expected lifetime of the pods
+	HorizonSeconds int64
+	// Smoothing factor of the level, between 0 and 1
+	LevelSmoothing float64
+	// Smoothing factor of the trend, between 0 and 1
+	TrendSmoothing float64
+	// Smoothing factor of the seasonality, between 0 and 1
+	SeasonalSmoothing float64
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -104,6 +174,36 @@ type TargetLoadPackingArgs struct {
 	DefaultRequestsMultiplier string
 	// Node target CPU Utilization for bin packing
 	TargetUtilization int64
+	// Resources to bin pack, with their target utilization and weight; CPU with TargetUtilization if empty
+	Resources []TargetLoadPackingResource
+	// Usage profiles of the workloads learned from the usage of their pods, instead of the requests of the pods
+	// and DefaultRequestsMultiplier; disabled if nil
+	UsageProfiles *UsageProfilesSpec
+	// Bounds of the target utilization a pod may set with its target utilization annotation, overriding
+	// TargetUtilization, the targets of the bin packed resources being scaled by the same ratio; overrides disabled if MaxTargetUtilization is 0
+	MinTargetUtilization int64
+	MaxTargetUtilization int64
+}
+
+// TargetLoadPackingResource holds the target utilization and weight of a resource bin packed by TargetLoadPacking.
+type TargetLoadPackingResource struct {
+	// Name of the resource, cpu, memory or an extended resource reported by the metric provider
+	Name v1.ResourceName
+	// Node target utilization of the resource for bin packing
+	TargetUtilization int64
+	// Weight of the resource in the score
+	Weight int64
+}
+
+// UsageProfilesSpec holds the parameters of the usage profiles of the workloads, i.e. of the pods with the same
+// controller, learned from the usage of their pods reported by the resource metrics API.
+type UsageProfilesSpec struct {
+	// Interval, in seconds, between two updates of the usage profiles
+	UpdateIntervalSeconds int64
+	// Smoothing factor of the usage of the pods of a workload, between 0 and 1, i.e. the weight of the latest usage
+	Smoothing float64
+	// Number of updates a workload is observed in before its usage profile is used
+	MinSamples int64
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -118,6 +218,12 @@ type LoadVariationRiskBalancingArgs struct {
 	SafeVarianceMargin float64
 	// Root power of standard deviation in risk value
 	SafeVarianceSensitivity float64
+	// Address of the debug endpoint serving the score breakdowns of the latest scored pods, disabled if empty
+	DebugAddress string
+	// Bounds of the safe variance margin a pod may set with its safe variance margin annotation; overrides
+	// disabled if MaxSafeVarianceMargin is 0
+	MinSafeVarianceMargin float64
+	MaxSafeVarianceMargin float64
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -132,6 +238,21 @@ type LowRiskOverCommitmentArgs struct {
 	SmoothingWindowSize int64
 	// Resources fractional weight of risk due to limits specification [0,1]
 	RiskLimitWeights map[v1.ResourceName]float64
+	// Maximum ratio of the limits of the pods on a node to its allocatable, by resource,
+	// beyond which the node is filtered out; no maximum for the resources not listed
+	MaxOvercommitRatios map[v1.ResourceName]float64
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LoadCeilingArgs holds arguments used to configure LoadCeiling plugin.
+type LoadCeilingArgs struct {
+	metav1.TypeMeta
+
+	// Common parameters for trimaran plugins
+	TrimaranSpec
+	// Hard ceilings of the predicted node utilization, in percent, by resource
+	UtilizationCeilings map[v1.ResourceName]int64
 }
 
 // ScoringStrategyType is a "string" type.
@@ -146,6 +267,9 @@ const (
 	LeastAllocated ScoringStrategyType = "LeastAllocated"
 	// LeastNUMANodes strategy favors nodes which requires least amount of NUMA nodes to satisfy resource requests for given pod
 	LeastNUMANodes ScoringStrategyType = "LeastNUMANodes"
+	// PreferredNUMANodes strategy scores nodes like LeastNUMANodes, but favors first the nodes running the restricted or
+	// best-effort Topology Manager policies where the kubelet computes a preferred NUMA affinity for given pod
+	PreferredNUMANodes ScoringStrategyType = "PreferredNUMANodes"
 )
 
 // ScoringStrategy define ScoringStrategyType for node resource topology plugin
@@ -192,6 +316,14 @@ const (
 	CacheResyncScopeOnlyResources CacheResyncScope = "OnlyResources"
 )
 
+// CacheReserveMode is a "string" type
+type CacheReserveMode string
+
+const (
+	CacheReservePessimistic CacheReserveMode = "Pessimistic"
+	CacheReserveNUMAZones   CacheReserveMode = "NUMAZones"
+)
+
 // NodeResourceTopologyCache define configuration details for the NodeResourceTopology cache.
 type NodeResourceTopologyCache struct {
 	// ForeignPodsDetect sets how foreign pods should be handled.
@@ -219,6 +351,17 @@ type NodeResourceTopologyCache struct {
 	// "All" to make the code react to node config changes avoiding reboots.
 	// Use "OnlyResources" to restore the previous behavior.
 	ResyncScope *CacheResyncScope
+	// ReserveMode controls on which NUMA zones the resources of the pods are reserved until the next resync.
+	// "Pessimistic" reserves them on all the NUMA zones of the node, because the kubelet may allocate them
+	// on any of them. "NUMAZones" reserves them only on the NUMA zones the filter aligned them to, falling
+	// back to all the NUMA zones when the filter could not decide the zones unambiguously.
+	// Has no effect if caching is disabled (CacheResyncPeriod is zero) or if DiscardReservedNodes
+	// is enabled. If unspecified, default is "Pessimistic".
+	ReserveMode *CacheReserveMode
+	// DebugAddress is the address of the debug endpoint serving the state of the cache per node,
+	// disabled if empty. Has no effect if caching is disabled (CacheResyncPeriod is zero) or
+	// if DiscardReservedNodes is enabled.
+	DebugAddress *string
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -279,3 +422,76 @@ type SySchedArgs struct {
 	// CR name of the default profile for all system calls
 	DefaultProfileName string
 }
+
+// VictimCostModelType is a "string" type.
+type VictimCostModelType string
+
+const (
+	// VictimCostModelPriority orders the victims of a preemption by priority only.
+	VictimCostModelPriority VictimCostModelType = "Priority"
+	// VictimCostModelWorkLost orders the victims of a preemption by priority, then by
+	// the amount of work lost by evicting them. Among the nodes whose highest victim priority
+	// is the lowest, the preemption happens on the one whose victims lose the least work.
+	VictimCostModelWorkLost VictimCostModelType = "WorkLost"
+)
+
+// VictimCostModel defines how CapacityScheduling estimates the cost of evicting a pod.
+type VictimCostModel struct {
+	// Type selects the cost model.
+	Type VictimCostModelType
+	// RuntimeWeight is the weight of the time the pod has been running.
+	RuntimeWeight int64
+	// RestartsWeight is the weight of the pod not having restarted.
+	RestartsWeight int64
+	// CheckpointWeight is the weight of the pod not being checkpointed.
+	CheckpointWeight int64
+	// QoSWeight is the weight of the QoS class of the pod.
+	QoSWeight int64
+	// RuntimeNormalizationSeconds is the runtime from which the runtime factor is at its maximum.
+	RuntimeNormalizationSeconds int64
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CapacitySchedulingArgs holds arguments used to configure CapacityScheduling plugin.
+type CapacitySchedulingArgs struct {
+	metav1.TypeMeta
+
+	// VictimCostModel selects how the victims of a preemption are ordered.
+	VictimCostModel VictimCostModel
+	// EnableBorrowing allows a quota to use more than its min, up to its max,
+	// out of the resources not used by the other quotas.
+	EnableBorrowing bool
+	// TrackedResources are the resources accounted against the quotas.
+	// All the resources are tracked if empty.
+	TrackedResources []v1.ResourceName
+	// AllowCrossQuotaPreemption allows a pod whose quota uses less than its min
+	// to preempt the pods of other quotas that use more than their min.
+	AllowCrossQuotaPreemption bool
+	// DefaultQuota is the quota applied to the namespaces without an ElasticQuota.
+	// The pods of those namespaces aren't accounted if nil.
+	DefaultQuota *DefaultQuota
+	// UpperBoundOfMax is the max of the resources a quota doesn't set a max for,
+	// in the base unit of the resource, millicores for cpu.
+	UpperBoundOfMax int64
+	// LowerBoundOfMin is the min of the resources a quota doesn't set a min for,
+	// in the base unit of the resource, millicores for cpu.
+	LowerBoundOfMin int64
+}
+
+// DefaultQuota defines the quota of the namespaces without an ElasticQuota.
+type DefaultQuota struct {
+	// Min is the guaranteed resources of the namespace, or of all of them if Shared.
+	Min v1.ResourceList
+	// Max is the upper bound of the resources used by the namespace, or by all of them if Shared.
+	Max v1.ResourceList
+	// Shared makes all the namespaces without an ElasticQuota share a single catch-all
+	// quota, instead of each of them having its own. The catch-all quota counts toward
+	// the sum of min of all quotas, and its pods can be preempted by the pods of the
+	// quotas that use less than their min.
+	Shared bool
+	// ExcludedNamespaces are the namespaces without an ElasticQuota that the default quota
+	// doesn't apply to, whether Shared or not: their pods aren't accounted, can't be preempted
+	// as pods of the catch-all quota and don't add to the sum of min of all quotas.
+	ExcludedNamespaces []string
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	"math"
 	"strconv"
 
 	v1 "k8s.io/api/core/v1"
@@ -49,6 +50,14 @@ var (
 	DefaultRequestsMultiplier = "1.5"
 	// DefaultTargetUtilizationPercent Recommended to keep -10 than desired limit.
 	DefaultTargetUtilizationPercent int64 = 40
+	// DefaultTargetLoadPackingResourceWeight is the weight of a resource bin packed by TargetLoadPacking
+	DefaultTargetLoadPackingResourceWeight int64 = 1
+	// DefaultUsageProfilesUpdateIntervalSeconds is the interval between two updates of the usage profiles
+	DefaultUsageProfilesUpdateIntervalSeconds int64 = 60
+	// DefaultUsageProfilesSmoothing is the weight of the latest usage of the pods of a workload
+	DefaultUsageProfilesSmoothing = 0.3
+	// DefaultUsageProfilesMinSamples is the number of updates a workload is observed in before its profile is used
+	DefaultUsageProfilesMinSamples int64 = 3
 
 	// Defaults for LoadVariationRiskBalancing plugin
 
@@ -75,10 +84,53 @@ var (
 		v1.ResourceMemory: DefaultRiskLimitWeight,
 	}
 
+	// Defaults for LoadCeiling plugin
+
+	// DefaultUtilizationCeiling is the hard ceiling of the predicted utilization of a resource, in percent
+	DefaultUtilizationCeiling int64 = 90
+
 	// DefaultMetricProviderType is the Kubernetes metrics server
 	DefaultMetricProviderType = KubernetesMetricsServer
 	// DefaultInsecureSkipVerify is whether to skip the certificate verification
 	DefaultInsecureSkipVerify = true
+	// DefaultMetricsStalenessThresholdSeconds never makes the metrics stale
+	DefaultMetricsStalenessThresholdSeconds int64 = 0
+	// DefaultStaleMetricsPolicy avoids the nodes without metrics, as the plugins always did
+	DefaultStaleMetricsPolicy = StaleMetricsMinScore
+	// DefaultMetricsUpdateIntervalSeconds is the interval between two updates of the metrics
+	DefaultMetricsUpdateIntervalSeconds int64 = 30
+	// DefaultMetricsFetchTimeoutSeconds is the timeout of an update of the metrics
+	DefaultMetricsFetchTimeoutSeconds int64 = 10
+	// DefaultMetricsMaxBackoffSeconds is the maximum interval between two updates of the metrics when they fail
+	DefaultMetricsMaxBackoffSeconds int64 = 300
+	// DefaultCacheCleanupIntervalMinutes is the maximum staleness of metrics possible by load watcher
+	DefaultCacheCleanupIntervalMinutes int64 = 5
+	// DefaultMetricsAgentReportingIntervalSeconds is the interval between two ingestions of the metrics agent
+	DefaultMetricsAgentReportingIntervalSeconds int64 = 60
+	// DefaultPredictionHistorySize is one hour of metrics updates at the default interval
+	DefaultPredictionHistorySize int64 = 120
+	// DefaultPredictionSeasonSeconds disables the seasonality of the prediction
+	DefaultPredictionSeasonSeconds int64 = 0
+	// DefaultPredictionHorizonSeconds is the time over which the utilization is forecast
+	DefaultPredictionHorizonSeconds int64 = 600
+	// DefaultPredictionLevelSmoothing is the smoothing factor of the level of the utilization
+	DefaultPredictionLevelSmoothing = 0.5
+	// DefaultPredictionTrendSmoothing is the smoothing factor of the trend of the utilization
+	DefaultPredictionTrendSmoothing = 0.1
+	// DefaultPredictionSeasonalSmoothing is the smoothing factor of the seasonality of the utilization
+	DefaultPredictionSeasonalSmoothing = 0.1
+	// DefaultPrometheusWindow is the window of the PrometheusNative queries
+	DefaultPrometheusWindow = "15m"
+	// DefaultPrometheusNodeLabel is the label holding the node name in the results of the PrometheusNative queries
+	DefaultPrometheusNodeLabel = "instance"
+	// DefaultPrometheusCPUAverageQuery uses the node-exporter recording rule of the CPU utilization
+	DefaultPrometheusCPUAverageQuery = "avg_over_time(instance:node_cpu:ratio[{{.Window}}])"
+	// DefaultPrometheusCPUStdQuery uses the node-exporter recording rule of the CPU utilization
+	DefaultPrometheusCPUStdQuery = "stddev_over_time(instance:node_cpu:ratio[{{.Window}}])"
+	// DefaultPrometheusMemoryAverageQuery uses the node-exporter recording rule of the memory utilization
+	DefaultPrometheusMemoryAverageQuery = "avg_over_time(instance:node_memory_utilisation:ratio[{{.Window}}])"
+	// DefaultPrometheusMemoryStdQuery uses the node-exporter recording rule of the memory utilization
+	DefaultPrometheusMemoryStdQuery = "stddev_over_time(instance:node_memory_utilisation:ratio[{{.Window}}])"
 
 	defaultResourceSpec = []schedulerconfigv1.ResourceSpec{
 		{Name: string(v1.ResourceCPU), Weight: 1},
@@ -91,6 +143,8 @@ var (
 
 	defaultInformerMode = CacheInformerDedicated
 
+	defaultReserveMode = CacheReservePessimistic
+
 	// Defaults for NetworkOverhead
 	// DefaultWeightsName contains the default costs to be used by networkAware plugins
 	DefaultWeightsName = "UserDefined"
@@ -102,6 +156,25 @@ var (
 	DefaultSySchedProfileNamespace = "default"
 	// DefaultSySchedProfileName is the name of the default syscall profile CR for SySched plugin
 	DefaultSySchedProfileName = "all-syscalls"
+
+	// Defaults for CapacityScheduling
+
+	// DefaultVictimCostModelType keeps ordering the victims of a preemption by priority only
+	DefaultVictimCostModelType = VictimCostModelPriority
+	// DefaultVictimCostWeight is the default weight of each factor of the WorkLost cost model
+	DefaultVictimCostWeight int64 = 1
+	// DefaultRuntimeNormalizationSeconds is one hour
+	DefaultRuntimeNormalizationSeconds int64 = 3600
+	// DefaultEnableBorrowing lets quotas use the resources not used by the other quotas
+	DefaultEnableBorrowing = true
+	// DefaultAllowCrossQuotaPreemption lets quotas reclaim their min from the other quotas
+	DefaultAllowCrossQuotaPreemption = true
+	// DefaultUpperBoundOfMax leaves the resources without a max unlimited
+	DefaultUpperBoundOfMax int64 = math.MaxInt64
+	// DefaultLowerBoundOfMin guarantees nothing of the resources without a min
+	DefaultLowerBoundOfMin int64 = 0
+	// DefaultExcludedNamespaces keeps the system namespaces out of the default quota
+	DefaultExcludedNamespaces = []string{metav1.NamespaceSystem, metav1.NamespacePublic, v1.NamespaceNodeLease}
 )
 
 // SetDefaults_CoschedulingArgs sets the default parameters for Coscheduling plugin.
@@ -133,6 +206,91 @@ func SetDefaultTrimaranSpec(args *TrimaranSpec) {
 	if args.MetricProvider.Type == Prometheus && args.MetricProvider.InsecureSkipVerify == nil {
 		args.MetricProvider.InsecureSkipVerify = &DefaultInsecureSkipVerify
 	}
+	if args.MetricProvider.Type == PrometheusNative {
+		setDefaultPrometheusQueries(&args.MetricProvider)
+	}
+	if args.MetricsStalenessThresholdSeconds == nil || *args.MetricsStalenessThresholdSeconds < 0 {
+		args.MetricsStalenessThresholdSeconds = &DefaultMetricsStalenessThresholdSeconds
+	}
+	if args.StaleMetricsPolicy == "" {
+		args.StaleMetricsPolicy = DefaultStaleMetricsPolicy
+	}
+	if args.MetricsUpdateIntervalSeconds == nil || *args.MetricsUpdateIntervalSeconds <= 0 {
+		args.MetricsUpdateIntervalSeconds = &DefaultMetricsUpdateIntervalSeconds
+	}
+	if args.MetricsFetchTimeoutSeconds == nil || *args.MetricsFetchTimeoutSeconds <= 0 {
+		args.MetricsFetchTimeoutSeconds = &DefaultMetricsFetchTimeoutSeconds
+	}
+	if args.MetricsMaxBackoffSeconds == nil || *args.MetricsMaxBackoffSeconds < *args.MetricsUpdateIntervalSeconds {
+		// the backoff doesn't shorten the interval between two updates
+		maxBackoffSeconds := max(DefaultMetricsMaxBackoffSeconds, *args.MetricsUpdateIntervalSeconds)
+		args.MetricsMaxBackoffSeconds = &maxBackoffSeconds
+	}
+	if args.CacheCleanupIntervalMinutes == nil || *args.CacheCleanupIntervalMinutes <= 0 {
+		args.CacheCleanupIntervalMinutes = &DefaultCacheCleanupIntervalMinutes
+	}
+	if args.MetricsAgentReportingIntervalSeconds == nil || *args.MetricsAgentReportingIntervalSeconds <= 0 {
+		args.MetricsAgentReportingIntervalSeconds = &DefaultMetricsAgentReportingIntervalSeconds
+	}
+	if args.Prediction != nil {
+		setDefaultPredictionSpec(args.Prediction, *args.MetricsUpdateIntervalSeconds)
+	}
+}
+
+// setDefaultPredictionSpec sets the default parameters of the prediction of the utilization
+func setDefaultPredictionSpec(prediction *PredictionSpec, updateIntervalSeconds int64) {
+	if prediction.SeasonSeconds == nil || *prediction.SeasonSeconds < 0 {
+		prediction.SeasonSeconds = &DefaultPredictionSeasonSeconds
+	}
+	if prediction.HistorySize == nil || *prediction.HistorySize < 2 {
+		historySize := DefaultPredictionHistorySize
+		if *prediction.SeasonSeconds > 0 {
+			// the history holds two seasons, to initialize the seasonality
+			historySize = max(historySize, 2*((*prediction.SeasonSeconds+updateIntervalSeconds-1)/updateIntervalSeconds))
+		}
+		prediction.HistorySize = &historySize
+	}
+	if prediction.HorizonSeconds == nil || *prediction.HorizonSeconds < 0 {
+		prediction.HorizonSeconds = &DefaultPredictionHorizonSeconds
+	}
+	if prediction.LevelSmoothing == nil || *prediction.LevelSmoothing <= 0 || *prediction.LevelSmoothing > 1 {
+		prediction.LevelSmoothing = &DefaultPredictionLevelSmoothing
+	}
+	if prediction.TrendSmoothing == nil || *prediction.TrendSmoothing < 0 || *prediction.TrendSmoothing > 1 {
+		prediction.TrendSmoothing = &DefaultPredictionTrendSmoothing
+	}
+	if prediction.SeasonalSmoothing == nil || *prediction.SeasonalSmoothing < 0 || *prediction.SeasonalSmoothing > 1 {
+		prediction.SeasonalSmoothing = &DefaultPredictionSeasonalSmoothing
+	}
+}
+
+// setDefaultPrometheusQueries sets the default queries of the PrometheusNative metric provider
+func setDefaultPrometheusQueries(metricProvider *MetricProviderSpec) {
+	if metricProvider.InsecureSkipVerify == nil {
+		metricProvider.InsecureSkipVerify = &DefaultInsecureSkipVerify
+	}
+	if metricProvider.PrometheusQueries == nil {
+		metricProvider.PrometheusQueries = &PrometheusQueries{}
+	}
+	queries := metricProvider.PrometheusQueries
+	if queries.Window == nil {
+		queries.Window = &DefaultPrometheusWindow
+	}
+	if queries.NodeLabel == nil {
+		queries.NodeLabel = &DefaultPrometheusNodeLabel
+	}
+	if queries.CPUAverage == nil {
+		queries.CPUAverage = &DefaultPrometheusCPUAverageQuery
+	}
+	if queries.CPUStd == nil {
+		queries.CPUStd = &DefaultPrometheusCPUStdQuery
+	}
+	if queries.MemoryAverage == nil {
+		queries.MemoryAverage = &DefaultPrometheusMemoryAverageQuery
+	}
+	if queries.MemoryStd == nil {
+		queries.MemoryStd = &DefaultPrometheusMemoryStdQuery
+	}
 }
 
 // SetDefaults_TargetLoadPackingArgs sets the default parameters for TargetLoadPacking plugin
@@ -148,6 +306,32 @@ func SetDefaults_TargetLoadPackingArgs(args *TargetLoadPackingArgs) {
 	if args.TargetUtilization == nil || *args.TargetUtilization <= 0 {
 		args.TargetUtilization = &DefaultTargetUtilizationPercent
 	}
+	for i := range args.Resources {
+		r := &args.Resources[i]
+		if r.TargetUtilization == nil || *r.TargetUtilization <= 0 {
+			targetUtilization := *args.TargetUtilization
+			r.TargetUtilization = &targetUtilization
+		}
+		if r.Weight == nil || *r.Weight <= 0 {
+			r.Weight = &DefaultTargetLoadPackingResourceWeight
+		}
+	}
+	if args.UsageProfiles != nil {
+		setDefaultUsageProfilesSpec(args.UsageProfiles)
+	}
+}
+
+// setDefaultUsageProfilesSpec sets the default parameters of the usage profiles of the workloads
+func setDefaultUsageProfilesSpec(usageProfiles *UsageProfilesSpec) {
+	if usageProfiles.UpdateIntervalSeconds == nil || *usageProfiles.UpdateIntervalSeconds <= 0 {
+		usageProfiles.UpdateIntervalSeconds = &DefaultUsageProfilesUpdateIntervalSeconds
+	}
+	if usageProfiles.Smoothing == nil || *usageProfiles.Smoothing <= 0 || *usageProfiles.Smoothing > 1 {
+		usageProfiles.Smoothing = &DefaultUsageProfilesSmoothing
+	}
+	if usageProfiles.MinSamples == nil || *usageProfiles.MinSamples <= 0 {
+		usageProfiles.MinSamples = &DefaultUsageProfilesMinSamples
+	}
 }
 
 // SetDefaults_LoadVariationRiskBalancingArgs sets the default parameters for LoadVariationRiskBalancing plugin
@@ -178,6 +362,17 @@ func SetDefaults_LowRiskOverCommitmentArgs(args *LowRiskOverCommitmentArgs) {
 	}
 }
 
+// SetDefaults_LoadCeilingArgs sets the default parameters for LoadCeiling plugin
+func SetDefaults_LoadCeilingArgs(args *LoadCeilingArgs) {
+	SetDefaultTrimaranSpec(&args.TrimaranSpec)
+	if len(args.UtilizationCeilings) == 0 {
+		args.UtilizationCeilings = map[v1.ResourceName]int64{
+			v1.ResourceCPU:    DefaultUtilizationCeiling,
+			v1.ResourceMemory: DefaultUtilizationCeiling,
+		}
+	}
+}
+
 // SetDefaults_NodeResourceTopologyMatchArgs sets the default parameters for NodeResourceTopologyMatch plugin.
 func SetDefaults_NodeResourceTopologyMatchArgs(obj *NodeResourceTopologyMatchArgs) {
 	if obj.ScoringStrategy == nil {
@@ -211,6 +406,9 @@ func SetDefaults_NodeResourceTopologyMatchArgs(obj *NodeResourceTopologyMatchArg
 	if obj.Cache.InformerMode == nil {
 		obj.Cache.InformerMode = &defaultInformerMode
 	}
+	if obj.Cache.ReserveMode == nil {
+		obj.Cache.ReserveMode = &defaultReserveMode
+	}
 }
 
 // SetDefaults_PreemptionTolerationArgs reuses SetDefaults_DefaultPreemptionArgs
@@ -250,3 +448,41 @@ func SetDefaults_SySchedArgs(obj *SySchedArgs) {
 		obj.DefaultProfileName = &DefaultSySchedProfileName
 	}
 }
+
+// SetDefaults_CapacitySchedulingArgs sets the default parameters for CapacityScheduling plugin.
+func SetDefaults_CapacitySchedulingArgs(obj *CapacitySchedulingArgs) {
+	costModel := &obj.VictimCostModel
+	if costModel.Type == "" {
+		costModel.Type = DefaultVictimCostModelType
+	}
+	if costModel.RuntimeWeight == nil {
+		costModel.RuntimeWeight = &DefaultVictimCostWeight
+	}
+	if costModel.RestartsWeight == nil {
+		costModel.RestartsWeight = &DefaultVictimCostWeight
+	}
+	if costModel.CheckpointWeight == nil {
+		costModel.CheckpointWeight = &DefaultVictimCostWeight
+	}
+	if costModel.QoSWeight == nil {
+		costModel.QoSWeight = &DefaultVictimCostWeight
+	}
+	if costModel.RuntimeNormalizationSeconds == nil || *costModel.RuntimeNormalizationSeconds <= 0 {
+		costModel.RuntimeNormalizationSeconds = &DefaultRuntimeNormalizationSeconds
+	}
+	if obj.EnableBorrowing == nil {
+		obj.EnableBorrowing = &DefaultEnableBorrowing
+	}
+	if obj.AllowCrossQuotaPreemption == nil {
+		obj.AllowCrossQuotaPreemption = &DefaultAllowCrossQuotaPreemption
+	}
+	if obj.UpperBoundOfMax == nil {
+		obj.UpperBoundOfMax = &DefaultUpperBoundOfMax
+	}
+	if obj.LowerBoundOfMin == nil {
+		obj.LowerBoundOfMin = &DefaultLowerBoundOfMin
+	}
+	if obj.DefaultQuota != nil && obj.DefaultQuota.ExcludedNamespaces == nil {
+		obj.DefaultQuota.ExcludedNamespaces = append([]string(nil), DefaultExcludedNamespaces...)
+	}
+}
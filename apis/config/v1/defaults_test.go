@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	"math"
 	"strconv"
 	"testing"
 
@@ -86,7 +87,15 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
 				DefaultRequests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(
 					strconv.FormatInt(DefaultRequestsMilliCores, 10) + "m")},
 				DefaultRequestsMultiplier: pointer.StringPtr("1.5"),
@@ -104,12 +113,125 @@ func TestSchedulingDefaults(t *testing.T) {
 			},
 			expect: &TargetLoadPackingArgs{
 				TrimaranSpec: TrimaranSpec{
-					WatcherAddress: pointer.StringPtr("http://localhost:2020")},
+					WatcherAddress:                       pointer.StringPtr("http://localhost:2020"),
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
 				DefaultRequests:           v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
 				DefaultRequestsMultiplier: pointer.StringPtr("2.5"),
 				TargetUtilization:         pointer.Int64Ptr(50),
 			},
 		},
+		{
+			name: "PrometheusNative TargetLoadPackingArgs",
+			config: &TargetLoadPackingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricProvider: MetricProviderSpec{
+						Type:    PrometheusNative,
+						Address: pointer.StringPtr("http://prometheus:9090"),
+						PrometheusQueries: &PrometheusQueries{
+							Window: pointer.StringPtr("5m"),
+						},
+					}},
+			},
+			expect: &TargetLoadPackingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricProvider: MetricProviderSpec{
+						Type:               PrometheusNative,
+						Address:            pointer.StringPtr("http://prometheus:9090"),
+						InsecureSkipVerify: pointer.BoolPtr(true),
+						PrometheusQueries: &PrometheusQueries{
+							Window:        pointer.StringPtr("5m"),
+							NodeLabel:     pointer.StringPtr("instance"),
+							CPUAverage:    pointer.StringPtr("avg_over_time(instance:node_cpu:ratio[{{.Window}}])"),
+							CPUStd:        pointer.StringPtr("stddev_over_time(instance:node_cpu:ratio[{{.Window}}])"),
+							MemoryAverage: pointer.StringPtr("avg_over_time(instance:node_memory_utilisation:ratio[{{.Window}}])"),
+							MemoryStd:     pointer.StringPtr("stddev_over_time(instance:node_memory_utilisation:ratio[{{.Window}}])"),
+						},
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
+				DefaultRequests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(
+					strconv.FormatInt(DefaultRequestsMilliCores, 10) + "m")},
+				DefaultRequestsMultiplier: pointer.StringPtr("1.5"),
+				TargetUtilization:         pointer.Int64Ptr(40),
+			},
+		},
+		{
+			name: "resources TargetLoadPackingArgs",
+			config: &TargetLoadPackingArgs{
+				TargetUtilization: pointer.Int64Ptr(50),
+				Resources: []TargetLoadPackingResource{
+					{Name: v1.ResourceCPU},
+					{Name: v1.ResourceMemory, TargetUtilization: pointer.Int64Ptr(70), Weight: pointer.Int64Ptr(2)},
+				},
+			},
+			expect: &TargetLoadPackingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricProvider: MetricProviderSpec{
+						Type: "KubernetesMetricsServer",
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
+				DefaultRequests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(
+					strconv.FormatInt(DefaultRequestsMilliCores, 10) + "m")},
+				DefaultRequestsMultiplier: pointer.StringPtr("1.5"),
+				TargetUtilization:         pointer.Int64Ptr(50),
+				Resources: []TargetLoadPackingResource{
+					{Name: v1.ResourceCPU, TargetUtilization: pointer.Int64Ptr(50), Weight: pointer.Int64Ptr(1)},
+					{Name: v1.ResourceMemory, TargetUtilization: pointer.Int64Ptr(70), Weight: pointer.Int64Ptr(2)},
+				},
+			},
+		},
+		{
+			name: "usage profiles TargetLoadPackingArgs",
+			config: &TargetLoadPackingArgs{
+				UsageProfiles: &UsageProfilesSpec{
+					Smoothing:  pointer.Float64Ptr(1.5),
+					MinSamples: pointer.Int64Ptr(5),
+				},
+			},
+			expect: &TargetLoadPackingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricProvider: MetricProviderSpec{
+						Type: "KubernetesMetricsServer",
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
+				DefaultRequests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(
+					strconv.FormatInt(DefaultRequestsMilliCores, 10) + "m")},
+				DefaultRequestsMultiplier: pointer.StringPtr("1.5"),
+				TargetUtilization:         pointer.Int64Ptr(40),
+				UsageProfiles: &UsageProfilesSpec{
+					UpdateIntervalSeconds: pointer.Int64Ptr(60),
+					Smoothing:             pointer.Float64Ptr(0.3),
+					MinSamples:            pointer.Int64Ptr(5),
+				},
+			},
+		},
 		{
 			name:   "empty config LoadVariationRiskBalancingArgs",
 			config: &LoadVariationRiskBalancingArgs{},
@@ -117,7 +239,15 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
 				SafeVarianceMargin:      pointer.Float64Ptr(1.0),
 				SafeVarianceSensitivity: pointer.Float64Ptr(1.0),
 			},
@@ -132,11 +262,81 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
 				SafeVarianceMargin:      pointer.Float64Ptr(2.0),
 				SafeVarianceSensitivity: pointer.Float64Ptr(2.0),
 			},
 		},
+		{
+			name: "set non default intervals LoadVariationRiskBalancingArgs",
+			config: &LoadVariationRiskBalancingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(600),
+					MetricsFetchTimeoutSeconds:   pointer.Int64Ptr(-1),
+					CacheCleanupIntervalMinutes:  pointer.Int64Ptr(1),
+				},
+			},
+			expect: &LoadVariationRiskBalancingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricProvider: MetricProviderSpec{
+						Type: "KubernetesMetricsServer",
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(600),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(600),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(1),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
+				SafeVarianceMargin:      pointer.Float64Ptr(1.0),
+				SafeVarianceSensitivity: pointer.Float64Ptr(1.0),
+			},
+		},
+		{
+			name: "prediction LoadVariationRiskBalancingArgs",
+			config: &LoadVariationRiskBalancingArgs{
+				TrimaranSpec: TrimaranSpec{
+					Prediction: &PredictionSpec{
+						SeasonSeconds:  pointer.Int64Ptr(86400),
+						LevelSmoothing: pointer.Float64Ptr(2),
+						TrendSmoothing: pointer.Float64Ptr(0),
+					},
+				},
+			},
+			expect: &LoadVariationRiskBalancingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricProvider: MetricProviderSpec{
+						Type: "KubernetesMetricsServer",
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+					Prediction: &PredictionSpec{
+						HistorySize:       pointer.Int64Ptr(5760),
+						SeasonSeconds:     pointer.Int64Ptr(86400),
+						HorizonSeconds:    pointer.Int64Ptr(600),
+						LevelSmoothing:    pointer.Float64Ptr(0.5),
+						TrendSmoothing:    pointer.Float64Ptr(0),
+						SeasonalSmoothing: pointer.Float64Ptr(0.1),
+					},
+				},
+				SafeVarianceMargin:      pointer.Float64Ptr(1.0),
+				SafeVarianceSensitivity: pointer.Float64Ptr(1.0),
+			},
+		},
 		{
 			name:   "empty config LowRiskOverCommitmentArgs",
 			config: &LowRiskOverCommitmentArgs{},
@@ -144,7 +344,15 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
 				SmoothingWindowSize: pointer.Int64Ptr(5),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.5,
@@ -165,7 +373,15 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
 				SmoothingWindowSize: pointer.Int64Ptr(10),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.2,
@@ -186,7 +402,15 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
 				SmoothingWindowSize: pointer.Int64Ptr(10),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.5,
@@ -194,6 +418,55 @@ func TestSchedulingDefaults(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "empty config LoadCeilingArgs",
+			config: &LoadCeilingArgs{},
+			expect: &LoadCeilingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricProvider: MetricProviderSpec{
+						Type: "KubernetesMetricsServer",
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
+				UtilizationCeilings: map[v1.ResourceName]int64{
+					v1.ResourceCPU:    90,
+					v1.ResourceMemory: 90,
+				},
+			},
+		},
+		{
+			name: "keep out of range LoadCeilingArgs",
+			config: &LoadCeilingArgs{
+				UtilizationCeilings: map[v1.ResourceName]int64{
+					v1.ResourceCPU:    80,
+					v1.ResourceMemory: 120,
+				},
+			},
+			expect: &LoadCeilingArgs{
+				TrimaranSpec: TrimaranSpec{
+					MetricProvider: MetricProviderSpec{
+						Type: "KubernetesMetricsServer",
+					},
+					MetricsStalenessThresholdSeconds:     pointer.Int64Ptr(0),
+					StaleMetricsPolicy:                   StaleMetricsMinScore,
+					MetricsUpdateIntervalSeconds:         pointer.Int64Ptr(30),
+					MetricsFetchTimeoutSeconds:           pointer.Int64Ptr(10),
+					MetricsMaxBackoffSeconds:             pointer.Int64Ptr(300),
+					CacheCleanupIntervalMinutes:          pointer.Int64Ptr(5),
+					MetricsAgentReportingIntervalSeconds: pointer.Int64Ptr(60),
+				},
+				UtilizationCeilings: map[v1.ResourceName]int64{
+					v1.ResourceCPU:    80,
+					v1.ResourceMemory: 120,
+				},
+			},
+		},
 		{
 			name:   "empty config NodeResourceTopologyMatchArgs",
 			config: &NodeResourceTopologyMatchArgs{},
@@ -206,6 +479,7 @@ func TestSchedulingDefaults(t *testing.T) {
 					ForeignPodsDetect: &defaultForeignPodsDetect,
 					ResyncMethod:      &defaultResyncMethod,
 					InformerMode:      &defaultInformerMode,
+					ReserveMode:       &defaultReserveMode,
 				},
 			},
 		},
@@ -274,6 +548,90 @@ func TestSchedulingDefaults(t *testing.T) {
 				DefaultProfileName:      pointer.StringPtr("all-syscalls"),
 			},
 		},
+		{
+			name:   "empty config CapacitySchedulingArgs",
+			config: &CapacitySchedulingArgs{},
+			expect: &CapacitySchedulingArgs{
+				VictimCostModel: VictimCostModel{
+					Type:                        VictimCostModelPriority,
+					RuntimeWeight:               pointer.Int64Ptr(1),
+					RestartsWeight:              pointer.Int64Ptr(1),
+					CheckpointWeight:            pointer.Int64Ptr(1),
+					QoSWeight:                   pointer.Int64Ptr(1),
+					RuntimeNormalizationSeconds: pointer.Int64Ptr(3600),
+				},
+				EnableBorrowing:           pointer.BoolPtr(true),
+				AllowCrossQuotaPreemption: pointer.BoolPtr(true),
+				UpperBoundOfMax:           pointer.Int64Ptr(math.MaxInt64),
+				LowerBoundOfMin:           pointer.Int64Ptr(0),
+			},
+		},
+		{
+			name: "shared DefaultQuota of CapacitySchedulingArgs",
+			config: &CapacitySchedulingArgs{
+				DefaultQuota: &DefaultQuota{
+					Max:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+					Shared: true,
+				},
+			},
+			expect: &CapacitySchedulingArgs{
+				VictimCostModel: VictimCostModel{
+					Type:                        VictimCostModelPriority,
+					RuntimeWeight:               pointer.Int64Ptr(1),
+					RestartsWeight:              pointer.Int64Ptr(1),
+					CheckpointWeight:            pointer.Int64Ptr(1),
+					QoSWeight:                   pointer.Int64Ptr(1),
+					RuntimeNormalizationSeconds: pointer.Int64Ptr(3600),
+				},
+				EnableBorrowing:           pointer.BoolPtr(true),
+				AllowCrossQuotaPreemption: pointer.BoolPtr(true),
+				DefaultQuota: &DefaultQuota{
+					Max:                v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+					Shared:             true,
+					ExcludedNamespaces: []string{"kube-system", "kube-public", "kube-node-lease"},
+				},
+				UpperBoundOfMax: pointer.Int64Ptr(math.MaxInt64),
+				LowerBoundOfMin: pointer.Int64Ptr(0),
+			},
+		},
+		{
+			name: "set non default CapacitySchedulingArgs",
+			config: &CapacitySchedulingArgs{
+				VictimCostModel: VictimCostModel{
+					Type:                        VictimCostModelWorkLost,
+					RuntimeWeight:               pointer.Int64Ptr(3),
+					CheckpointWeight:            pointer.Int64Ptr(0),
+					RuntimeNormalizationSeconds: pointer.Int64Ptr(600),
+				},
+				EnableBorrowing:           pointer.BoolPtr(false),
+				TrackedResources:          []v1.ResourceName{v1.ResourceCPU},
+				AllowCrossQuotaPreemption: pointer.BoolPtr(false),
+				DefaultQuota: &DefaultQuota{
+					Max: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+				UpperBoundOfMax: pointer.Int64Ptr(100000),
+				LowerBoundOfMin: pointer.Int64Ptr(10),
+			},
+			expect: &CapacitySchedulingArgs{
+				VictimCostModel: VictimCostModel{
+					Type:                        VictimCostModelWorkLost,
+					RuntimeWeight:               pointer.Int64Ptr(3),
+					RestartsWeight:              pointer.Int64Ptr(1),
+					CheckpointWeight:            pointer.Int64Ptr(0),
+					QoSWeight:                   pointer.Int64Ptr(1),
+					RuntimeNormalizationSeconds: pointer.Int64Ptr(600),
+				},
+				EnableBorrowing:           pointer.BoolPtr(false),
+				TrackedResources:          []v1.ResourceName{v1.ResourceCPU},
+				AllowCrossQuotaPreemption: pointer.BoolPtr(false),
+				DefaultQuota: &DefaultQuota{
+					Max:                v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+					ExcludedNamespaces: []string{"kube-system", "kube-public", "kube-node-lease"},
+				},
+				UpperBoundOfMax: pointer.Int64Ptr(100000),
+				LowerBoundOfMin: pointer.Int64Ptr(10),
+			},
+		},
 	}
 
 	for _, tc := range tests {
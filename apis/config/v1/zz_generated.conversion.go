@@ -40,6 +40,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*CapacitySchedulingArgs)(nil), (*config.CapacitySchedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(a.(*CapacitySchedulingArgs), b.(*config.CapacitySchedulingArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.CapacitySchedulingArgs)(nil), (*CapacitySchedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(a.(*config.CapacitySchedulingArgs), b.(*CapacitySchedulingArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CoschedulingArgs)(nil), (*config.CoschedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_CoschedulingArgs_To_config_CoschedulingArgs(a.(*CoschedulingArgs), b.(*config.CoschedulingArgs), scope)
 	}); err != nil {
@@ -50,6 +60,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*DefaultQuota)(nil), (*config.DefaultQuota)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_DefaultQuota_To_config_DefaultQuota(a.(*DefaultQuota), b.(*config.DefaultQuota), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.DefaultQuota)(nil), (*DefaultQuota)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_DefaultQuota_To_v1_DefaultQuota(a.(*config.DefaultQuota), b.(*DefaultQuota), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*LoadCeilingArgs)(nil), (*config.LoadCeilingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_LoadCeilingArgs_To_config_LoadCeilingArgs(a.(*LoadCeilingArgs), b.(*config.LoadCeilingArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.LoadCeilingArgs)(nil), (*LoadCeilingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_LoadCeilingArgs_To_v1_LoadCeilingArgs(a.(*config.LoadCeilingArgs), b.(*LoadCeilingArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*LoadVariationRiskBalancingArgs)(nil), (*config.LoadVariationRiskBalancingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_LoadVariationRiskBalancingArgs_To_config_LoadVariationRiskBalancingArgs(a.(*LoadVariationRiskBalancingArgs), b.(*config.LoadVariationRiskBalancingArgs), scope)
 	}); err != nil {
@@ -110,6 +140,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PredictionSpec)(nil), (*config.PredictionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_PredictionSpec_To_config_PredictionSpec(a.(*PredictionSpec), b.(*config.PredictionSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.PredictionSpec)(nil), (*PredictionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_PredictionSpec_To_v1_PredictionSpec(a.(*config.PredictionSpec), b.(*PredictionSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PreemptionTolerationArgs)(nil), (*config.PreemptionTolerationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_PreemptionTolerationArgs_To_config_PreemptionTolerationArgs(a.(*PreemptionTolerationArgs), b.(*config.PreemptionTolerationArgs), scope)
 	}); err != nil {
@@ -120,6 +160,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PrometheusQueries)(nil), (*config.PrometheusQueries)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_PrometheusQueries_To_config_PrometheusQueries(a.(*PrometheusQueries), b.(*config.PrometheusQueries), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.PrometheusQueries)(nil), (*PrometheusQueries)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_PrometheusQueries_To_v1_PrometheusQueries(a.(*config.PrometheusQueries), b.(*PrometheusQueries), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ScoringStrategy)(nil), (*config.ScoringStrategy)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_ScoringStrategy_To_config_ScoringStrategy(a.(*ScoringStrategy), b.(*config.ScoringStrategy), scope)
 	}); err != nil {
@@ -150,6 +200,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*TargetLoadPackingResource)(nil), (*config.TargetLoadPackingResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(a.(*TargetLoadPackingResource), b.(*config.TargetLoadPackingResource), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.TargetLoadPackingResource)(nil), (*TargetLoadPackingResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(a.(*config.TargetLoadPackingResource), b.(*TargetLoadPackingResource), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*TopologicalSortArgs)(nil), (*config.TopologicalSortArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_TopologicalSortArgs_To_config_TopologicalSortArgs(a.(*TopologicalSortArgs), b.(*config.TopologicalSortArgs), scope)
 	}); err != nil {
@@ -170,6 +230,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*UsageProfilesSpec)(nil), (*config.UsageProfilesSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_UsageProfilesSpec_To_config_UsageProfilesSpec(a.(*UsageProfilesSpec), b.(*config.UsageProfilesSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.UsageProfilesSpec)(nil), (*UsageProfilesSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_UsageProfilesSpec_To_v1_UsageProfilesSpec(a.(*config.UsageProfilesSpec), b.(*UsageProfilesSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*VictimCostModel)(nil), (*config.VictimCostModel)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_VictimCostModel_To_config_VictimCostModel(a.(*VictimCostModel), b.(*config.VictimCostModel), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.VictimCostModel)(nil), (*VictimCostModel)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_VictimCostModel_To_v1_VictimCostModel(a.(*config.VictimCostModel), b.(*VictimCostModel), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*config.NodeResourceTopologyMatchArgs)(nil), (*NodeResourceTopologyMatchArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_config_NodeResourceTopologyMatchArgs_To_v1_NodeResourceTopologyMatchArgs(a.(*config.NodeResourceTopologyMatchArgs), b.(*NodeResourceTopologyMatchArgs), scope)
 	}); err != nil {
@@ -183,6 +263,58 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in *CapacitySchedulingArgs, out *config.CapacitySchedulingArgs, s conversion.Scope) error {
+	if err := Convert_v1_VictimCostModel_To_config_VictimCostModel(&in.VictimCostModel, &out.VictimCostModel, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.EnableBorrowing, &out.EnableBorrowing, s); err != nil {
+		return err
+	}
+	out.TrackedResources = *(*[]corev1.ResourceName)(unsafe.Pointer(&in.TrackedResources))
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.AllowCrossQuotaPreemption, &out.AllowCrossQuotaPreemption, s); err != nil {
+		return err
+	}
+	out.DefaultQuota = (*config.DefaultQuota)(unsafe.Pointer(in.DefaultQuota))
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.UpperBoundOfMax, &out.UpperBoundOfMax, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.LowerBoundOfMin, &out.LowerBoundOfMin, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs is an autogenerated conversion function.
+func Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in *CapacitySchedulingArgs, out *config.CapacitySchedulingArgs, s conversion.Scope) error {
+	return autoConvert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in, out, s)
+}
+
+func autoConvert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in *config.CapacitySchedulingArgs, out *CapacitySchedulingArgs, s conversion.Scope) error {
+	if err := Convert_config_VictimCostModel_To_v1_VictimCostModel(&in.VictimCostModel, &out.VictimCostModel, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.EnableBorrowing, &out.EnableBorrowing, s); err != nil {
+		return err
+	}
+	out.TrackedResources = *(*[]corev1.ResourceName)(unsafe.Pointer(&in.TrackedResources))
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.AllowCrossQuotaPreemption, &out.AllowCrossQuotaPreemption, s); err != nil {
+		return err
+	}
+	out.DefaultQuota = (*DefaultQuota)(unsafe.Pointer(in.DefaultQuota))
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.UpperBoundOfMax, &out.UpperBoundOfMax, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.LowerBoundOfMin, &out.LowerBoundOfMin, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs is an autogenerated conversion function.
+func Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in *config.CapacitySchedulingArgs, out *CapacitySchedulingArgs, s conversion.Scope) error {
+	return autoConvert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in, out, s)
+}
+
 func autoConvert_v1_CoschedulingArgs_To_config_CoschedulingArgs(in *CoschedulingArgs, out *config.CoschedulingArgs, s conversion.Scope) error {
 	if err := metav1.Convert_Pointer_int64_To_int64(&in.PermitWaitingTimeSeconds, &out.PermitWaitingTimeSeconds, s); err != nil {
 		return err
@@ -213,6 +345,58 @@ func Convert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in *config.Coschedul
 	return autoConvert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in, out, s)
 }
 
+func autoConvert_v1_DefaultQuota_To_config_DefaultQuota(in *DefaultQuota, out *config.DefaultQuota, s conversion.Scope) error {
+	out.Min = *(*corev1.ResourceList)(unsafe.Pointer(&in.Min))
+	out.Max = *(*corev1.ResourceList)(unsafe.Pointer(&in.Max))
+	out.Shared = in.Shared
+	out.ExcludedNamespaces = *(*[]string)(unsafe.Pointer(&in.ExcludedNamespaces))
+	return nil
+}
+
+// Convert_v1_DefaultQuota_To_config_DefaultQuota is an autogenerated conversion function.
+func Convert_v1_DefaultQuota_To_config_DefaultQuota(in *DefaultQuota, out *config.DefaultQuota, s conversion.Scope) error {
+	return autoConvert_v1_DefaultQuota_To_config_DefaultQuota(in, out, s)
+}
+
+func autoConvert_config_DefaultQuota_To_v1_DefaultQuota(in *config.DefaultQuota, out *DefaultQuota, s conversion.Scope) error {
+	out.Min = *(*corev1.ResourceList)(unsafe.Pointer(&in.Min))
+	out.Max = *(*corev1.ResourceList)(unsafe.Pointer(&in.Max))
+	out.Shared = in.Shared
+	out.ExcludedNamespaces = *(*[]string)(unsafe.Pointer(&in.ExcludedNamespaces))
+	return nil
+}
+
+// Convert_config_DefaultQuota_To_v1_DefaultQuota is an autogenerated conversion function.
+func Convert_config_DefaultQuota_To_v1_DefaultQuota(in *config.DefaultQuota, out *DefaultQuota, s conversion.Scope) error {
+	return autoConvert_config_DefaultQuota_To_v1_DefaultQuota(in, out, s)
+}
+
+func autoConvert_v1_LoadCeilingArgs_To_config_LoadCeilingArgs(in *LoadCeilingArgs, out *config.LoadCeilingArgs, s conversion.Scope) error {
+	if err := Convert_v1_TrimaranSpec_To_config_TrimaranSpec(&in.TrimaranSpec, &out.TrimaranSpec, s); err != nil {
+		return err
+	}
+	out.UtilizationCeilings = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.UtilizationCeilings))
+	return nil
+}
+
+// Convert_v1_LoadCeilingArgs_To_config_LoadCeilingArgs is an autogenerated conversion function.
+func Convert_v1_LoadCeilingArgs_To_config_LoadCeilingArgs(in *LoadCeilingArgs, out *config.LoadCeilingArgs, s conversion.Scope) error {
+	return autoConvert_v1_LoadCeilingArgs_To_config_LoadCeilingArgs(in, out, s)
+}
+
+func autoConvert_config_LoadCeilingArgs_To_v1_LoadCeilingArgs(in *config.LoadCeilingArgs, out *LoadCeilingArgs, s conversion.Scope) error {
+	if err := Convert_config_TrimaranSpec_To_v1_TrimaranSpec(&in.TrimaranSpec, &out.TrimaranSpec, s); err != nil {
+		return err
+	}
+	out.UtilizationCeilings = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.UtilizationCeilings))
+	return nil
+}
+
+// Convert_config_LoadCeilingArgs_To_v1_LoadCeilingArgs is an autogenerated conversion function.
+func Convert_config_LoadCeilingArgs_To_v1_LoadCeilingArgs(in *config.LoadCeilingArgs, out *LoadCeilingArgs, s conversion.Scope) error {
+	return autoConvert_config_LoadCeilingArgs_To_v1_LoadCeilingArgs(in, out, s)
+}
+
 func autoConvert_v1_LoadVariationRiskBalancingArgs_To_config_LoadVariationRiskBalancingArgs(in *LoadVariationRiskBalancingArgs, out *config.LoadVariationRiskBalancingArgs, s conversion.Scope) error {
 	if err := Convert_v1_TrimaranSpec_To_config_TrimaranSpec(&in.TrimaranSpec, &out.TrimaranSpec, s); err != nil {
 		return err
@@ -223,6 +407,15 @@ func autoConvert_v1_LoadVariationRiskBalancingArgs_To_config_LoadVariationRiskBa
 	if err := metav1.Convert_Pointer_float64_To_float64(&in.SafeVarianceSensitivity, &out.SafeVarianceSensitivity, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.DebugAddress, &out.DebugAddress, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.MinSafeVarianceMargin, &out.MinSafeVarianceMargin, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.MaxSafeVarianceMargin, &out.MaxSafeVarianceMargin, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -241,6 +434,15 @@ func autoConvert_config_LoadVariationRiskBalancingArgs_To_v1_LoadVariationRiskBa
 	if err := metav1.Convert_float64_To_Pointer_float64(&in.SafeVarianceSensitivity, &out.SafeVarianceSensitivity, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.DebugAddress, &out.DebugAddress, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.MinSafeVarianceMargin, &out.MinSafeVarianceMargin, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.MaxSafeVarianceMargin, &out.MaxSafeVarianceMargin, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -257,6 +459,7 @@ func autoConvert_v1_LowRiskOverCommitmentArgs_To_config_LowRiskOverCommitmentArg
 		return err
 	}
 	out.RiskLimitWeights = *(*map[corev1.ResourceName]float64)(unsafe.Pointer(&in.RiskLimitWeights))
+	out.MaxOvercommitRatios = *(*map[corev1.ResourceName]float64)(unsafe.Pointer(&in.MaxOvercommitRatios))
 	return nil
 }
 
@@ -273,6 +476,7 @@ func autoConvert_config_LowRiskOverCommitmentArgs_To_v1_LowRiskOverCommitmentArg
 		return err
 	}
 	out.RiskLimitWeights = *(*map[corev1.ResourceName]float64)(unsafe.Pointer(&in.RiskLimitWeights))
+	out.MaxOvercommitRatios = *(*map[corev1.ResourceName]float64)(unsafe.Pointer(&in.MaxOvercommitRatios))
 	return nil
 }
 
@@ -292,6 +496,15 @@ func autoConvert_v1_MetricProviderSpec_To_config_MetricProviderSpec(in *MetricPr
 	if err := metav1.Convert_Pointer_bool_To_bool(&in.InsecureSkipVerify, &out.InsecureSkipVerify, s); err != nil {
 		return err
 	}
+	if in.PrometheusQueries != nil {
+		in, out := &in.PrometheusQueries, &out.PrometheusQueries
+		*out = new(config.PrometheusQueries)
+		if err := Convert_v1_PrometheusQueries_To_config_PrometheusQueries(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PrometheusQueries = nil
+	}
 	return nil
 }
 
@@ -311,6 +524,15 @@ func autoConvert_config_MetricProviderSpec_To_v1_MetricProviderSpec(in *config.M
 	if err := metav1.Convert_bool_To_Pointer_bool(&in.InsecureSkipVerify, &out.InsecureSkipVerify, s); err != nil {
 		return err
 	}
+	if in.PrometheusQueries != nil {
+		in, out := &in.PrometheusQueries, &out.PrometheusQueries
+		*out = new(PrometheusQueries)
+		if err := Convert_config_PrometheusQueries_To_v1_PrometheusQueries(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PrometheusQueries = nil
+	}
 	return nil
 }
 
@@ -356,6 +578,8 @@ func autoConvert_v1_NodeResourceTopologyCache_To_config_NodeResourceTopologyCach
 	out.ResyncMethod = (*config.CacheResyncMethod)(unsafe.Pointer(in.ResyncMethod))
 	out.InformerMode = (*config.CacheInformerMode)(unsafe.Pointer(in.InformerMode))
 	out.ResyncScope = (*config.CacheResyncScope)(unsafe.Pointer(in.ResyncScope))
+	out.ReserveMode = (*config.CacheReserveMode)(unsafe.Pointer(in.ReserveMode))
+	out.DebugAddress = (*string)(unsafe.Pointer(in.DebugAddress))
 	return nil
 }
 
@@ -369,6 +593,8 @@ func autoConvert_config_NodeResourceTopologyCache_To_v1_NodeResourceTopologyCach
 	out.ResyncMethod = (*CacheResyncMethod)(unsafe.Pointer(in.ResyncMethod))
 	out.InformerMode = (*CacheInformerMode)(unsafe.Pointer(in.InformerMode))
 	out.ResyncScope = (*CacheResyncScope)(unsafe.Pointer(in.ResyncScope))
+	out.ReserveMode = (*CacheReserveMode)(unsafe.Pointer(in.ReserveMode))
+	out.DebugAddress = (*string)(unsafe.Pointer(in.DebugAddress))
 	return nil
 }
 
@@ -419,6 +645,60 @@ func Convert_config_NodeResourcesAllocatableArgs_To_v1_NodeResourcesAllocatableA
 	return autoConvert_config_NodeResourcesAllocatableArgs_To_v1_NodeResourcesAllocatableArgs(in, out, s)
 }
 
+func autoConvert_v1_PredictionSpec_To_config_PredictionSpec(in *PredictionSpec, out *config.PredictionSpec, s conversion.Scope) error {
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.HistorySize, &out.HistorySize, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.SeasonSeconds, &out.SeasonSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.HorizonSeconds, &out.HorizonSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.LevelSmoothing, &out.LevelSmoothing, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.TrendSmoothing, &out.TrendSmoothing, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.SeasonalSmoothing, &out.SeasonalSmoothing, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_PredictionSpec_To_config_PredictionSpec is an autogenerated conversion function.
+func Convert_v1_PredictionSpec_To_config_PredictionSpec(in *PredictionSpec, out *config.PredictionSpec, s conversion.Scope) error {
+	return autoConvert_v1_PredictionSpec_To_config_PredictionSpec(in, out, s)
+}
+
+func autoConvert_config_PredictionSpec_To_v1_PredictionSpec(in *config.PredictionSpec, out *PredictionSpec, s conversion.Scope) error {
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.HistorySize, &out.HistorySize, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.SeasonSeconds, &out.SeasonSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.HorizonSeconds, &out.HorizonSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.LevelSmoothing, &out.LevelSmoothing, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.TrendSmoothing, &out.TrendSmoothing, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.SeasonalSmoothing, &out.SeasonalSmoothing, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_PredictionSpec_To_v1_PredictionSpec is an autogenerated conversion function.
+func Convert_config_PredictionSpec_To_v1_PredictionSpec(in *config.PredictionSpec, out *PredictionSpec, s conversion.Scope) error {
+	return autoConvert_config_PredictionSpec_To_v1_PredictionSpec(in, out, s)
+}
+
 func autoConvert_v1_PreemptionTolerationArgs_To_config_PreemptionTolerationArgs(in *PreemptionTolerationArgs, out *config.PreemptionTolerationArgs, s conversion.Scope) error {
 	if err := metav1.Convert_Pointer_int32_To_int32(&in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage, s); err != nil {
 		return err
@@ -449,6 +729,60 @@ func Convert_config_PreemptionTolerationArgs_To_v1_PreemptionTolerationArgs(in *
 	return autoConvert_config_PreemptionTolerationArgs_To_v1_PreemptionTolerationArgs(in, out, s)
 }
 
+func autoConvert_v1_PrometheusQueries_To_config_PrometheusQueries(in *PrometheusQueries, out *config.PrometheusQueries, s conversion.Scope) error {
+	if err := metav1.Convert_Pointer_string_To_string(&in.Window, &out.Window, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.NodeLabel, &out.NodeLabel, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.CPUAverage, &out.CPUAverage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.CPUStd, &out.CPUStd, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.MemoryAverage, &out.MemoryAverage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.MemoryStd, &out.MemoryStd, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_PrometheusQueries_To_config_PrometheusQueries is an autogenerated conversion function.
+func Convert_v1_PrometheusQueries_To_config_PrometheusQueries(in *PrometheusQueries, out *config.PrometheusQueries, s conversion.Scope) error {
+	return autoConvert_v1_PrometheusQueries_To_config_PrometheusQueries(in, out, s)
+}
+
+func autoConvert_config_PrometheusQueries_To_v1_PrometheusQueries(in *config.PrometheusQueries, out *PrometheusQueries, s conversion.Scope) error {
+	if err := metav1.Convert_string_To_Pointer_string(&in.Window, &out.Window, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.NodeLabel, &out.NodeLabel, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.CPUAverage, &out.CPUAverage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.CPUStd, &out.CPUStd, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.MemoryAverage, &out.MemoryAverage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.MemoryStd, &out.MemoryStd, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_PrometheusQueries_To_v1_PrometheusQueries is an autogenerated conversion function.
+func Convert_config_PrometheusQueries_To_v1_PrometheusQueries(in *config.PrometheusQueries, out *PrometheusQueries, s conversion.Scope) error {
+	return autoConvert_config_PrometheusQueries_To_v1_PrometheusQueries(in, out, s)
+}
+
 func autoConvert_v1_ScoringStrategy_To_config_ScoringStrategy(in *ScoringStrategy, out *config.ScoringStrategy, s conversion.Scope) error {
 	out.Type = config.ScoringStrategyType(in.Type)
 	out.Resources = *(*[]apisconfig.ResourceSpec)(unsafe.Pointer(&in.Resources))
@@ -512,6 +846,32 @@ func autoConvert_v1_TargetLoadPackingArgs_To_config_TargetLoadPackingArgs(in *Ta
 	if err := metav1.Convert_Pointer_int64_To_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
 		return err
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]config.TargetLoadPackingResource, len(*in))
+		for i := range *in {
+			if err := Convert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Resources = nil
+	}
+	if in.UsageProfiles != nil {
+		in, out := &in.UsageProfiles, &out.UsageProfiles
+		*out = new(config.UsageProfilesSpec)
+		if err := Convert_v1_UsageProfilesSpec_To_config_UsageProfilesSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.UsageProfiles = nil
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MinTargetUtilization, &out.MinTargetUtilization, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MaxTargetUtilization, &out.MaxTargetUtilization, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -531,6 +891,32 @@ func autoConvert_config_TargetLoadPackingArgs_To_v1_TargetLoadPackingArgs(in *co
 	if err := metav1.Convert_int64_To_Pointer_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
 		return err
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]TargetLoadPackingResource, len(*in))
+		for i := range *in {
+			if err := Convert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Resources = nil
+	}
+	if in.UsageProfiles != nil {
+		in, out := &in.UsageProfiles, &out.UsageProfiles
+		*out = new(UsageProfilesSpec)
+		if err := Convert_config_UsageProfilesSpec_To_v1_UsageProfilesSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.UsageProfiles = nil
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MinTargetUtilization, &out.MinTargetUtilization, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MaxTargetUtilization, &out.MaxTargetUtilization, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -539,6 +925,38 @@ func Convert_config_TargetLoadPackingArgs_To_v1_TargetLoadPackingArgs(in *config
 	return autoConvert_config_TargetLoadPackingArgs_To_v1_TargetLoadPackingArgs(in, out, s)
 }
 
+func autoConvert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(in *TargetLoadPackingResource, out *config.TargetLoadPackingResource, s conversion.Scope) error {
+	out.Name = corev1.ResourceName(in.Name)
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.Weight, &out.Weight, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource is an autogenerated conversion function.
+func Convert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(in *TargetLoadPackingResource, out *config.TargetLoadPackingResource, s conversion.Scope) error {
+	return autoConvert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(in, out, s)
+}
+
+func autoConvert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(in *config.TargetLoadPackingResource, out *TargetLoadPackingResource, s conversion.Scope) error {
+	out.Name = corev1.ResourceName(in.Name)
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.Weight, &out.Weight, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource is an autogenerated conversion function.
+func Convert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(in *config.TargetLoadPackingResource, out *TargetLoadPackingResource, s conversion.Scope) error {
+	return autoConvert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(in, out, s)
+}
+
 func autoConvert_v1_TopologicalSortArgs_To_config_TopologicalSortArgs(in *TopologicalSortArgs, out *config.TopologicalSortArgs, s conversion.Scope) error {
 	out.Namespaces = *(*[]string)(unsafe.Pointer(&in.Namespaces))
 	return nil
@@ -566,6 +984,34 @@ func autoConvert_v1_TrimaranSpec_To_config_TrimaranSpec(in *TrimaranSpec, out *c
 	if err := metav1.Convert_Pointer_string_To_string(&in.WatcherAddress, &out.WatcherAddress, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsStalenessThresholdSeconds, &out.MetricsStalenessThresholdSeconds, s); err != nil {
+		return err
+	}
+	out.StaleMetricsPolicy = config.StaleMetricsPolicyType(in.StaleMetricsPolicy)
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsFetchTimeoutSeconds, &out.MetricsFetchTimeoutSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsMaxBackoffSeconds, &out.MetricsMaxBackoffSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.CacheCleanupIntervalMinutes, &out.CacheCleanupIntervalMinutes, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsAgentReportingIntervalSeconds, &out.MetricsAgentReportingIntervalSeconds, s); err != nil {
+		return err
+	}
+	if in.Prediction != nil {
+		in, out := &in.Prediction, &out.Prediction
+		*out = new(config.PredictionSpec)
+		if err := Convert_v1_PredictionSpec_To_config_PredictionSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Prediction = nil
+	}
 	return nil
 }
 
@@ -581,6 +1027,34 @@ func autoConvert_config_TrimaranSpec_To_v1_TrimaranSpec(in *config.TrimaranSpec,
 	if err := metav1.Convert_string_To_Pointer_string(&in.WatcherAddress, &out.WatcherAddress, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsStalenessThresholdSeconds, &out.MetricsStalenessThresholdSeconds, s); err != nil {
+		return err
+	}
+	out.StaleMetricsPolicy = StaleMetricsPolicyType(in.StaleMetricsPolicy)
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsFetchTimeoutSeconds, &out.MetricsFetchTimeoutSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsMaxBackoffSeconds, &out.MetricsMaxBackoffSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.CacheCleanupIntervalMinutes, &out.CacheCleanupIntervalMinutes, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsAgentReportingIntervalSeconds, &out.MetricsAgentReportingIntervalSeconds, s); err != nil {
+		return err
+	}
+	if in.Prediction != nil {
+		in, out := &in.Prediction, &out.Prediction
+		*out = new(PredictionSpec)
+		if err := Convert_config_PredictionSpec_To_v1_PredictionSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Prediction = nil
+	}
 	return nil
 }
 
@@ -588,3 +1062,89 @@ func autoConvert_config_TrimaranSpec_To_v1_TrimaranSpec(in *config.TrimaranSpec,
 func Convert_config_TrimaranSpec_To_v1_TrimaranSpec(in *config.TrimaranSpec, out *TrimaranSpec, s conversion.Scope) error {
 	return autoConvert_config_TrimaranSpec_To_v1_TrimaranSpec(in, out, s)
 }
+
+func autoConvert_v1_UsageProfilesSpec_To_config_UsageProfilesSpec(in *UsageProfilesSpec, out *config.UsageProfilesSpec, s conversion.Scope) error {
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.UpdateIntervalSeconds, &out.UpdateIntervalSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.Smoothing, &out.Smoothing, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MinSamples, &out.MinSamples, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_UsageProfilesSpec_To_config_UsageProfilesSpec is an autogenerated conversion function.
+func Convert_v1_UsageProfilesSpec_To_config_UsageProfilesSpec(in *UsageProfilesSpec, out *config.UsageProfilesSpec, s conversion.Scope) error {
+	return autoConvert_v1_UsageProfilesSpec_To_config_UsageProfilesSpec(in, out, s)
+}
+
+func autoConvert_config_UsageProfilesSpec_To_v1_UsageProfilesSpec(in *config.UsageProfilesSpec, out *UsageProfilesSpec, s conversion.Scope) error {
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.UpdateIntervalSeconds, &out.UpdateIntervalSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.Smoothing, &out.Smoothing, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MinSamples, &out.MinSamples, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_UsageProfilesSpec_To_v1_UsageProfilesSpec is an autogenerated conversion function.
+func Convert_config_UsageProfilesSpec_To_v1_UsageProfilesSpec(in *config.UsageProfilesSpec, out *UsageProfilesSpec, s conversion.Scope) error {
+	return autoConvert_config_UsageProfilesSpec_To_v1_UsageProfilesSpec(in, out, s)
+}
+
+func autoConvert_v1_VictimCostModel_To_config_VictimCostModel(in *VictimCostModel, out *config.VictimCostModel, s conversion.Scope) error {
+	out.Type = config.VictimCostModelType(in.Type)
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.RuntimeWeight, &out.RuntimeWeight, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.RestartsWeight, &out.RestartsWeight, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.CheckpointWeight, &out.CheckpointWeight, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.QoSWeight, &out.QoSWeight, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.RuntimeNormalizationSeconds, &out.RuntimeNormalizationSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_VictimCostModel_To_config_VictimCostModel is an autogenerated conversion function.
+func Convert_v1_VictimCostModel_To_config_VictimCostModel(in *VictimCostModel, out *config.VictimCostModel, s conversion.Scope) error {
+	return autoConvert_v1_VictimCostModel_To_config_VictimCostModel(in, out, s)
+}
+
+func autoConvert_config_VictimCostModel_To_v1_VictimCostModel(in *config.VictimCostModel, out *VictimCostModel, s conversion.Scope) error {
+	out.Type = VictimCostModelType(in.Type)
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.RuntimeWeight, &out.RuntimeWeight, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.RestartsWeight, &out.RestartsWeight, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.CheckpointWeight, &out.CheckpointWeight, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.QoSWeight, &out.QoSWeight, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.RuntimeNormalizationSeconds, &out.RuntimeNormalizationSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_VictimCostModel_To_v1_VictimCostModel is an autogenerated conversion function.
+func Convert_config_VictimCostModel_To_v1_VictimCostModel(in *config.VictimCostModel, out *VictimCostModel, s conversion.Scope) error {
+	return autoConvert_config_VictimCostModel_To_v1_VictimCostModel(in, out, s)
+}
@@ -27,6 +27,62 @@ import (
 	configv1 "k8s.io/kube-scheduler/config/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySchedulingArgs) DeepCopyInto(out *CapacitySchedulingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.VictimCostModel.DeepCopyInto(&out.VictimCostModel)
+	if in.EnableBorrowing != nil {
+		in, out := &in.EnableBorrowing, &out.EnableBorrowing
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TrackedResources != nil {
+		in, out := &in.TrackedResources, &out.TrackedResources
+		*out = make([]corev1.ResourceName, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowCrossQuotaPreemption != nil {
+		in, out := &in.AllowCrossQuotaPreemption, &out.AllowCrossQuotaPreemption
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DefaultQuota != nil {
+		in, out := &in.DefaultQuota, &out.DefaultQuota
+		*out = new(DefaultQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpperBoundOfMax != nil {
+		in, out := &in.UpperBoundOfMax, &out.UpperBoundOfMax
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LowerBoundOfMin != nil {
+		in, out := &in.LowerBoundOfMin, &out.LowerBoundOfMin
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySchedulingArgs.
+func (in *CapacitySchedulingArgs) DeepCopy() *CapacitySchedulingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySchedulingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacitySchedulingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
 	*out = *in
@@ -62,6 +118,74 @@ func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultQuota) DeepCopyInto(out *DefaultQuota) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultQuota.
+func (in *DefaultQuota) DeepCopy() *DefaultQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadCeilingArgs) DeepCopyInto(out *LoadCeilingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.TrimaranSpec.DeepCopyInto(&out.TrimaranSpec)
+	if in.UtilizationCeilings != nil {
+		in, out := &in.UtilizationCeilings, &out.UtilizationCeilings
+		*out = make(map[corev1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadCeilingArgs.
+func (in *LoadCeilingArgs) DeepCopy() *LoadCeilingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadCeilingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadCeilingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadVariationRiskBalancingArgs) DeepCopyInto(out *LoadVariationRiskBalancingArgs) {
 	*out = *in
@@ -77,6 +201,21 @@ func (in *LoadVariationRiskBalancingArgs) DeepCopyInto(out *LoadVariationRiskBal
 		*out = new(float64)
 		**out = **in
 	}
+	if in.DebugAddress != nil {
+		in, out := &in.DebugAddress, &out.DebugAddress
+		*out = new(string)
+		**out = **in
+	}
+	if in.MinSafeVarianceMargin != nil {
+		in, out := &in.MinSafeVarianceMargin, &out.MinSafeVarianceMargin
+		*out = new(float64)
+		**out = **in
+	}
+	if in.MaxSafeVarianceMargin != nil {
+		in, out := &in.MaxSafeVarianceMargin, &out.MaxSafeVarianceMargin
+		*out = new(float64)
+		**out = **in
+	}
 	return
 }
 
@@ -115,6 +254,13 @@ func (in *LowRiskOverCommitmentArgs) DeepCopyInto(out *LowRiskOverCommitmentArgs
 			(*out)[key] = val
 		}
 	}
+	if in.MaxOvercommitRatios != nil {
+		in, out := &in.MaxOvercommitRatios, &out.MaxOvercommitRatios
+		*out = make(map[corev1.ResourceName]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -154,6 +300,11 @@ func (in *MetricProviderSpec) DeepCopyInto(out *MetricProviderSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PrometheusQueries != nil {
+		in, out := &in.PrometheusQueries, &out.PrometheusQueries
+		*out = new(PrometheusQueries)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -230,6 +381,16 @@ func (in *NodeResourceTopologyCache) DeepCopyInto(out *NodeResourceTopologyCache
 		*out = new(CacheResyncScope)
 		**out = **in
 	}
+	if in.ReserveMode != nil {
+		in, out := &in.ReserveMode, &out.ReserveMode
+		*out = new(CacheReserveMode)
+		**out = **in
+	}
+	if in.DebugAddress != nil {
+		in, out := &in.DebugAddress, &out.DebugAddress
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -313,6 +474,52 @@ func (in *NodeResourcesAllocatableArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictionSpec) DeepCopyInto(out *PredictionSpec) {
+	*out = *in
+	if in.HistorySize != nil {
+		in, out := &in.HistorySize, &out.HistorySize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SeasonSeconds != nil {
+		in, out := &in.SeasonSeconds, &out.SeasonSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.HorizonSeconds != nil {
+		in, out := &in.HorizonSeconds, &out.HorizonSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LevelSmoothing != nil {
+		in, out := &in.LevelSmoothing, &out.LevelSmoothing
+		*out = new(float64)
+		**out = **in
+	}
+	if in.TrendSmoothing != nil {
+		in, out := &in.TrendSmoothing, &out.TrendSmoothing
+		*out = new(float64)
+		**out = **in
+	}
+	if in.SeasonalSmoothing != nil {
+		in, out := &in.SeasonalSmoothing, &out.SeasonalSmoothing
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictionSpec.
+func (in *PredictionSpec) DeepCopy() *PredictionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreemptionTolerationArgs) DeepCopyInto(out *PreemptionTolerationArgs) {
 	*out = *in
@@ -348,6 +555,52 @@ func (in *PreemptionTolerationArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusQueries) DeepCopyInto(out *PrometheusQueries) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(string)
+		**out = **in
+	}
+	if in.NodeLabel != nil {
+		in, out := &in.NodeLabel, &out.NodeLabel
+		*out = new(string)
+		**out = **in
+	}
+	if in.CPUAverage != nil {
+		in, out := &in.CPUAverage, &out.CPUAverage
+		*out = new(string)
+		**out = **in
+	}
+	if in.CPUStd != nil {
+		in, out := &in.CPUStd, &out.CPUStd
+		*out = new(string)
+		**out = **in
+	}
+	if in.MemoryAverage != nil {
+		in, out := &in.MemoryAverage, &out.MemoryAverage
+		*out = new(string)
+		**out = **in
+	}
+	if in.MemoryStd != nil {
+		in, out := &in.MemoryStd, &out.MemoryStd
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusQueries.
+func (in *PrometheusQueries) DeepCopy() *PrometheusQueries {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusQueries)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScoringStrategy) DeepCopyInto(out *ScoringStrategy) {
 	*out = *in
@@ -426,6 +679,28 @@ func (in *TargetLoadPackingArgs) DeepCopyInto(out *TargetLoadPackingArgs) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]TargetLoadPackingResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UsageProfiles != nil {
+		in, out := &in.UsageProfiles, &out.UsageProfiles
+		*out = new(UsageProfilesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinTargetUtilization != nil {
+		in, out := &in.MinTargetUtilization, &out.MinTargetUtilization
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxTargetUtilization != nil {
+		in, out := &in.MaxTargetUtilization, &out.MaxTargetUtilization
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -447,6 +722,32 @@ func (in *TargetLoadPackingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetLoadPackingResource) DeepCopyInto(out *TargetLoadPackingResource) {
+	*out = *in
+	if in.TargetUtilization != nil {
+		in, out := &in.TargetUtilization, &out.TargetUtilization
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetLoadPackingResource.
+func (in *TargetLoadPackingResource) DeepCopy() *TargetLoadPackingResource {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetLoadPackingResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TopologicalSortArgs) DeepCopyInto(out *TopologicalSortArgs) {
 	*out = *in
@@ -486,6 +787,41 @@ func (in *TrimaranSpec) DeepCopyInto(out *TrimaranSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MetricsStalenessThresholdSeconds != nil {
+		in, out := &in.MetricsStalenessThresholdSeconds, &out.MetricsStalenessThresholdSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetricsUpdateIntervalSeconds != nil {
+		in, out := &in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetricsFetchTimeoutSeconds != nil {
+		in, out := &in.MetricsFetchTimeoutSeconds, &out.MetricsFetchTimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetricsMaxBackoffSeconds != nil {
+		in, out := &in.MetricsMaxBackoffSeconds, &out.MetricsMaxBackoffSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CacheCleanupIntervalMinutes != nil {
+		in, out := &in.CacheCleanupIntervalMinutes, &out.CacheCleanupIntervalMinutes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetricsAgentReportingIntervalSeconds != nil {
+		in, out := &in.MetricsAgentReportingIntervalSeconds, &out.MetricsAgentReportingIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Prediction != nil {
+		in, out := &in.Prediction, &out.Prediction
+		*out = new(PredictionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -498,3 +834,75 @@ func (in *TrimaranSpec) DeepCopy() *TrimaranSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageProfilesSpec) DeepCopyInto(out *UsageProfilesSpec) {
+	*out = *in
+	if in.UpdateIntervalSeconds != nil {
+		in, out := &in.UpdateIntervalSeconds, &out.UpdateIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Smoothing != nil {
+		in, out := &in.Smoothing, &out.Smoothing
+		*out = new(float64)
+		**out = **in
+	}
+	if in.MinSamples != nil {
+		in, out := &in.MinSamples, &out.MinSamples
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageProfilesSpec.
+func (in *UsageProfilesSpec) DeepCopy() *UsageProfilesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageProfilesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VictimCostModel) DeepCopyInto(out *VictimCostModel) {
+	*out = *in
+	if in.RuntimeWeight != nil {
+		in, out := &in.RuntimeWeight, &out.RuntimeWeight
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RestartsWeight != nil {
+		in, out := &in.RestartsWeight, &out.RestartsWeight
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CheckpointWeight != nil {
+		in, out := &in.CheckpointWeight, &out.CheckpointWeight
+		*out = new(int64)
+		**out = **in
+	}
+	if in.QoSWeight != nil {
+		in, out := &in.QoSWeight, &out.QoSWeight
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RuntimeNormalizationSeconds != nil {
+		in, out := &in.RuntimeNormalizationSeconds, &out.RuntimeNormalizationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VictimCostModel.
+func (in *VictimCostModel) DeepCopy() *VictimCostModel {
+	if in == nil {
+		return nil
+	}
+	out := new(VictimCostModel)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -28,6 +28,7 @@ var validScoringStrategy = sets.NewString(
 	string(config.BalancedAllocation),
 	string(config.LeastAllocated),
 	string(config.LeastNUMANodes),
+	string(config.PreferredNUMANodes),
 )
 
 func ValidateNodeResourceTopologyMatchArgs(path *field.Path, args *config.NodeResourceTopologyMatchArgs) error {
@@ -46,3 +47,80 @@ func validateScoringStrategyType(scoringStrategy config.ScoringStrategyType, pat
 	}
 	return nil
 }
+
+var validVictimCostModel = sets.NewString(
+	string(config.VictimCostModelPriority),
+	string(config.VictimCostModelWorkLost),
+)
+
+func ValidateCapacitySchedulingArgs(path *field.Path, args *config.CapacitySchedulingArgs) error {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateVictimCostModel(args.VictimCostModel, path.Child("victimCostModel"))...)
+
+	trackedResourcesPath := path.Child("trackedResources")
+	tracked := sets.NewString()
+	for i, name := range args.TrackedResources {
+		if len(name) == 0 {
+			allErrs = append(allErrs, field.Required(trackedResourcesPath.Index(i), "resource name must not be empty"))
+		} else if tracked.Has(string(name)) {
+			allErrs = append(allErrs, field.Duplicate(trackedResourcesPath.Index(i), name))
+		}
+		tracked.Insert(string(name))
+	}
+
+	if args.DefaultQuota != nil {
+		allErrs = append(allErrs, validateDefaultQuota(args.DefaultQuota, path.Child("defaultQuota"))...)
+	}
+
+	if args.LowerBoundOfMin < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("lowerBoundOfMin"), args.LowerBoundOfMin, "must not be negative"))
+	}
+	if args.UpperBoundOfMax < args.LowerBoundOfMin {
+		allErrs = append(allErrs, field.Invalid(path.Child("upperBoundOfMax"), args.UpperBoundOfMax, "must not be less than lowerBoundOfMin"))
+	}
+
+	return allErrs.ToAggregate()
+}
+
+func validateVictimCostModel(costModel config.VictimCostModel, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !validVictimCostModel.Has(string(costModel.Type)) {
+		allErrs = append(allErrs, field.Invalid(path.Child("type"), costModel.Type, "invalid VictimCostModelType"))
+	}
+	weights := []struct {
+		name   string
+		weight int64
+	}{
+		{"runtimeWeight", costModel.RuntimeWeight},
+		{"restartsWeight", costModel.RestartsWeight},
+		{"checkpointWeight", costModel.CheckpointWeight},
+		{"qosWeight", costModel.QoSWeight},
+	}
+	for _, w := range weights {
+		if w.weight < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child(w.name), w.weight, "weight must not be negative"))
+		}
+	}
+	if costModel.RuntimeNormalizationSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("runtimeNormalizationSeconds"), costModel.RuntimeNormalizationSeconds, "must be positive"))
+	}
+	return allErrs
+}
+
+func validateDefaultQuota(quota *config.DefaultQuota, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for name, quantity := range quota.Min {
+		if quantity.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("min").Key(string(name)), quantity.String(), "must not be negative"))
+		}
+		if maxQuantity, ok := quota.Max[name]; ok && quantity.Cmp(maxQuantity) > 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("min").Key(string(name)), quantity.String(), "must not be greater than max"))
+		}
+	}
+	for name, quantity := range quota.Max {
+		if quantity.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("max").Key(string(name)), quantity.String(), "must not be negative"))
+		}
+	}
+	return allErrs
+}
@@ -21,6 +21,9 @@ import (
 	"strings"
 	"testing"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
 	"sigs.k8s.io/scheduler-plugins/apis/config"
 )
 
@@ -38,6 +41,14 @@ func TestValidateNodeResourceTopologyMatchArgs(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "correct config, PreferredNUMANodes ScoringStrategy type",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.PreferredNUMANodes,
+				},
+			},
+		},
 		{
 			description: "incorrect config, wrong ScoringStrategy type",
 			args: &config.NodeResourceTopologyMatchArgs{
@@ -67,3 +78,108 @@ func TestValidateNodeResourceTopologyMatchArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCapacitySchedulingArgs(t *testing.T) {
+	victimCostModel := config.VictimCostModel{
+		Type:                        config.VictimCostModelPriority,
+		RuntimeWeight:               1,
+		RestartsWeight:              1,
+		CheckpointWeight:            1,
+		QoSWeight:                   1,
+		RuntimeNormalizationSeconds: 3600,
+	}
+
+	testCases := []struct {
+		args        *config.CapacitySchedulingArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct config",
+			args: &config.CapacitySchedulingArgs{
+				VictimCostModel:  victimCostModel,
+				TrackedResources: []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory},
+				DefaultQuota: &config.DefaultQuota{
+					Min: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+					Max: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+		},
+		{
+			description: "incorrect config, wrong VictimCostModel type",
+			args: &config.CapacitySchedulingArgs{
+				VictimCostModel: config.VictimCostModel{
+					Type:                        "not existent",
+					RuntimeNormalizationSeconds: 3600,
+				},
+			},
+			expectedErr: fmt.Errorf("victimCostModel.type: Invalid value:"),
+		},
+		{
+			description: "incorrect config, negative weight",
+			args: &config.CapacitySchedulingArgs{
+				VictimCostModel: config.VictimCostModel{
+					Type:                        config.VictimCostModelWorkLost,
+					QoSWeight:                   -1,
+					RuntimeNormalizationSeconds: 3600,
+				},
+			},
+			expectedErr: fmt.Errorf("victimCostModel.qosWeight: Invalid value:"),
+		},
+		{
+			description: "incorrect config, duplicated tracked resource",
+			args: &config.CapacitySchedulingArgs{
+				VictimCostModel:  victimCostModel,
+				TrackedResources: []v1.ResourceName{v1.ResourceCPU, v1.ResourceCPU},
+			},
+			expectedErr: fmt.Errorf("trackedResources[1]: Duplicate value:"),
+		},
+		{
+			description: "incorrect config, default quota min greater than max",
+			args: &config.CapacitySchedulingArgs{
+				VictimCostModel: victimCostModel,
+				DefaultQuota: &config.DefaultQuota{
+					Min: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")},
+					Max: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+			expectedErr: fmt.Errorf("defaultQuota.min[cpu]: Invalid value:"),
+		},
+		{
+			description: "incorrect config, negative lower bound of min",
+			args: &config.CapacitySchedulingArgs{
+				VictimCostModel: victimCostModel,
+				UpperBoundOfMax: 100,
+				LowerBoundOfMin: -1,
+			},
+			expectedErr: fmt.Errorf("lowerBoundOfMin: Invalid value:"),
+		},
+		{
+			description: "incorrect config, upper bound of max less than lower bound of min",
+			args: &config.CapacitySchedulingArgs{
+				VictimCostModel: victimCostModel,
+				UpperBoundOfMax: 10,
+				LowerBoundOfMin: 100,
+			},
+			expectedErr: fmt.Errorf("upperBoundOfMax: Invalid value:"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateCapacitySchedulingArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -27,6 +27,42 @@ import (
 	apisconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySchedulingArgs) DeepCopyInto(out *CapacitySchedulingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.VictimCostModel = in.VictimCostModel
+	if in.TrackedResources != nil {
+		in, out := &in.TrackedResources, &out.TrackedResources
+		*out = make([]v1.ResourceName, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultQuota != nil {
+		in, out := &in.DefaultQuota, &out.DefaultQuota
+		*out = new(DefaultQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySchedulingArgs.
+func (in *CapacitySchedulingArgs) DeepCopy() *CapacitySchedulingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySchedulingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacitySchedulingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
 	*out = *in
@@ -52,11 +88,79 @@ func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultQuota) DeepCopyInto(out *DefaultQuota) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultQuota.
+func (in *DefaultQuota) DeepCopy() *DefaultQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadCeilingArgs) DeepCopyInto(out *LoadCeilingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.TrimaranSpec.DeepCopyInto(&out.TrimaranSpec)
+	if in.UtilizationCeilings != nil {
+		in, out := &in.UtilizationCeilings, &out.UtilizationCeilings
+		*out = make(map[v1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadCeilingArgs.
+func (in *LoadCeilingArgs) DeepCopy() *LoadCeilingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadCeilingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadCeilingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadVariationRiskBalancingArgs) DeepCopyInto(out *LoadVariationRiskBalancingArgs) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	out.TrimaranSpec = in.TrimaranSpec
+	in.TrimaranSpec.DeepCopyInto(&out.TrimaranSpec)
 	return
 }
 
@@ -82,7 +186,7 @@ func (in *LoadVariationRiskBalancingArgs) DeepCopyObject() runtime.Object {
 func (in *LowRiskOverCommitmentArgs) DeepCopyInto(out *LowRiskOverCommitmentArgs) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	out.TrimaranSpec = in.TrimaranSpec
+	in.TrimaranSpec.DeepCopyInto(&out.TrimaranSpec)
 	if in.RiskLimitWeights != nil {
 		in, out := &in.RiskLimitWeights, &out.RiskLimitWeights
 		*out = make(map[v1.ResourceName]float64, len(*in))
@@ -90,6 +194,13 @@ func (in *LowRiskOverCommitmentArgs) DeepCopyInto(out *LowRiskOverCommitmentArgs
 			(*out)[key] = val
 		}
 	}
+	if in.MaxOvercommitRatios != nil {
+		in, out := &in.MaxOvercommitRatios, &out.MaxOvercommitRatios
+		*out = make(map[v1.ResourceName]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -114,6 +225,11 @@ func (in *LowRiskOverCommitmentArgs) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricProviderSpec) DeepCopyInto(out *MetricProviderSpec) {
 	*out = *in
+	if in.PrometheusQueries != nil {
+		in, out := &in.PrometheusQueries, &out.PrometheusQueries
+		*out = new(PrometheusQueries)
+		**out = **in
+	}
 	return
 }
 
@@ -180,6 +296,16 @@ func (in *NodeResourceTopologyCache) DeepCopyInto(out *NodeResourceTopologyCache
 		*out = new(CacheResyncScope)
 		**out = **in
 	}
+	if in.ReserveMode != nil {
+		in, out := &in.ReserveMode, &out.ReserveMode
+		*out = new(CacheReserveMode)
+		**out = **in
+	}
+	if in.DebugAddress != nil {
+		in, out := &in.DebugAddress, &out.DebugAddress
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -254,6 +380,22 @@ func (in *NodeResourcesAllocatableArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictionSpec) DeepCopyInto(out *PredictionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictionSpec.
+func (in *PredictionSpec) DeepCopy() *PredictionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreemptionTolerationArgs) DeepCopyInto(out *PreemptionTolerationArgs) {
 	*out = *in
@@ -279,6 +421,22 @@ func (in *PreemptionTolerationArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusQueries) DeepCopyInto(out *PrometheusQueries) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusQueries.
+func (in *PrometheusQueries) DeepCopy() *PrometheusQueries {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusQueries)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScoringStrategy) DeepCopyInto(out *ScoringStrategy) {
 	*out = *in
@@ -329,7 +487,7 @@ func (in *SySchedArgs) DeepCopyObject() runtime.Object {
 func (in *TargetLoadPackingArgs) DeepCopyInto(out *TargetLoadPackingArgs) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	out.TrimaranSpec = in.TrimaranSpec
+	in.TrimaranSpec.DeepCopyInto(&out.TrimaranSpec)
 	if in.DefaultRequests != nil {
 		in, out := &in.DefaultRequests, &out.DefaultRequests
 		*out = make(v1.ResourceList, len(*in))
@@ -337,6 +495,16 @@ func (in *TargetLoadPackingArgs) DeepCopyInto(out *TargetLoadPackingArgs) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]TargetLoadPackingResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsageProfiles != nil {
+		in, out := &in.UsageProfiles, &out.UsageProfiles
+		*out = new(UsageProfilesSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -358,6 +526,22 @@ func (in *TargetLoadPackingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetLoadPackingResource) DeepCopyInto(out *TargetLoadPackingResource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetLoadPackingResource.
+func (in *TargetLoadPackingResource) DeepCopy() *TargetLoadPackingResource {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetLoadPackingResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TopologicalSortArgs) DeepCopyInto(out *TopologicalSortArgs) {
 	*out = *in
@@ -391,7 +575,12 @@ func (in *TopologicalSortArgs) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrimaranSpec) DeepCopyInto(out *TrimaranSpec) {
 	*out = *in
-	out.MetricProvider = in.MetricProvider
+	in.MetricProvider.DeepCopyInto(&out.MetricProvider)
+	if in.Prediction != nil {
+		in, out := &in.Prediction, &out.Prediction
+		*out = new(PredictionSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -404,3 +593,35 @@ func (in *TrimaranSpec) DeepCopy() *TrimaranSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageProfilesSpec) DeepCopyInto(out *UsageProfilesSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageProfilesSpec.
+func (in *UsageProfilesSpec) DeepCopy() *UsageProfilesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageProfilesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VictimCostModel) DeepCopyInto(out *VictimCostModel) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VictimCostModel.
+func (in *VictimCostModel) DeepCopy() *VictimCostModel {
+	if in == nil {
+		return nil
+	}
+	out := new(VictimCostModel)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+)
+
+// podEventHandlers : pod event handlers of the plugins, notified synchronously of the pods bound by the replay,
+// so that the plugins account for them before the next pod is scheduled
+type podEventHandlers struct {
+	handlers []cache.ResourceEventHandler
+}
+
+// onAdd : notify the handlers of a pod bound by the replay
+func (h *podEventHandlers) onAdd(pod *v1.Pod) {
+	for _, handler := range h.handlers {
+		handler.OnAdd(pod, false)
+	}
+}
+
+// withPodEventHandlers : wrap a plugin factory so that the plugin registers its pod event handlers in handlers
+func withPodEventHandlers(factory frameworkruntime.PluginFactory, handlers *podEventHandlers) frameworkruntime.PluginFactory {
+	return func(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+		return factory(ctx, obj, &replayHandle{Handle: handle, handlers: handlers})
+	}
+}
+
+// replayHandle : framework handle whose pod informer registers the event handlers in handlers
+type replayHandle struct {
+	framework.Handle
+	handlers *podEventHandlers
+}
+
+func (h *replayHandle) SharedInformerFactory() informers.SharedInformerFactory {
+	return &replayInformerFactory{SharedInformerFactory: h.Handle.SharedInformerFactory(), handlers: h.handlers}
+}
+
+type replayInformerFactory struct {
+	informers.SharedInformerFactory
+	handlers *podEventHandlers
+}
+
+func (f *replayInformerFactory) Core() coreinformers.Interface {
+	return &replayCoreInformers{Interface: f.SharedInformerFactory.Core(), handlers: f.handlers}
+}
+
+type replayCoreInformers struct {
+	coreinformers.Interface
+	handlers *podEventHandlers
+}
+
+func (i *replayCoreInformers) V1() corev1informers.Interface {
+	return &replayCoreV1Informers{Interface: i.Interface.V1(), handlers: i.handlers}
+}
+
+type replayCoreV1Informers struct {
+	corev1informers.Interface
+	handlers *podEventHandlers
+}
+
+func (i *replayCoreV1Informers) Pods() corev1informers.PodInformer {
+	return &replayPodInformer{PodInformer: i.Interface.Pods(), handlers: i.handlers}
+}
+
+type replayPodInformer struct {
+	corev1informers.PodInformer
+	handlers *podEventHandlers
+}
+
+func (i *replayPodInformer) Informer() cache.SharedIndexInformer {
+	return &replayPodIndexInformer{SharedIndexInformer: i.PodInformer.Informer(), handlers: i.handlers}
+}
+
+type replayPodIndexInformer struct {
+	cache.SharedIndexInformer
+	handlers *podEventHandlers
+}
+
+func (i *replayPodIndexInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	i.handlers.handlers = append(i.handlers.handlers, handler)
+	return nil, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+)
+
+// loadMetrics : load the recorded metrics, a JSON array or a stream of JSON documents
+func loadMetrics(file string) ([]watcher.WatcherMetrics, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read metrics: %v", err)
+	}
+	var snapshots []watcher.WatcherMetrics
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &snapshots); err != nil {
+			return nil, fmt.Errorf("unable to decode metrics: %v", err)
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var snapshot watcher.WatcherMetrics
+			if err := decoder.Decode(&snapshot); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("unable to decode metrics: %v", err)
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no metrics in %v", file)
+	}
+	return snapshots, nil
+}
+
+// loadManifests : load the nodes and pods of the manifests, in order, ignoring other objects
+func loadManifests(files []string) ([]*v1.Node, []*v1.Pod, error) {
+	var nodes []*v1.Node
+	var pods []*v1.Pod
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read manifests: %v", err)
+		}
+		objs, err := decodeManifests(f)
+		f.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to decode manifests of %v: %v", file, err)
+		}
+		for _, obj := range objs {
+			switch t := obj.(type) {
+			case *v1.Node:
+				nodes = append(nodes, t)
+			case *v1.Pod:
+				if t.Namespace == "" {
+					t.Namespace = "default"
+				}
+				if t.UID == "" {
+					t.UID = types.UID(fmt.Sprintf("replay-%v-%v", t.Namespace, t.Name))
+				}
+				pods = append(pods, t)
+			default:
+				klog.InfoS("Ignoring object", "kind", obj.GetObjectKind().GroupVersionKind().Kind)
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, nil, fmt.Errorf("no nodes in manifests")
+	}
+	return nodes, pods, nil
+}
+
+// decodeManifests : decode the objects of a YAML or JSON stream, expanding the lists
+func decodeManifests(r io.Reader) ([]runtime.Object, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	deserializer := scheme.Codecs.UniversalDeserializer()
+	var objs []runtime.Object
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, _, err := deserializer.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if list, ok := obj.(*v1.List); ok {
+			for _, item := range list.Items {
+				itemObj, _, err := deserializer.Decode(item.Raw, nil, nil)
+				if err != nil {
+					return nil, err
+				}
+				objs = append(objs, itemObj)
+			}
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
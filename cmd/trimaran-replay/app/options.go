@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// OutputText : report as a human readable table
+	OutputText = "text"
+	// OutputJSON : report as JSON
+	OutputJSON = "json"
+)
+
+type ReplayOptions struct {
+	ConfigFile    string
+	MetricsFile   string
+	ManifestFiles []string
+	PodsPerStep   int
+	UsageRatio    float64
+	Output        string
+}
+
+func NewReplayOptions() *ReplayOptions {
+	options := &ReplayOptions{}
+	options.addAllFlags()
+	return options
+}
+
+func (o *ReplayOptions) addAllFlags() {
+	pflag.StringVar(&o.ConfigFile, "config", "", "Scheduler configuration file; every profile is replayed independently.")
+	pflag.StringVar(&o.MetricsFile, "metrics", "", "File of recorded load watcher metrics, a JSON array or a stream of JSON documents, one per metrics update.")
+	pflag.StringSliceVar(&o.ManifestFiles, "manifests", nil, "YAML or JSON manifests of the nodes and pods; the pods without node arrive in order.")
+	pflag.IntVar(&o.PodsPerStep, "pods-per-step", 0, "Number of pods arriving between two metrics updates; spread evenly over the metrics updates if 0.")
+	pflag.Float64Var(&o.UsageRatio, "usage-ratio", 1, "Ratio of the requests of the replayed pods added to the utilization of their node.")
+	pflag.StringVar(&o.Output, "output", OutputText, "Format of the report, text or json.")
+}
+
+// Validate : check the options
+func (o *ReplayOptions) Validate() error {
+	if o.ConfigFile == "" {
+		return fmt.Errorf("missing scheduler configuration file")
+	}
+	if o.MetricsFile == "" {
+		return fmt.Errorf("missing metrics file")
+	}
+	if len(o.ManifestFiles) == 0 {
+		return fmt.Errorf("missing manifests")
+	}
+	if o.PodsPerStep < 0 {
+		return fmt.Errorf("invalid pods per step, got %v", o.PodsPerStep)
+	}
+	if o.UsageRatio < 0 {
+		return fmt.Errorf("invalid usage ratio, got %v", o.UsageRatio)
+	}
+	if o.Output != OutputText && o.Output != OutputJSON {
+		return fmt.Errorf("invalid output, got %v", o.Output)
+	}
+	return nil
+}
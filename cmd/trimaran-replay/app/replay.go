@@ -0,0 +1,537 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/cmd/kube-scheduler/app/options"
+	schedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	pluginv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran/loadceiling"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran/loadvariationriskbalancing"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran/lowriskovercommitment"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran/targetloadpacking"
+
+	// Ensure scheme package is initialized.
+	_ "sigs.k8s.io/scheduler-plugins/apis/config/scheme"
+)
+
+// Run : replay the pods of the manifests against the recorded metrics through every profile of the scheduler
+// configuration, and write the report to out
+func Run(ctx context.Context, o *ReplayOptions, out io.Writer) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	cfg, err := options.LoadConfigFromFile(klog.FromContext(ctx), o.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("unable to load scheduler configuration: %v", err)
+	}
+	snapshots, err := loadMetrics(o.MetricsFile)
+	if err != nil {
+		return err
+	}
+	nodes, pods, err := loadManifests(o.ManifestFiles)
+	if err != nil {
+		return err
+	}
+
+	report := &Report{}
+	for i := range cfg.Profiles {
+		profileReport, err := replayProfile(ctx, o, &cfg.Profiles[i], snapshots, nodes, pods)
+		if err != nil {
+			return fmt.Errorf("unable to replay profile %v: %v", cfg.Profiles[i].SchedulerName, err)
+		}
+		report.Profiles = append(report.Profiles, profileReport)
+	}
+	return report.write(out, o.Output)
+}
+
+// bindDelay : time between the collection of the metrics of a step and the binding of the pods of the step
+const bindDelay = time.Second
+
+// replayProfile : replay the pods through the plugins of the profile, the metrics being served by a local
+// load watcher endpoint
+func replayProfile(ctx context.Context, o *ReplayOptions, profile *schedulerconfig.KubeSchedulerProfile,
+	snapshots []watcher.WatcherMetrics, nodes []*v1.Node, pods []*v1.Pod) (*ProfileReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r := newReplayer(nodes, snapshots, o.UsageRatio)
+	// the plugins follow the replay clock, advanced from the windows of the recorded metrics
+	now := snapshotTime(&snapshots[0])
+	clock := clocktesting.NewFakeClock(now)
+	ctx = trimaran.WithClock(ctx, clock)
+	address, err := r.serveMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// all the Trimaran plugins of the profile fetch the replayed metrics
+	var specs []*pluginConfig.TrimaranSpec
+	for _, pc := range profile.PluginConfig {
+		if spec := trimaranSpec(pc.Args); spec != nil {
+			spec.WatcherAddress = address
+			specs = append(specs, spec)
+		}
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no Trimaran plugin configured")
+	}
+	// the served metrics reflect the pods bound a reporting interval before the end of their window
+	reportingInterval := specs[0].MetricsAgentReportingIntervalSeconds
+	if reportingInterval <= 0 {
+		reportingInterval = pluginv1.DefaultMetricsAgentReportingIntervalSeconds
+	}
+	for _, spec := range specs[1:] {
+		if spec.MetricsAgentReportingIntervalSeconds != specs[0].MetricsAgentReportingIntervalSeconds {
+			return nil, fmt.Errorf("different metrics agent reporting intervals, got %v and %v",
+				specs[0].MetricsAgentReportingIntervalSeconds, spec.MetricsAgentReportingIntervalSeconds)
+		}
+	}
+
+	registry := plugins.NewInTreeRegistry()
+	for name, factory := range map[string]frameworkruntime.PluginFactory{
+		loadceiling.Name:                loadceiling.New,
+		loadvariationriskbalancing.Name: loadvariationriskbalancing.New,
+		lowriskovercommitment.Name:      lowriskovercommitment.New,
+		targetloadpacking.Name:          targetloadpacking.New,
+	} {
+		if err := registry.Register(name, withPodEventHandlers(factory, &r.handlers)); err != nil {
+			return nil, err
+		}
+	}
+	client := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	fwk, err := frameworkruntime.NewFramework(ctx, registry, profile,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(r),
+		frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+
+	// the collectors shared with the plugins, updated at each step
+	var collectors []*trimaran.Collector
+	for _, spec := range specs {
+		collector, err := trimaran.GetCollector(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		collectors = append(collectors, collector)
+	}
+
+	var pending []*v1.Pod
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			pending = append(pending, pod)
+			continue
+		}
+		// the usage of the pods already running is part of the recorded metrics
+		nodeInfo, ok := r.nodeInfoMap[pod.Spec.NodeName]
+		if !ok {
+			klog.InfoS("Ignoring pod bound to unknown node", "pod", klog.KObj(pod), "node", pod.Spec.NodeName)
+			continue
+		}
+		nodeInfo.AddPod(pod)
+	}
+
+	podsPerStep := o.PodsPerStep
+	if podsPerStep == 0 {
+		podsPerStep = int(math.Ceil(float64(len(pending)) / float64(len(snapshots))))
+		if podsPerStep < 1 {
+			podsPerStep = 1
+		}
+	}
+	steps := len(snapshots)
+	if n := int(math.Ceil(float64(len(pending)) / float64(podsPerStep))); n > steps {
+		steps = n
+	}
+
+	report := &ProfileReport{SchedulerName: profile.SchedulerName}
+	for step := 0; step < steps; step++ {
+		if step > 0 {
+			now = now.Add(r.stepInterval(step))
+		}
+		clock.SetTime(now)
+		r.setMetrics(step, now, reportingInterval)
+		for _, collector := range collectors {
+			if err := collector.UpdateMetrics(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		// The pods bound at this step are expired from the predicted usage of the plugins once the metrics
+		// reflect them, i.e. once the end of the window is a reporting interval after their binding, which
+		// happens from the next step on.
+		clock.SetTime(now.Add(bindDelay))
+		for i := step * podsPerStep; i < (step+1)*podsPerStep && i < len(pending); i++ {
+			pod := pending[i]
+			nodeName, err := r.schedule(ctx, fwk, pod)
+			if err != nil {
+				klog.V(4).InfoS("Unable to schedule pod", "pod", klog.KObj(pod), "err", err)
+				report.Unschedulable = append(report.Unschedulable, klog.KObj(pod).String())
+				continue
+			}
+			klog.V(4).InfoS("Scheduled pod", "pod", klog.KObj(pod), "node", nodeName)
+			r.bind(pod, nodeName)
+			report.Scheduled++
+		}
+	}
+	// the metrics once the last pods are reflected
+	r.setMetrics(steps-1, now.Add(r.stepInterval(steps)), reportingInterval)
+
+	r.report(report)
+	return report, nil
+}
+
+// trimaranSpec : get the TrimaranSpec of the args of a Trimaran plugin, nil for other plugins
+func trimaranSpec(args interface{}) *pluginConfig.TrimaranSpec {
+	switch t := args.(type) {
+	case *pluginConfig.TargetLoadPackingArgs:
+		return &t.TrimaranSpec
+	case *pluginConfig.LoadVariationRiskBalancingArgs:
+		return &t.TrimaranSpec
+	case *pluginConfig.LowRiskOverCommitmentArgs:
+		return &t.TrimaranSpec
+	case *pluginConfig.LoadCeilingArgs:
+		return &t.TrimaranSpec
+	default:
+		return nil
+	}
+}
+
+// replayer : state of the cluster during the replay, listed to the plugins as the scheduler snapshot
+type replayer struct {
+	nodeInfos   []*framework.NodeInfo
+	nodeInfoMap map[string]*framework.NodeInfo
+
+	// recorded metrics, one per step
+	snapshots []watcher.WatcherMetrics
+	// ratio of the requests of the replayed pods added to the utilization of their node
+	usageRatio float64
+	// simulated usage of the replayed pods, by node name
+	usage map[string]*framework.Resource
+	// peak utilization served, by node name
+	peaks map[string]*nodeUtilization
+
+	// metrics served to the plugins
+	metrics watcher.WatcherMetrics
+	// for safe access to metrics
+	mu sync.RWMutex
+
+	handlers podEventHandlers
+}
+
+// nodeUtilization : utilization of the node, in percent
+type nodeUtilization struct {
+	cpu    float64
+	memory float64
+}
+
+func newReplayer(nodes []*v1.Node, snapshots []watcher.WatcherMetrics, usageRatio float64) *replayer {
+	r := &replayer{
+		nodeInfoMap: make(map[string]*framework.NodeInfo, len(nodes)),
+		snapshots:   snapshots,
+		usageRatio:  usageRatio,
+		usage:       make(map[string]*framework.Resource, len(nodes)),
+		peaks:       make(map[string]*nodeUtilization, len(nodes)),
+	}
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		r.nodeInfos = append(r.nodeInfos, nodeInfo)
+		r.nodeInfoMap[node.Name] = nodeInfo
+		r.usage[node.Name] = &framework.Resource{}
+		r.peaks[node.Name] = &nodeUtilization{}
+	}
+	return r
+}
+
+// snapshotTime : time at which the metrics were recorded, the end of their window if set
+func snapshotTime(snapshot *watcher.WatcherMetrics) time.Time {
+	if snapshot.Window.End > 0 {
+		return time.Unix(snapshot.Window.End, 0)
+	}
+	return time.Unix(snapshot.Timestamp, 0)
+}
+
+// stepInterval : time between the previous step and the step, the interval between their recorded metrics,
+// or between the last ones beyond. It is at least the bind delay, so that the windows of the steps
+// follow each other and reflect the pods bound at the previous steps.
+func (r *replayer) stepInterval(step int) time.Duration {
+	interval := time.Duration(pluginv1.DefaultMetricsUpdateIntervalSeconds) * time.Second
+	if step >= len(r.snapshots) {
+		step = len(r.snapshots) - 1
+	}
+	if step > 0 {
+		if recorded := snapshotTime(&r.snapshots[step]).Sub(snapshotTime(&r.snapshots[step-1])); recorded > 0 {
+			interval = recorded
+		}
+	}
+	if interval < bindDelay {
+		interval = bindDelay
+	}
+	return interval
+}
+
+// serveMetrics : serve the metrics on a local load watcher endpoint until ctx is done, returning its address
+func (r *replayer) serveMetrics(ctx context.Context) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("unable to serve metrics: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(watcher.BaseUrl, func(w http.ResponseWriter, _ *http.Request) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.metrics); err != nil {
+			klog.ErrorS(err, "Unable to encode metrics")
+		}
+	})
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.ErrorS(err, "Unable to serve metrics")
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	return "http://" + listener.Addr().String(), nil
+}
+
+// setMetrics : serve the recorded metrics of the step, or the last ones beyond, plus the simulated usage of the
+// replayed pods. The window ends a reporting interval after now, so that the metrics are fresh and reflect the
+// pods bound before now, by the replay clock.
+func (r *replayer) setMetrics(step int, now time.Time, reportingIntervalSeconds int64) {
+	snapshot := r.snapshots[len(r.snapshots)-1]
+	if step < len(r.snapshots) {
+		snapshot = r.snapshots[step]
+	}
+	metrics := watcher.WatcherMetrics{
+		Timestamp: now.Unix(),
+		Window: watcher.Window{
+			Duration: snapshot.Window.Duration,
+			Start:    now.Unix() + reportingIntervalSeconds - (snapshot.Window.End - snapshot.Window.Start),
+			End:      now.Unix() + reportingIntervalSeconds,
+		},
+		Source: snapshot.Source,
+		Data:   watcher.Data{NodeMetricsMap: make(watcher.NodeMetricsMap, len(snapshot.Data.NodeMetricsMap))},
+	}
+	for nodeName, nodeMetrics := range snapshot.Data.NodeMetricsMap {
+		added := r.addedUtilization(nodeName)
+		replayed := make([]watcher.Metric, 0, len(nodeMetrics.Metrics))
+		for _, metric := range nodeMetrics.Metrics {
+			if metric.Operator == watcher.Average || metric.Operator == watcher.Latest || metric.Operator == "" {
+				switch metric.Type {
+				case watcher.CPU:
+					metric.Value += added.cpu
+				case watcher.Memory:
+					metric.Value += added.memory
+				}
+			}
+			replayed = append(replayed, metric)
+		}
+		nodeMetrics.Metrics = replayed
+		metrics.Data.NodeMetricsMap[nodeName] = nodeMetrics
+
+		if peak, ok := r.peaks[nodeName]; ok {
+			cpu, _, _ := trimaran.GetResourceData(replayed, watcher.CPU)
+			memory, _, _ := trimaran.GetResourceData(replayed, watcher.Memory)
+			peak.cpu = math.Max(peak.cpu, cpu)
+			peak.memory = math.Max(peak.memory, memory)
+		}
+	}
+
+	r.mu.Lock()
+	r.metrics = metrics
+	r.mu.Unlock()
+}
+
+// addedUtilization : simulated utilization of the node by the replayed pods, in percent
+func (r *replayer) addedUtilization(nodeName string) *nodeUtilization {
+	nodeInfo, ok := r.nodeInfoMap[nodeName]
+	if !ok {
+		return &nodeUtilization{}
+	}
+	usage := r.usage[nodeName]
+	allocatable := nodeInfo.Node().Status.Allocatable
+	added := &nodeUtilization{}
+	if capacity := allocatable.Cpu().MilliValue(); capacity > 0 {
+		added.cpu = float64(usage.MilliCPU) * 100 / float64(capacity)
+	}
+	if capacity := allocatable.Memory().Value(); capacity > 0 {
+		added.memory = float64(usage.Memory) * 100 / float64(capacity)
+	}
+	return added
+}
+
+// schedule : run the filter and score plugins of the framework for the pod, returning the selected node
+func (r *replayer) schedule(ctx context.Context, fwk framework.Framework, pod *v1.Pod) (string, error) {
+	state := framework.NewCycleState()
+	preFilterResult, status := fwk.RunPreFilterPlugins(ctx, state, pod)
+	if !status.IsSuccess() {
+		return "", status.AsError()
+	}
+	var feasible []*framework.NodeInfo
+	for _, nodeInfo := range r.nodeInfos {
+		if !preFilterResult.AllNodes() && !preFilterResult.NodeNames.Has(nodeInfo.Node().Name) {
+			continue
+		}
+		if status := fwk.RunFilterPlugins(ctx, state, pod, nodeInfo); status.IsSuccess() {
+			feasible = append(feasible, nodeInfo)
+		}
+	}
+	switch len(feasible) {
+	case 0:
+		return "", fmt.Errorf("no feasible node")
+	case 1:
+		return feasible[0].Node().Name, nil
+	}
+
+	if status := fwk.RunPreScorePlugins(ctx, state, pod, feasible); !status.IsSuccess() {
+		return "", status.AsError()
+	}
+	scores, status := fwk.RunScorePlugins(ctx, state, pod, feasible)
+	if !status.IsSuccess() {
+		return "", status.AsError()
+	}
+	// the first node with the highest score, for a deterministic replay
+	selected := scores[0]
+	for _, score := range scores[1:] {
+		if score.TotalScore > selected.TotalScore {
+			selected = score
+		}
+	}
+	return selected.Name, nil
+}
+
+// bind : add the pod to the node, its usage to the simulated usage of the node, and notify the plugins
+func (r *replayer) bind(pod *v1.Pod, nodeName string) {
+	bound := pod.DeepCopy()
+	bound.Spec.NodeName = nodeName
+	r.nodeInfoMap[nodeName].AddPod(bound)
+
+	requested := trimaran.GetResourceRequested(bound)
+	usage := r.usage[nodeName]
+	usage.MilliCPU += int64(float64(requested.MilliCPU) * r.usageRatio)
+	usage.Memory += int64(float64(requested.Memory) * r.usageRatio)
+
+	r.handlers.onAdd(bound)
+}
+
+// report : report the utilization of the nodes
+func (r *replayer) report(report *ProfileReport) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, nodeInfo := range r.nodeInfos {
+		nodeName := nodeInfo.Node().Name
+		nodeReport := &NodeReport{
+			Name: nodeName,
+			Pods: len(nodeInfo.Pods),
+		}
+		if nodeMetrics, ok := r.metrics.Data.NodeMetricsMap[nodeName]; ok {
+			nodeReport.CPU, _, _ = trimaran.GetResourceData(nodeMetrics.Metrics, watcher.CPU)
+			nodeReport.Memory, _, _ = trimaran.GetResourceData(nodeMetrics.Metrics, watcher.Memory)
+			nodeReport.PeakCPU = r.peaks[nodeName].cpu
+			nodeReport.PeakMemory = r.peaks[nodeName].memory
+		}
+		report.Nodes = append(report.Nodes, nodeReport)
+	}
+	report.summarize()
+}
+
+func (r *replayer) NodeInfos() framework.NodeInfoLister {
+	return r
+}
+
+func (r *replayer) StorageInfos() framework.StorageInfoLister {
+	return r
+}
+
+func (r *replayer) List() ([]*framework.NodeInfo, error) {
+	return r.nodeInfos, nil
+}
+
+func (r *replayer) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	var nodeInfos []*framework.NodeInfo
+	for _, nodeInfo := range r.nodeInfos {
+		if len(nodeInfo.PodsWithAffinity) > 0 {
+			nodeInfos = append(nodeInfos, nodeInfo)
+		}
+	}
+	return nodeInfos, nil
+}
+
+func (r *replayer) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	var nodeInfos []*framework.NodeInfo
+	for _, nodeInfo := range r.nodeInfos {
+		if len(nodeInfo.PodsWithRequiredAntiAffinity) > 0 {
+			nodeInfos = append(nodeInfos, nodeInfo)
+		}
+	}
+	return nodeInfos, nil
+}
+
+func (r *replayer) Get(nodeName string) (*framework.NodeInfo, error) {
+	nodeInfo, ok := r.nodeInfoMap[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("nodeinfo not found for node name %q", nodeName)
+	}
+	return nodeInfo, nil
+}
+
+func (r *replayer) IsPVCUsedByPods(key string) bool {
+	for _, nodeInfo := range r.nodeInfos {
+		if nodeInfo.PVCRefCounts[key] > 0 {
+			return true
+		}
+	}
+	return false
+}
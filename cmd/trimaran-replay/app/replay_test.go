@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- schedulerName: target-load-packing
+  plugins:
+    score:
+      enabled:
+      - name: TargetLoadPacking
+      disabled:
+      - name: "*"
+  pluginConfig:
+  - name: TargetLoadPacking
+    args:
+      targetUtilization: 40
+      metricProvider:
+        type: KubernetesMetricsServer
+- schedulerName: load-variation-risk-balancing
+  plugins:
+    score:
+      enabled:
+      - name: LoadVariationRiskBalancing
+      disabled:
+      - name: "*"
+  pluginConfig:
+  - name: LoadVariationRiskBalancing
+    args:
+      metricProvider:
+        type: KubernetesMetricsServer
+`
+
+const testMetrics = `
+{"timestamp": 1700000000, "window": {"duration": "15m", "start": 1699999100, "end": 1700000000},
+ "data": {"NodeMetricsMap": {
+  "node-1": {"metrics": [{"type": "CPU", "operator": "AVG", "value": 10}, {"type": "Memory", "operator": "AVG", "value": 10}]},
+  "node-2": {"metrics": [{"type": "CPU", "operator": "AVG", "value": 30}, {"type": "Memory", "operator": "AVG", "value": 30}]}}}}
+{"timestamp": 1700000060, "window": {"duration": "15m", "start": 1699999160, "end": 1700000060},
+ "data": {"NodeMetricsMap": {
+  "node-1": {"metrics": [{"type": "CPU", "operator": "AVG", "value": 10}, {"type": "Memory", "operator": "AVG", "value": 10}]},
+  "node-2": {"metrics": [{"type": "CPU", "operator": "AVG", "value": 30}, {"type": "Memory", "operator": "AVG", "value": 30}]}}}}
+`
+
+const testManifests = `
+apiVersion: v1
+kind: Node
+metadata:
+  name: node-1
+status:
+  capacity: {cpu: "10", memory: 10Gi, pods: "110"}
+  allocatable: {cpu: "10", memory: 10Gi, pods: "110"}
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: node-2
+status:
+  capacity: {cpu: "10", memory: 10Gi, pods: "110"}
+  allocatable: {cpu: "10", memory: 10Gi, pods: "110"}
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: pod-1
+  spec:
+    containers:
+    - name: c
+      image: c
+      resources:
+        requests: {cpu: "1", memory: 1Gi}
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: pod-2
+  spec:
+    containers:
+    - name: c
+      image: c
+      resources:
+        requests: {cpu: "1", memory: 1Gi}
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: pod-3
+  spec:
+    containers:
+    - name: c
+      image: c
+      resources:
+        requests: {cpu: "1", memory: 1Gi}
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: pod-4
+  spec:
+    containers:
+    - name: c
+      image: c
+      resources:
+        requests: {cpu: "1", memory: 1Gi}
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	o := &ReplayOptions{
+		ConfigFile:    filepath.Join(tmpDir, "config.yaml"),
+		MetricsFile:   filepath.Join(tmpDir, "metrics.json"),
+		ManifestFiles: []string{filepath.Join(tmpDir, "manifests.yaml")},
+		UsageRatio:    1,
+		Output:        OutputJSON,
+	}
+	require.NoError(t, os.WriteFile(o.ConfigFile, []byte(testConfig), 0600))
+	require.NoError(t, os.WriteFile(o.MetricsFile, []byte(testMetrics), 0600))
+	require.NoError(t, os.WriteFile(o.ManifestFiles[0], []byte(testManifests), 0600))
+
+	var out bytes.Buffer
+	require.NoError(t, Run(context.Background(), o, &out))
+	report := &Report{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), report))
+	require.Len(t, report.Profiles, 2)
+
+	// Packing up to the target utilization, pod-1 lands on node-2, reaching the target, and the following pods
+	// on node-1, accounting for the predicted usage of pod-1 until the metrics reflect it.
+	tlp := report.Profiles[0]
+	assert.Equal(t, "target-load-packing", tlp.SchedulerName)
+	assert.Equal(t, 4, tlp.Scheduled)
+	assert.Empty(t, tlp.Unschedulable)
+	assert.Equal(t, []*NodeReport{
+		{Name: "node-1", CPU: 40, Memory: 40, PeakCPU: 40, PeakMemory: 40, Pods: 3},
+		{Name: "node-2", CPU: 40, Memory: 40, PeakCPU: 40, PeakMemory: 40, Pods: 1},
+	}, tlp.Nodes)
+	assert.Equal(t, &Distribution{Mean: 40, Min: 40, P50: 40, P90: 40, Max: 40}, tlp.CPU)
+
+	// Balancing the risk from the metrics only, the pods of a step land on the same node, ties going to the
+	// first node.
+	lvrb := report.Profiles[1]
+	assert.Equal(t, "load-variation-risk-balancing", lvrb.SchedulerName)
+	assert.Equal(t, 4, lvrb.Scheduled)
+	assert.Equal(t, []*NodeReport{
+		{Name: "node-1", CPU: 50, Memory: 50, PeakCPU: 50, PeakMemory: 50, Pods: 4},
+		{Name: "node-2", CPU: 30, Memory: 30, PeakCPU: 30, PeakMemory: 30, Pods: 0},
+	}, lvrb.Nodes)
+	assert.Equal(t, &Distribution{Mean: 40, StdDev: 10, Min: 30, P50: 30, P90: 50, Max: 50}, lvrb.CPU)
+}
+
+func TestNewDistribution(t *testing.T) {
+	d := newDistribution([]float64{40, 10, 30, 20})
+	assert.Equal(t, &Distribution{Mean: 25, StdDev: 11.180339887498949, Min: 10, P50: 20, P90: 40, Max: 40}, d)
+	assert.Equal(t, &Distribution{}, newDistribution(nil))
+}
+
+func TestValidate(t *testing.T) {
+	valid := ReplayOptions{
+		ConfigFile:    "config.yaml",
+		MetricsFile:   "metrics.json",
+		ManifestFiles: []string{"manifests.yaml"},
+		UsageRatio:    1,
+		Output:        OutputText,
+	}
+	assert.NoError(t, valid.Validate())
+
+	for name, modify := range map[string]func(o *ReplayOptions){
+		"missing config":     func(o *ReplayOptions) { o.ConfigFile = "" },
+		"missing metrics":    func(o *ReplayOptions) { o.MetricsFile = "" },
+		"missing manifests":  func(o *ReplayOptions) { o.ManifestFiles = nil },
+		"negative pods":      func(o *ReplayOptions) { o.PodsPerStep = -1 },
+		"negative usage":     func(o *ReplayOptions) { o.UsageRatio = -1 },
+		"unsupported output": func(o *ReplayOptions) { o.Output = "yaml" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			o := valid
+			modify(&o)
+			assert.Error(t, o.Validate())
+		})
+	}
+}
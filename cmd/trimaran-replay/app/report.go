@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Report : report of the replay through every profile
+type Report struct {
+	Profiles []*ProfileReport `json:"profiles"`
+}
+
+// ProfileReport : report of the replay through a profile
+type ProfileReport struct {
+	SchedulerName string `json:"schedulerName"`
+	// number of replayed pods bound to a node
+	Scheduled int `json:"scheduled"`
+	// replayed pods without a feasible node
+	Unschedulable []string `json:"unschedulable,omitempty"`
+	// utilization of the nodes, in the order of the manifests
+	Nodes []*NodeReport `json:"nodes"`
+	// distributions of the utilization of the nodes at the end of the replay
+	CPU    *Distribution `json:"cpu"`
+	Memory *Distribution `json:"memory"`
+}
+
+// NodeReport : utilization of a node, in percent
+type NodeReport struct {
+	Name string `json:"name"`
+	// average utilization at the end of the replay
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+	// highest average utilization during the replay
+	PeakCPU    float64 `json:"peakCPU"`
+	PeakMemory float64 `json:"peakMemory"`
+	// number of pods on the node at the end of the replay
+	Pods int `json:"pods"`
+}
+
+// Distribution : distribution of the utilization of the nodes, in percent
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	Min    float64 `json:"min"`
+	P50    float64 `json:"p50"`
+	P90    float64 `json:"p90"`
+	Max    float64 `json:"max"`
+}
+
+// summarize : compute the distributions of the utilization of the nodes
+func (p *ProfileReport) summarize() {
+	cpu := make([]float64, 0, len(p.Nodes))
+	memory := make([]float64, 0, len(p.Nodes))
+	for _, node := range p.Nodes {
+		cpu = append(cpu, node.CPU)
+		memory = append(memory, node.Memory)
+	}
+	p.CPU = newDistribution(cpu)
+	p.Memory = newDistribution(memory)
+}
+
+// newDistribution : get the distribution of the values
+func newDistribution(values []float64) *Distribution {
+	d := &Distribution{}
+	if len(values) == 0 {
+		return d
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	d.Mean = sum / float64(len(sorted))
+	var variance float64
+	for _, v := range sorted {
+		variance += (v - d.Mean) * (v - d.Mean)
+	}
+	d.StdDev = math.Sqrt(variance / float64(len(sorted)))
+	d.Min = sorted[0]
+	d.P50 = percentile(sorted, 50)
+	d.P90 = percentile(sorted, 90)
+	d.Max = sorted[len(sorted)-1]
+	return d
+}
+
+// percentile : get the nearest-rank percentile of the sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// write : write the report to out in the output format
+func (r *Report) write(out io.Writer, output string) error {
+	if output == OutputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for i, p := range r.Profiles {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Profile: %v\n", p.SchedulerName)
+		fmt.Fprintf(w, "Scheduled: %v, Unschedulable: %v\n\n", p.Scheduled, len(p.Unschedulable))
+		fmt.Fprintln(w, "NODE\tCPU%\tPEAK CPU%\tMEMORY%\tPEAK MEMORY%\tPODS")
+		for _, node := range p.Nodes {
+			fmt.Fprintf(w, "%v\t%.1f\t%.1f\t%.1f\t%.1f\t%v\n",
+				node.Name, node.CPU, node.PeakCPU, node.Memory, node.PeakMemory, node.Pods)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "\tMEAN\tSTDDEV\tMIN\tP50\tP90\tMAX")
+		for _, d := range []struct {
+			name string
+			*Distribution
+		}{{"CPU%", p.CPU}, {"MEMORY%", p.Memory}} {
+			fmt.Fprintf(w, "%v\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\n",
+				d.name, d.Mean, d.StdDev, d.Min, d.P50, d.P90, d.Max)
+		}
+	}
+	return w.Flush()
+}
@@ -19,15 +19,21 @@ package capacityscheduling
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	corelisters "k8s.io/client-go/listers/core/v1"
@@ -43,6 +49,9 @@ import (
 	ctrlruntimecache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	pluginv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
@@ -58,11 +67,27 @@ func init() {
 // CapacityScheduling is a plugin that implements the mechanism of capacity scheduling.
 type CapacityScheduling struct {
 	sync.RWMutex
-	fh                framework.Handle
-	podLister         corelisters.PodLister
-	pdbLister         policylisters.PodDisruptionBudgetLister
-	client            client.Client
+	fh        framework.Handle
+	podLister corelisters.PodLister
+	pdbLister policylisters.PodDisruptionBudgetLister
+	client    client.Client
+	// podGroupReader reads the PodGroups from the informer cache, nil without the PodGroup CRD.
+	podGroupReader client.Reader
+	// podGroupSynced tells whether the PodGroups are in the cache, which is assumed if nil.
+	podGroupSynced    func() bool
 	elasticQuotaInfos ElasticQuotaInfos
+	victimCostModel   victimCostModel
+
+	// borrowingDisabled prevents quotas from using more than their min.
+	borrowingDisabled bool
+	// crossQuotaPreemptionDisabled prevents pods from preempting the pods of other quotas.
+	crossQuotaPreemptionDisabled bool
+	// trackedResources are the resources accounted against the quotas, all of them if nil.
+	trackedResources sets.Set[v1.ResourceName]
+	// defaultQuota is applied to the namespaces without an ElasticQuota if not nil.
+	defaultQuota *config.DefaultQuota
+	// bounds are the max and min of the resources the quotas don't set.
+	bounds *quotaBounds
 }
 
 // PreFilterState computed at PreFilter and used at PostFilter or Reserve.
@@ -78,6 +103,14 @@ type PreFilterState struct {
 	// 1. the pods subject to the same quota(namespace) and is more important than the preemptor.
 	// 2. the pods subject to the different quota(namespace) and the usage of quota(namespace) does not exceed min.
 	nominatedPodsReqWithPodReq framework.Resource
+
+	// pgFullName is the full name of the PodGroup the pod belongs to, if any.
+	pgFullName string
+
+	// gangReq is the quota the pod's PodGroup still needs to reach MinMember.
+	// It is nil when the pod doesn't belong to a PodGroup, or its PodGroup is
+	// already admitted, and is reserved for the whole group at Reserve.
+	gangReq *framework.Resource
 }
 
 // Clone the preFilter state.
@@ -117,13 +150,69 @@ func (c *CapacityScheduling) Name() string {
 	return Name
 }
 
+// getArgs returns the args of the plugin, defaulted as in the v1 API if the profile doesn't configure them.
+func getArgs(obj runtime.Object) (*config.CapacitySchedulingArgs, error) {
+	if obj == nil {
+		v1Args := &pluginv1.CapacitySchedulingArgs{}
+		pluginv1.SetDefaults_CapacitySchedulingArgs(v1Args)
+		args := &config.CapacitySchedulingArgs{}
+		if err := pluginv1.Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(v1Args, args, nil); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+	args, ok := obj.(*config.CapacitySchedulingArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type CapacitySchedulingArgs, got %T", obj)
+	}
+	return args, nil
+}
+
+// newFromArgs returns the plugin configured by its args, without the informers of the ElasticQuotas,
+// the PodGroups and the pods.
+func newFromArgs(obj runtime.Object, handle framework.Handle) (*CapacityScheduling, error) {
+	args, err := getArgs(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateCapacitySchedulingArgs(nil, args); err != nil {
+		return nil, err
+	}
+	costModel, err := newVictimCostModel(args.VictimCostModel)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CapacityScheduling{
+		fh:                           handle,
+		elasticQuotaInfos:            NewElasticQuotaInfos(),
+		podLister:                    handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+		pdbLister:                    getPDBLister(handle.SharedInformerFactory()),
+		victimCostModel:              costModel,
+		borrowingDisabled:            !args.EnableBorrowing,
+		crossQuotaPreemptionDisabled: !args.AllowCrossQuotaPreemption,
+		defaultQuota:                 args.DefaultQuota,
+		bounds: &quotaBounds{
+			upperBoundOfMax: args.UpperBoundOfMax,
+			lowerBoundOfMin: args.LowerBoundOfMin,
+		},
+	}
+	if len(args.TrackedResources) != 0 {
+		c.trackedResources = sets.New(args.TrackedResources...)
+	}
+	RegisterMetrics()
+	if c.defaultQuota != nil && c.defaultQuota.Shared {
+		c.elasticQuotaInfos[CatchAllQuotaNamespace] = c.newDefaultElasticQuotaInfo(CatchAllQuotaNamespace)
+		updateQuotaMetrics(c.elasticQuotaInfos[CatchAllQuotaNamespace])
+	}
+	return c, nil
+}
+
 // New initializes a new plugin and returns it.
 func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
-	c := &CapacityScheduling{
-		fh:                handle,
-		elasticQuotaInfos: NewElasticQuotaInfos(),
-		podLister:         handle.SharedInformerFactory().Core().V1().Pods().Lister(),
-		pdbLister:         getPDBLister(handle.SharedInformerFactory()),
+	c, err := newFromArgs(obj, handle)
+	if err != nil {
+		return nil, err
 	}
 
 	client, err := client.New(handle.KubeConfig(), client.Options{Scheme: scheme})
@@ -164,6 +253,39 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 		},
 	})
 
+	// The PodGroups are read at every PreFilter of their pods, so serve them from the cache.
+	// Without the PodGroup CRD, the pods of PodGroups are admitted on their own.
+	podGroupInformer, err := dynamicCache.GetInformer(ctx, &v1alpha1.PodGroup{})
+	if meta.IsNoMatchError(err) {
+		klog.InfoS("PodGroup kind not found, admitting the pods of PodGroups on their own", "err", err)
+	} else if err != nil {
+		return nil, err
+	} else {
+		podGroupInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1alpha1.PodGroup:
+					return true
+				case cache.DeletedFinalStateUnknown:
+					_, ok := t.Obj.(*v1alpha1.PodGroup)
+					return ok
+				default:
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				DeleteFunc: c.deletePodGroup,
+			},
+		})
+		c.podGroupReader = dynamicCache
+		c.podGroupSynced = podGroupInformer.HasSynced
+	}
+	go func() {
+		if err := dynamicCache.Start(ctx); err != nil {
+			klog.ErrorS(err, "Failed to start the ElasticQuota and PodGroup cache")
+		}
+	}()
+
 	podInformer := handle.SharedInformerFactory().Core().V1().Pods().Informer()
 	podInformer.AddEventHandler(
 		cache.FilteringResourceEventHandler{
@@ -187,6 +309,27 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 			},
 		},
 	)
+	// The quota held for a PodGroup is released once none of its members is left to schedule.
+	podInformer.AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.Pod:
+					return pendingGangPod(t)
+				case cache.DeletedFinalStateUnknown:
+					if pod, ok := t.Obj.(*v1.Pod); ok {
+						return pendingGangPod(pod)
+					}
+					return false
+				default:
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				DeleteFunc: c.deletePendingGangPod,
+			},
+		},
+	)
 	klog.InfoS("CapacityScheduling start")
 	return c, nil
 }
@@ -209,12 +352,16 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 	// TODO improve the efficiency of taking snapshot
 	// e.g. use a two-pointer data structure to only copy the updated EQs when necessary.
 	snapshotElasticQuota := c.snapshotElasticQuota()
-	podReq := computePodResourceRequest(pod)
+	podReq := c.podResourceRequest(pod)
 
 	state.Write(ElasticQuotaSnapshotKey, snapshotElasticQuota)
 
 	elasticQuotaInfos := snapshotElasticQuota.elasticQuotaInfos
-	eq := snapshotElasticQuota.elasticQuotaInfos[pod.Namespace]
+	eq := snapshotElasticQuota.elasticQuotaInfos.get(pod.Namespace)
+	if eq == nil && c.defaultQuotaApplies(pod.Namespace) {
+		eq = c.newDefaultElasticQuotaInfo(pod.Namespace)
+		elasticQuotaInfos[pod.Namespace] = eq
+	}
 	if eq == nil {
 		preFilterState := &PreFilterState{
 			podReq: *podReq,
@@ -237,6 +384,20 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("Error getting the nodelist: %v", err))
 	}
 
+	// admissionReq is the request checked against the quota. For a pod in a PodGroup
+	// that hasn't reached MinMember yet, it is the quota needed by the whole group, so
+	// that part of a gang can't consume quota the rest of the gang would need.
+	// For a pod whose PodGroup already holds a reservation, only the part of its
+	// request not covered by the reservation is checked.
+	admissionReq := podReq
+	pgFullName, gangReq := c.gangRequest(ctx, pod, podReq, eq)
+	if reserved, ok := eq.gangReservation(pgFullName); ok {
+		admissionReq = subtractNonNegative(podReq, reserved)
+		gangReq = nil
+	} else if gangReq != nil {
+		admissionReq = gangReq
+	}
+
 	for _, node := range nodeList {
 		nominatedPods := c.fh.NominatedPodsForNode(node.Node().Name)
 		for _, p := range nominatedPods {
@@ -244,37 +405,52 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 				continue
 			}
 			ns := p.Pod.Namespace
-			info := c.elasticQuotaInfos[ns]
+			info := c.elasticQuotaInfos.get(ns)
 			if info != nil {
-				pResourceRequest := util.ResourceList(computePodResourceRequest(p.Pod))
+				pResourceRequest := util.ResourceList(c.podResourceRequest(p.Pod))
 				// If they are subject to the same quota(namespace) and p is more important than pod,
 				// p will be added to the nominatedResource and totalNominatedResource.
 				// If they aren't subject to the same quota(namespace) and the usage of quota(p's namespace) does not exceed min,
 				// p will be added to the totalNominatedResource.
-				if ns == pod.Namespace && corev1helpers.PodPriority(p.Pod) >= corev1helpers.PodPriority(pod) {
+				if info.Namespace == eq.Namespace && corev1helpers.PodPriority(p.Pod) >= corev1helpers.PodPriority(pod) {
 					nominatedPodsReqInEQWithPodReq.Add(pResourceRequest)
 					nominatedPodsReqWithPodReq.Add(pResourceRequest)
-				} else if ns != pod.Namespace && !info.usedOverMin() {
+				} else if info.Namespace != eq.Namespace && !info.usedOverMin() {
 					nominatedPodsReqWithPodReq.Add(pResourceRequest)
 				}
 			}
 		}
 	}
 
-	nominatedPodsReqInEQWithPodReq.Add(util.ResourceList(podReq))
-	nominatedPodsReqWithPodReq.Add(util.ResourceList(podReq))
+	nominatedPodsReqInEQWithPodReq.Add(util.ResourceList(admissionReq))
+	nominatedPodsReqWithPodReq.Add(util.ResourceList(admissionReq))
 	preFilterState := &PreFilterState{
 		podReq:                         *podReq,
 		nominatedPodsReqInEQWithPodReq: *nominatedPodsReqInEQWithPodReq,
 		nominatedPodsReqWithPodReq:     *nominatedPodsReqWithPodReq,
+		pgFullName:                     pgFullName,
+		gangReq:                        gangReq,
 	}
 	state.Write(preFilterStateKey, preFilterState)
 
 	if eq.usedOverMaxWith(nominatedPodsReqInEQWithPodReq) {
+		admissionRejections.WithLabelValues(eq.Namespace, rejectionReasonOverMax).Inc()
+		if gangReq != nil {
+			return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because PodGroup %v would make ElasticQuota %v more than Max", pod.Namespace, pod.Name, pgFullName, eq.Namespace))
+		}
 		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v is more than Max", pod.Namespace, pod.Name, eq.Namespace))
 	}
 
+	if c.borrowingDisabled && eq.usedOverMinWith(nominatedPodsReqInEQWithPodReq) {
+		admissionRejections.WithLabelValues(eq.Namespace, rejectionReasonBorrowingDisabled).Inc()
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v is more than Min and borrowing is disabled", pod.Namespace, pod.Name, eq.Namespace))
+	}
+
 	if elasticQuotaInfos.aggregatedUsedOverMinWith(*nominatedPodsReqWithPodReq) {
+		admissionRejections.WithLabelValues(eq.Namespace, rejectionReasonOverAggregatedMin).Inc()
+		if gangReq != nil {
+			return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because PodGroup %v would make total ElasticQuota used more than min", pod.Namespace, pod.Name, pgFullName))
+		}
 		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because total ElasticQuota used is more than min", pod.Namespace, pod.Name))
 	}
 
@@ -294,7 +470,7 @@ func (c *CapacityScheduling) AddPod(ctx context.Context, cycleState *framework.C
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
-	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[podToAdd.Pod.Namespace]
+	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos.get(podToAdd.Pod.Namespace)
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.addPodIfNotPresent(podToAdd.Pod)
 		if err != nil {
@@ -313,7 +489,7 @@ func (c *CapacityScheduling) RemovePod(ctx context.Context, cycleState *framewor
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
-	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[podToRemove.Pod.Namespace]
+	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos.get(podToRemove.Pod.Namespace)
 	if elasticQuotaInfo != nil {
 		err = elasticQuotaInfo.deletePodIfPresent(podToRemove.Pod)
 		if err != nil {
@@ -329,32 +505,53 @@ func (c *CapacityScheduling) PostFilter(ctx context.Context, state *framework.Cy
 		metrics.PreemptionAttempts.Inc()
 	}()
 
+	p := &preemptor{
+		fh:                           c.fh,
+		state:                        state,
+		victimCostModel:              c.victimCostModel,
+		borrowingDisabled:            c.borrowingDisabled,
+		crossQuotaPreemptionDisabled: c.crossQuotaPreemptionDisabled,
+		victims:                      make(map[string][]*v1.Pod),
+	}
 	pe := preemption.Evaluator{
 		PluginName: c.Name(),
 		Handler:    c.fh,
 		PodLister:  c.podLister,
 		PdbLister:  c.pdbLister,
 		State:      state,
-		Interface: &preemptor{
-			fh:    c.fh,
-			state: state,
-		},
+		Interface:  p,
 	}
 
-	return pe.Preempt(ctx, pod, m)
+	result, status := pe.Preempt(ctx, pod, m)
+	if status.IsSuccess() && result != nil && result.NominatingInfo != nil {
+		p.recordVictims(pod, result.NominatedNodeName)
+	}
+	return result, status
 }
 
 func (c *CapacityScheduling) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos.get(pod.Namespace)
+	if elasticQuotaInfo == nil && c.defaultQuotaApplies(pod.Namespace) {
+		elasticQuotaInfo = c.newDefaultElasticQuotaInfo(pod.Namespace)
+		c.elasticQuotaInfos[pod.Namespace] = elasticQuotaInfo
+	}
 	if elasticQuotaInfo != nil {
+		// The first member of a PodGroup reserves the quota for the whole group,
+		// the following members consume that reservation as they are added.
+		if preFilterState, err := getPreFilterState(state); err == nil && preFilterState.gangReq != nil {
+			if _, ok := elasticQuotaInfo.gangReservation(preFilterState.pgFullName); !ok {
+				elasticQuotaInfo.reserveGang(preFilterState.pgFullName, *preFilterState.gangReq)
+			}
+		}
 		err := elasticQuotaInfo.addPodIfNotPresent(pod)
 		if err != nil {
 			klog.ErrorS(err, "Failed to add Pod to its associated elasticQuota", "pod", klog.KObj(pod))
 			return framework.NewStatus(framework.Error, err.Error())
 		}
+		updateQuotaMetrics(elasticQuotaInfo)
 	}
 	return framework.NewStatus(framework.Success, "")
 }
@@ -363,22 +560,106 @@ func (c *CapacityScheduling) Unreserve(ctx context.Context, state *framework.Cyc
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos.get(pod.Namespace)
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.deletePodIfPresent(pod)
 		if err != nil {
 			klog.ErrorS(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(pod))
 		}
+		// A rejected member means the PodGroup is rejected as a whole,
+		// so release the quota that is still held for its other members.
+		if pgFullName := util.GetPodGroupFullName(pod); len(pgFullName) != 0 {
+			elasticQuotaInfo.releaseGang(pgFullName)
+		}
+		updateQuotaMetrics(elasticQuotaInfo)
+		c.dropDefaultQuotaIfUnused(elasticQuotaInfo)
 	}
 }
 
+// gangRequest returns the full name of the PodGroup the pod belongs to and the quota
+// the PodGroup still needs to reach MinMember: MinResources if specified, otherwise
+// MinMember times the pod request, minus the requests of the members already accounted
+// in the quota. The returned request is nil if the pod doesn't belong to a PodGroup or
+// its PodGroup has already reached MinMember.
+func (c *CapacityScheduling) gangRequest(ctx context.Context, pod *v1.Pod, podReq *framework.Resource, eq *ElasticQuotaInfo) (string, *framework.Resource) {
+	pgName := util.GetPodGroupLabel(pod)
+	if len(pgName) == 0 || c.podGroupReader == nil {
+		return "", nil
+	}
+	pgFullName := util.GetPodGroupFullName(pod)
+	// The cache blocks the reads until it is synced, so admit the pod on its own meanwhile.
+	if c.podGroupSynced != nil && !c.podGroupSynced() {
+		klog.V(4).InfoS("PodGroups not synced yet, admitting the pod on its own", "pod", klog.KObj(pod), "podGroup", pgFullName)
+		return pgFullName, nil
+	}
+
+	var pg v1alpha1.PodGroup
+	if err := c.podGroupReader.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: pgName}, &pg); err != nil {
+		klog.V(4).InfoS("Failed to get PodGroup, admitting the pod on its own", "pod", klog.KObj(pod), "podGroup", pgFullName, "err", err)
+		return pgFullName, nil
+	}
+	if pg.Spec.MinMember <= 1 && len(pg.Spec.MinResources) == 0 {
+		return pgFullName, nil
+	}
+
+	assigned, assignedReq := eq.gangMembersOf(pgFullName)
+	// The pod itself may still be accounted if it is scheduled again.
+	if key, err := framework.GetPodKey(pod); err == nil && eq.pods.Has(key) {
+		assigned--
+		assignedReq = subtractNonNegative(assignedReq, podReq)
+	}
+	if assigned >= pg.Spec.MinMember {
+		return pgFullName, nil
+	}
+
+	var gangReq *framework.Resource
+	if len(pg.Spec.MinResources) != 0 {
+		gangReq = trackedResourceRequest(framework.NewResource(pg.Spec.MinResources), c.trackedResources)
+	} else {
+		gangReq = scaleResource(podReq, int64(pg.Spec.MinMember))
+	}
+	gangReq = subtractNonNegative(gangReq, assignedReq)
+	// The pod itself must always fit, even if MinResources is smaller than its request.
+	gangReq.SetMaxResource(util.ResourceList(podReq))
+	return pgFullName, gangReq
+}
+
 type preemptor struct {
-	fh    framework.Handle
-	state *framework.CycleState
+	fh                           framework.Handle
+	state                        *framework.CycleState
+	victimCostModel              victimCostModel
+	borrowingDisabled            bool
+	crossQuotaPreemptionDisabled bool
+
+	// victims holds the victims selected on each node, to report the victims of the node
+	// the preemptor is nominated to. SelectVictimsOnNode is called in parallel for the nodes.
+	victimsLock sync.Mutex
+	victims     map[string][]*v1.Pod
+}
+
+// recordVictims reports the victims evicted so that the pod can be scheduled on the node.
+func (p *preemptor) recordVictims(pod *v1.Pod, nodeName string) {
+	elasticQuotaSnapshotState, err := getElasticQuotaSnapshotState(p.state)
+	if err != nil {
+		return
+	}
+	quotaName := func(namespace string) string {
+		if eqInfo := elasticQuotaSnapshotState.elasticQuotaInfos.get(namespace); eqInfo != nil {
+			return eqInfo.Namespace
+		}
+		return ""
+	}
+
+	p.victimsLock.Lock()
+	defer p.victimsLock.Unlock()
+	preemptorQuota := quotaName(pod.Namespace)
+	for _, victim := range p.victims[nodeName] {
+		preemptionVictims.WithLabelValues(preemptorQuota, quotaName(victim.Namespace)).Inc()
+	}
 }
 
 func (p *preemptor) OrderedScoreFuncs(ctx context.Context, nodesToVictims map[string]*extenderv1.Victims) []func(node string) int64 {
-	return nil
+	return victimCostScoreFuncs(p.victimCostModel, nodesToVictims, time.Now())
 }
 
 func (p *preemptor) GetOffsetAndNumCandidates(n int32) (int32, int32) {
@@ -432,23 +713,25 @@ func (p *preemptor) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus
 		}
 
 		podPriority := corev1helpers.PodPriority(pod)
-		preemptorEQInfo, preemptorWithEQ := elasticQuotaSnapshotState.elasticQuotaInfos[pod.Namespace]
+		preemptorEQInfo := elasticQuotaSnapshotState.elasticQuotaInfos.get(pod.Namespace)
+		preemptorWithEQ := preemptorEQInfo != nil
 		if preemptorWithEQ {
 			moreThanMinWithPreemptor := preemptorEQInfo.usedOverMinWith(&preFilterState.nominatedPodsReqInEQWithPodReq)
+			crossQuotaPreemptionDisabled := p.crossQuotaPreemptionDisabled
 			for _, p := range nodeInfo.Pods {
 				// Checking terminating pods
 				if p.Pod.DeletionTimestamp != nil {
-					eqInfo, withEQ := elasticQuotaSnapshotState.elasticQuotaInfos[p.Pod.Namespace]
-					if !withEQ {
+					eqInfo := elasticQuotaSnapshotState.elasticQuotaInfos.get(p.Pod.Namespace)
+					if eqInfo == nil {
 						continue
 					}
-					if p.Pod.Namespace == pod.Namespace && corev1helpers.PodPriority(p.Pod) < podPriority {
+					if sameQuota(eqInfo, p.Pod.Namespace, preemptorEQInfo, pod.Namespace) && corev1helpers.PodPriority(p.Pod) < podPriority {
 						// There is a terminating pod on the nominated node.
 						// If the terminating pod is in the same namespace with preemptor
 						// and it is less important than preemptor,
 						// return false to avoid preempting more pods.
 						return false, "not eligible due to a terminating pod on the nominated node."
-					} else if p.Pod.Namespace != pod.Namespace && !moreThanMinWithPreemptor && eqInfo.usedOverMin() {
+					} else if !sameQuota(eqInfo, p.Pod.Namespace, preemptorEQInfo, pod.Namespace) && !moreThanMinWithPreemptor && !crossQuotaPreemptionDisabled && eqInfo.usedOverMin() {
 						// There is a terminating pod on the nominated node.
 						// The terminating pod isn't in the same namespace with preemptor.
 						// If moreThanMinWithPreemptor is false, it indicates that preemptor can preempt the pods in other EQs whose used is over min.
@@ -460,8 +743,7 @@ func (p *preemptor) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus
 			}
 		} else {
 			for _, p := range nodeInfo.Pods {
-				_, withEQ := elasticQuotaSnapshotState.elasticQuotaInfos[p.Pod.Namespace]
-				if withEQ {
+				if eqInfo := elasticQuotaSnapshotState.elasticQuotaInfos.get(p.Pod.Namespace); eqInfo != nil {
 					continue
 				}
 				if p.Pod.DeletionTimestamp != nil && corev1helpers.PodPriority(p.Pod) < podPriority {
@@ -474,6 +756,15 @@ func (p *preemptor) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus
 	return true, ""
 }
 
+// sameQuota tells whether the pods of the two namespaces, subject to the given quotas,
+// preempt each other by the rules of the same quota. The namespaces sharing the catch-all
+// quota don't: a namespace may only preempt the pods of another one by the rules of
+// different quotas, so that it can't evict the pods of an unrelated namespace.
+func sameQuota(eqInfo *ElasticQuotaInfo, namespace string, otherEQInfo *ElasticQuotaInfo, otherNamespace string) bool {
+	return eqInfo.Namespace == otherEQInfo.Namespace &&
+		(eqInfo.Namespace != CatchAllQuotaNamespace || namespace == otherNamespace)
+}
+
 func (p *preemptor) SelectVictimsOnNode(
 	ctx context.Context,
 	state *framework.CycleState,
@@ -520,7 +811,8 @@ func (p *preemptor) SelectVictimsOnNode(
 
 	elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
 	podPriority := corev1helpers.PodPriority(pod)
-	preemptorElasticQuotaInfo, preemptorWithElasticQuota := elasticQuotaInfos[pod.Namespace]
+	preemptorElasticQuotaInfo := elasticQuotaInfos.get(pod.Namespace)
+	preemptorWithElasticQuota := preemptorElasticQuotaInfo != nil
 
 	// sort the pods in node by the priority class
 	sort.Slice(nodeInfo.Pods, func(i, j int) bool { return !schedutil.MoreImportantPod(nodeInfo.Pods[i].Pod, nodeInfo.Pods[j].Pod) })
@@ -530,19 +822,21 @@ func (p *preemptor) SelectVictimsOnNode(
 		nominatedPodsReqInEQWithPodReq = preFilterState.nominatedPodsReqInEQWithPodReq
 		nominatedPodsReqWithPodReq = preFilterState.nominatedPodsReqWithPodReq
 		moreThanMinWithPreemptor := preemptorElasticQuotaInfo.usedOverMinWith(&nominatedPodsReqInEQWithPodReq)
+		crossQuotaPreemptionDisabled := p.crossQuotaPreemptionDisabled
 		for _, p := range nodeInfo.Pods {
-			eqInfo, withEQ := elasticQuotaInfos[p.Pod.Namespace]
-			if !withEQ {
+			eqInfo := elasticQuotaInfos.get(p.Pod.Namespace)
+			if eqInfo == nil {
 				continue
 			}
 
-			if moreThanMinWithPreemptor {
+			if moreThanMinWithPreemptor || crossQuotaPreemptionDisabled {
 				// If Preemptor.Request + Quota.Used > Quota.Min:
 				// It means that its guaranteed isn't borrowed by other
 				// quotas. So that we will select the pods which subject to the
 				// same quota(namespace) with the lower priority than the
 				// preemptor's priority as potential victims in a node.
-				if p.Pod.Namespace == pod.Namespace && corev1helpers.PodPriority(p.Pod) < podPriority {
+				// The same applies if preempting the pods of other quotas is disabled.
+				if sameQuota(eqInfo, p.Pod.Namespace, preemptorElasticQuotaInfo, pod.Namespace) && corev1helpers.PodPriority(p.Pod) < podPriority {
 					potentialVictims = append(potentialVictims, p)
 					if err := removePod(p); err != nil {
 						return nil, 0, framework.AsStatus(err)
@@ -556,7 +850,7 @@ func (p *preemptor) SelectVictimsOnNode(
 				// will be chosen from Quotas that allocates more resources
 				// than its min, i.e., borrowing resources from other
 				// Quotas.
-				if p.Pod.Namespace != pod.Namespace && eqInfo.usedOverMin() {
+				if !sameQuota(eqInfo, p.Pod.Namespace, preemptorElasticQuotaInfo, pod.Namespace) && eqInfo.usedOverMin() {
 					potentialVictims = append(potentialVictims, p)
 					if err := removePod(p); err != nil {
 						return nil, 0, framework.AsStatus(err)
@@ -566,8 +860,7 @@ func (p *preemptor) SelectVictimsOnNode(
 		}
 	} else {
 		for _, p := range nodeInfo.Pods {
-			_, withEQ := elasticQuotaInfos[p.Pod.Namespace]
-			if withEQ {
+			if eqInfo := elasticQuotaInfos.get(p.Pod.Namespace); eqInfo != nil {
 				continue
 			}
 			if corev1helpers.PodPriority(p.Pod) < podPriority {
@@ -600,6 +893,7 @@ func (p *preemptor) SelectVictimsOnNode(
 	// we are almost done and this node is not suitable for preemption.
 	if preemptorWithElasticQuota {
 		if preemptorElasticQuotaInfo.usedOverMaxWith(&podReq) ||
+			(p.borrowingDisabled && preemptorElasticQuotaInfo.usedOverMinWith(&podReq)) ||
 			elasticQuotaInfos.aggregatedUsedOverMinWith(podReq) {
 			return nil, 0, framework.NewStatus(framework.Unschedulable, "global quota max exceeded")
 		}
@@ -607,12 +901,14 @@ func (p *preemptor) SelectVictimsOnNode(
 
 	var victims []*v1.Pod
 	numViolatingVictim := 0
+	moreImportant := moreImportantVictim(p.victimCostModel, time.Now())
 	sort.Slice(potentialVictims, func(i, j int) bool {
-		return schedutil.MoreImportantPod(potentialVictims[i].Pod, potentialVictims[j].Pod)
+		return moreImportant(potentialVictims[i].Pod, potentialVictims[j].Pod)
 	})
 	// Try to reprieve as many pods as possible. We first try to reprieve the PDB
 	// violating victims and then other non-violating ones. In both cases, we start
-	// from the highest priority victims.
+	// from the highest priority victims and, among them, from the most costly ones
+	// to evict if a victim cost model is configured.
 	violatingVictims, nonViolatingVictims := filterPodsWithPDBViolation(potentialVictims, pdbs)
 	reprievePod := func(pi *framework.PodInfo) (bool, error) {
 		if err := addPod(pi); err != nil {
@@ -653,44 +949,105 @@ func (p *preemptor) SelectVictimsOnNode(
 			return nil, 0, framework.AsStatus(err)
 		}
 	}
+	if p.victims != nil {
+		p.victimsLock.Lock()
+		p.victims[nodeInfo.Node().Name] = victims
+		p.victimsLock.Unlock()
+	}
 	return victims, numViolatingVictim, framework.NewStatus(framework.Success)
 }
 
 func (c *CapacityScheduling) addElasticQuota(obj interface{}) {
 	eq := obj.(*v1alpha1.ElasticQuota)
-	oldElasticQuotaInfo := c.elasticQuotaInfos[eq.Namespace]
-	if oldElasticQuotaInfo != nil {
-		return
-	}
-
-	elasticQuotaInfo := newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
+	elasticQuotaInfo := c.newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max)
 
 	c.Lock()
 	defer c.Unlock()
+	oldElasticQuotaInfo := c.elasticQuotaInfos[eq.Namespace]
+	if oldElasticQuotaInfo != nil && !oldElasticQuotaInfo.defaulted {
+		return
+	}
+	// The namespace was subject to the default quota so far, keep what it uses.
+	if oldElasticQuotaInfo != nil {
+		elasticQuotaInfo.inheritUsage(oldElasticQuotaInfo)
+	} else if catchAll := c.elasticQuotaInfos[CatchAllQuotaNamespace]; catchAll != nil {
+		c.moveNamespacePods(eq.Namespace, catchAll, elasticQuotaInfo)
+	}
 	c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfo
+	updateQuotaMetrics(elasticQuotaInfo)
 }
 
 func (c *CapacityScheduling) updateElasticQuota(oldObj, newObj interface{}) {
 	oldEQ := oldObj.(*v1alpha1.ElasticQuota)
 	newEQ := newObj.(*v1alpha1.ElasticQuota)
-	newEQInfo := newElasticQuotaInfo(newEQ.Namespace, newEQ.Spec.Min, newEQ.Spec.Max, nil)
+	newEQInfo := c.newElasticQuotaInfo(newEQ.Namespace, newEQ.Spec.Min, newEQ.Spec.Max)
 
 	c.Lock()
 	defer c.Unlock()
 
 	oldEQInfo := c.elasticQuotaInfos[oldEQ.Namespace]
 	if oldEQInfo != nil {
-		newEQInfo.pods = oldEQInfo.pods
-		newEQInfo.Used = oldEQInfo.Used
+		newEQInfo.inheritUsage(oldEQInfo)
+		deleteQuotaMetrics(oldEQInfo)
 	}
 	c.elasticQuotaInfos[newEQ.Namespace] = newEQInfo
+	updateQuotaMetrics(newEQInfo)
 }
 
 func (c *CapacityScheduling) deleteElasticQuota(obj interface{}) {
 	elasticQuota := obj.(*v1alpha1.ElasticQuota)
 	c.Lock()
 	defer c.Unlock()
+
+	// The namespace falls back to the default quota, if any, keeping what it uses.
+	oldEQInfo := c.elasticQuotaInfos[elasticQuota.Namespace]
+	if oldEQInfo != nil && c.defaultQuotaApplies(elasticQuota.Namespace) && !c.defaultQuota.Shared {
+		newEQInfo := c.newDefaultElasticQuotaInfo(elasticQuota.Namespace)
+		newEQInfo.inheritUsage(oldEQInfo)
+		c.elasticQuotaInfos[elasticQuota.Namespace] = newEQInfo
+		deleteQuotaMetrics(oldEQInfo)
+		updateQuotaMetrics(newEQInfo)
+		c.dropDefaultQuotaIfUnused(newEQInfo)
+		return
+	}
 	delete(c.elasticQuotaInfos, elasticQuota.Namespace)
+	if oldEQInfo != nil {
+		deleteQuotaMetrics(oldEQInfo)
+	}
+	if catchAll := c.elasticQuotaInfos.get(elasticQuota.Namespace); oldEQInfo != nil && catchAll != nil {
+		c.moveNamespacePods(elasticQuota.Namespace, oldEQInfo, catchAll)
+	}
+}
+
+// moveNamespacePods moves the pods of the namespace, and the quota held for its PodGroups,
+// from one quota to another. It is used when a namespace joins or leaves the catch-all quota.
+func (c *CapacityScheduling) moveNamespacePods(namespace string, from, to *ElasticQuotaInfo) {
+	pods, err := c.podLister.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list pods", "namespace", namespace)
+		return
+	}
+	for _, pod := range pods {
+		key, err := framework.GetPodKey(pod)
+		if err != nil || !from.pods.Has(key) {
+			continue
+		}
+		if err := from.deletePodIfPresent(pod); err != nil {
+			klog.ErrorS(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(pod))
+			continue
+		}
+		if err := to.addPodIfNotPresent(pod); err != nil {
+			klog.ErrorS(err, "Failed to add Pod to its associated elasticQuota", "pod", klog.KObj(pod))
+		}
+	}
+	for pgFullName, reserved := range from.gangReserved {
+		if strings.HasPrefix(pgFullName, namespace+"/") {
+			to.reserveGang(pgFullName, *reserved)
+			from.releaseGang(pgFullName)
+		}
+	}
+	updateQuotaMetrics(from)
+	updateQuotaMetrics(to)
 }
 
 func (c *CapacityScheduling) addPod(obj interface{}) {
@@ -699,7 +1056,7 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos.get(pod.Namespace)
 	// If elasticQuotaInfo is nil, try to list ElasticQuotas through elasticQuotaLister
 	if elasticQuotaInfo == nil {
 		var eqList v1alpha1.ElasticQuotaList
@@ -709,15 +1066,19 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 		}
 
 		eqs := eqList.Items
-		// If the length of elasticQuotas is 0, return.
+		// If the length of elasticQuotas is 0, fall back to the default quota if any, or return.
 		if len(eqs) == 0 {
-			return
+			if !c.defaultQuotaApplies(pod.Namespace) {
+				return
+			}
+			elasticQuotaInfo = c.newDefaultElasticQuotaInfo(pod.Namespace)
+			c.elasticQuotaInfos[pod.Namespace] = elasticQuotaInfo
 		}
 
 		if len(eqs) > 0 {
 			// only one elasticquota is supported in each namespace
 			eq := eqs[0]
-			elasticQuotaInfo = newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
+			elasticQuotaInfo = c.newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max)
 			c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfo
 		}
 	}
@@ -726,6 +1087,7 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 	if err != nil {
 		klog.ErrorS(err, "Failed to add Pod to its associated elasticQuota", "pod", klog.KObj(pod))
 	}
+	updateQuotaMetrics(elasticQuotaInfo)
 }
 
 func (c *CapacityScheduling) updatePod(oldObj, newObj interface{}) {
@@ -740,30 +1102,143 @@ func (c *CapacityScheduling) updatePod(oldObj, newObj interface{}) {
 		c.Lock()
 		defer c.Unlock()
 
-		elasticQuotaInfo := c.elasticQuotaInfos[newPod.Namespace]
+		elasticQuotaInfo := c.elasticQuotaInfos.get(newPod.Namespace)
 		if elasticQuotaInfo != nil {
 			err := elasticQuotaInfo.deletePodIfPresent(newPod)
 			if err != nil {
 				klog.ErrorS(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(newPod))
 			}
+			updateQuotaMetrics(elasticQuotaInfo)
+			c.dropDefaultQuotaIfUnused(elasticQuotaInfo)
 		}
 	}
 }
 
 func (c *CapacityScheduling) deletePod(obj interface{}) {
-	pod := obj.(*v1.Pod)
+	pod := podFromDeleteEvent(obj)
+	if pod == nil {
+		return
+	}
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos.get(pod.Namespace)
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.deletePodIfPresent(pod)
 		if err != nil {
 			klog.ErrorS(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(pod))
 		}
+		c.releaseGangIfNoPendingPods(elasticQuotaInfo, pod)
+		updateQuotaMetrics(elasticQuotaInfo)
+		c.dropDefaultQuotaIfUnused(elasticQuotaInfo)
 	}
 }
 
+func (c *CapacityScheduling) deletePendingGangPod(obj interface{}) {
+	pod := podFromDeleteEvent(obj)
+	if pod == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	elasticQuotaInfo := c.elasticQuotaInfos.get(pod.Namespace)
+	if elasticQuotaInfo != nil {
+		c.releaseGangIfNoPendingPods(elasticQuotaInfo, pod)
+		updateQuotaMetrics(elasticQuotaInfo)
+		c.dropDefaultQuotaIfUnused(elasticQuotaInfo)
+	}
+}
+
+func (c *CapacityScheduling) deletePodGroup(obj interface{}) {
+	pg, ok := obj.(*v1alpha1.PodGroup)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if pg, ok = tombstone.Obj.(*v1alpha1.PodGroup); !ok {
+			return
+		}
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	elasticQuotaInfo := c.elasticQuotaInfos.get(pg.Namespace)
+	if elasticQuotaInfo != nil {
+		elasticQuotaInfo.releaseGang(pg.Namespace + "/" + pg.Name)
+		updateQuotaMetrics(elasticQuotaInfo)
+		c.dropDefaultQuotaIfUnused(elasticQuotaInfo)
+	}
+}
+
+// releaseGangIfNoPendingPods releases the quota held for the PodGroup of the deleted pod
+// if none of the PodGroup members is left to schedule, as nothing would consume it anymore.
+// It must be called with the lock held.
+func (c *CapacityScheduling) releaseGangIfNoPendingPods(elasticQuotaInfo *ElasticQuotaInfo, deleted *v1.Pod) {
+	pgName := util.GetPodGroupLabel(deleted)
+	if len(pgName) == 0 {
+		return
+	}
+	pgFullName := util.GetPodGroupFullName(deleted)
+	if _, ok := elasticQuotaInfo.gangReservation(pgFullName); !ok {
+		return
+	}
+	pods, err := c.podLister.Pods(deleted.Namespace).List(labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: pgName}))
+	if err != nil {
+		klog.ErrorS(err, "Failed to list the pods of PodGroup", "podGroup", pgFullName)
+		return
+	}
+	for _, pod := range pods {
+		if pod.UID != deleted.UID && pendingGangPod(pod) && pod.DeletionTimestamp == nil {
+			return
+		}
+	}
+	klog.V(4).InfoS("Releasing the quota held for PodGroup without pending pods", "podGroup", pgFullName)
+	elasticQuotaInfo.releaseGang(pgFullName)
+}
+
+// newElasticQuotaInfo returns an ElasticQuotaInfo accounting the resources tracked by the plugin.
+func (c *CapacityScheduling) newElasticQuotaInfo(namespace string, min, max v1.ResourceList) *ElasticQuotaInfo {
+	elasticQuotaInfo := newElasticQuotaInfoWithBounds(namespace, min, max, nil, c.bounds)
+	elasticQuotaInfo.trackedResources = c.trackedResources
+	return elasticQuotaInfo
+}
+
+// newDefaultElasticQuotaInfo returns the ElasticQuotaInfo of a namespace without an ElasticQuota,
+// or the catch-all quota, which leaves out the excluded namespaces. It must only be called if the plugin has a default quota.
+func (c *CapacityScheduling) newDefaultElasticQuotaInfo(namespace string) *ElasticQuotaInfo {
+	elasticQuotaInfo := c.newElasticQuotaInfo(namespace, c.defaultQuota.Min, c.defaultQuota.Max)
+	elasticQuotaInfo.defaulted = true
+	if namespace == CatchAllQuotaNamespace {
+		elasticQuotaInfo.excludedNamespaces = sets.New(c.defaultQuota.ExcludedNamespaces...)
+	}
+	return elasticQuotaInfo
+}
+
+// dropDefaultQuotaIfUnused removes the default quota of a namespace once it has no pods
+// and holds no quota for PodGroups, so that the namespaces that are gone don't keep adding
+// their min to the sum of min of all quotas. It must be called with the lock held.
+func (c *CapacityScheduling) dropDefaultQuotaIfUnused(elasticQuotaInfo *ElasticQuotaInfo) {
+	if !elasticQuotaInfo.defaulted || elasticQuotaInfo.Namespace == CatchAllQuotaNamespace ||
+		elasticQuotaInfo.pods.Len() != 0 || len(elasticQuotaInfo.gangReserved) != 0 {
+		return
+	}
+	delete(c.elasticQuotaInfos, elasticQuotaInfo.Namespace)
+	deleteQuotaMetrics(elasticQuotaInfo)
+}
+
+// defaultQuotaApplies tells whether the namespace, if it doesn't have an ElasticQuota,
+// is subject to the default quota.
+func (c *CapacityScheduling) defaultQuotaApplies(namespace string) bool {
+	return c.defaultQuota != nil && !slices.Contains(c.defaultQuota.ExcludedNamespaces, namespace)
+}
+
+// podResourceRequest returns the request of the pod in the resources tracked by the plugin.
+func (c *CapacityScheduling) podResourceRequest(pod *v1.Pod) *framework.Resource {
+	return trackedResourceRequest(computePodResourceRequest(pod), c.trackedResources)
+}
+
 // getElasticQuotasSnapshot will return the snapshot of elasticQuotas.
 func (c *CapacityScheduling) snapshotElasticQuota() *ElasticQuotaSnapshotState {
 	c.RLock()
@@ -910,3 +1385,21 @@ func filterPodsWithPDBViolation(podInfos []*framework.PodInfo, pdbs []*policy.Po
 func assignedPod(pod *v1.Pod) bool {
 	return len(pod.Spec.NodeName) != 0
 }
+
+// pendingGangPod selects the members of a PodGroup that are not assigned yet.
+func pendingGangPod(pod *v1.Pod) bool {
+	return !assignedPod(pod) && len(util.GetPodGroupLabel(pod)) != 0
+}
+
+// podFromDeleteEvent returns the pod of a delete event, which may be a tombstone.
+func podFromDeleteEvent(obj interface{}) *v1.Pod {
+	switch t := obj.(type) {
+	case *v1.Pod:
+		return t
+	case cache.DeletedFinalStateUnknown:
+		pod, _ := t.Obj.(*v1.Pod)
+		return pod
+	default:
+		return nil
+	}
+}
@@ -18,6 +18,7 @@ package capacityscheduling
 
 import (
 	"context"
+	"math"
 	"reflect"
 	"sort"
 	"testing"
@@ -30,7 +31,9 @@ import (
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/events"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
@@ -45,6 +48,7 @@ import (
 	imageutils "k8s.io/kubernetes/test/utils/image"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	testutil "sigs.k8s.io/scheduler-plugins/test/util"
 )
@@ -52,9 +56,90 @@ import (
 const ResourceGPU v1.ResourceName = "nvidia.com/gpu"
 
 var (
-	midPriority, highPriority = int32(100), int32(1000)
+	lowPriority, midPriority, highPriority = int32(10), int32(100), int32(1000)
 )
 
+func TestGetArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      apiruntime.Object
+		expected *config.CapacitySchedulingArgs
+		wantErr  bool
+	}{
+		{
+			name: "defaulted args without plugin config",
+			expected: &config.CapacitySchedulingArgs{
+				VictimCostModel: config.VictimCostModel{
+					Type:                        config.VictimCostModelPriority,
+					RuntimeWeight:               1,
+					RestartsWeight:              1,
+					CheckpointWeight:            1,
+					QoSWeight:                   1,
+					RuntimeNormalizationSeconds: 3600,
+				},
+				EnableBorrowing:           true,
+				AllowCrossQuotaPreemption: true,
+				UpperBoundOfMax:           math.MaxInt64,
+			},
+		},
+		{
+			name:     "configured args",
+			obj:      &config.CapacitySchedulingArgs{EnableBorrowing: true},
+			expected: &config.CapacitySchedulingArgs{EnableBorrowing: true},
+		},
+		{
+			name:    "args of another plugin",
+			obj:     &config.CoschedulingArgs{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := getArgs(tt.obj)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := gocmp.Diff(tt.expected, args); diff != "" {
+				t.Errorf("unexpected args (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewFromArgsWithoutPluginConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cs := clientsetfake.NewSimpleClientset()
+	fwk, err := tf.NewFramework(
+		ctx,
+		[]tf.RegisterPluginFunc{
+			tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		},
+		"",
+		frameworkruntime.WithInformerFactory(informers.NewSharedInformerFactory(cs, 0)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The framework passes nil args to the plugins without a pluginConfig entry.
+	c, err := newFromArgs(nil, fwk)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.borrowingDisabled || c.crossQuotaPreemptionDisabled {
+		t.Errorf("expected borrowing and cross quota preemption to be enabled by default")
+	}
+	if c.victimCostModel != nil || c.trackedResources != nil || c.defaultQuota != nil {
+		t.Errorf("expected no victim cost model, tracked resources nor default quota by default")
+	}
+	if c.bounds.upperOfMax() != UpperBoundOfMax || c.bounds.lowerOfMin() != LowerBoundOfMin {
+		t.Errorf("expected the default bounds, got %+v", c.bounds)
+	}
+}
+
 func TestPreFilter(t *testing.T) {
 	type podInfo struct {
 		podName      string
@@ -182,7 +267,461 @@ func TestPreFilter(t *testing.T) {
 	}
 }
 
+func TestPodGroupQuotaAdmission(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := testutil.NewFakeClient(
+		testutil.MakePodGroup().Namespace("ns1").Name("pg1").MinMember(2).Obj(),
+		testutil.MakePodGroup().Namespace("ns1").Name("pg2").MinMember(3).Obj(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+	}
+	fwk, err := tf.NewFramework(
+		ctx, registeredPlugins, "",
+		frameworkruntime.WithPodNominator(testutil.NewPodNominator(nil)),
+		frameworkruntime.WithSnapshotSharedLister(testutil.NewFakeSharedLister(make([]*v1.Pod, 0), make([]*v1.Node, 0))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := &CapacityScheduling{
+		fh:             fwk,
+		podGroupReader: client,
+		elasticQuotaInfos: map[string]*ElasticQuotaInfo{
+			"ns1": newElasticQuotaInfo("ns1", makeResourceList(0, 2000), makeResourceList(0, 2000), nil),
+		},
+	}
+
+	withPodGroup := func(pod *v1.Pod, pgName string) *v1.Pod {
+		pod.Labels = map[string]string{v1alpha1.PodGroupLabel: pgName}
+		return pod
+	}
+	pg1p1 := withPodGroup(makePod("pg1-p1", "ns1", 800, 0, 0, 0, "pg1-p1", ""), "pg1")
+	pg1p2 := withPodGroup(makePod("pg1-p2", "ns1", 800, 0, 0, 0, "pg1-p2", ""), "pg1")
+	pg2p1 := withPodGroup(makePod("pg2-p1", "ns1", 800, 0, 0, 0, "pg2-p1", ""), "pg2")
+	single := makePod("single", "ns1", 500, 0, 0, 0, "single", "")
+
+	// MinMember(3) * 800 doesn't fit into Max even though a single member does.
+	if _, got := cs.PreFilter(ctx, framework.NewCycleState(), pg2p1); got.Code() != framework.Unschedulable {
+		t.Errorf("expected pg2 to be rejected as a whole, got %v: %v", got.Code(), got.Message())
+	}
+
+	state := framework.NewCycleState()
+	if _, got := cs.PreFilter(ctx, state, pg1p1); !got.IsSuccess() {
+		t.Fatalf("expected pg1 to be admitted, got %v: %v", got.Code(), got.Message())
+	}
+	if got := cs.Reserve(ctx, state, pg1p1, "node-a"); !got.IsSuccess() {
+		t.Fatalf("unexpected Reserve status: %v", got.Message())
+	}
+	eq := cs.elasticQuotaInfos["ns1"]
+	if eq.Used.Memory != 800 || eq.gangReserved["ns1/pg1"].Memory != 800 {
+		t.Errorf("expected 800 used and 800 reserved for pg1, got %v used and %v reserved", eq.Used.Memory, eq.gangReserved["ns1/pg1"])
+	}
+
+	// The quota held for the rest of pg1 can't be taken by other pods.
+	if _, got := cs.PreFilter(ctx, framework.NewCycleState(), single); got.Code() != framework.Unschedulable {
+		t.Errorf("expected pod to be rejected by the quota held for pg1, got %v: %v", got.Code(), got.Message())
+	}
+	// But the remaining member of pg1 is covered by it.
+	if _, got := cs.PreFilter(ctx, framework.NewCycleState(), pg1p2); !got.IsSuccess() {
+		t.Errorf("expected remaining member of pg1 to be admitted, got %v: %v", got.Code(), got.Message())
+	}
+
+	// Rejecting a member releases the quota held for the whole PodGroup.
+	cs.Unreserve(ctx, state, pg1p1, "node-a")
+	if eq.Used.Memory != 0 || len(eq.gangReserved) != 0 {
+		t.Errorf("expected quota to be released, got %v used and %v reserved", eq.Used.Memory, eq.gangReserved)
+	}
+	if _, got := cs.PreFilter(ctx, framework.NewCycleState(), single); !got.IsSuccess() {
+		t.Errorf("expected pod to be admitted once pg1 is released, got %v: %v", got.Code(), got.Message())
+	}
+
+	// The members already accounted in the quota are taken off the quota the PodGroup needs.
+	pg2p0 := withPodGroup(makePod("pg2-p0", "ns1", 800, 0, 0, 0, "pg2-p0", "node-a"), "pg2")
+	if err := eq.addPodIfNotPresent(pg2p0); err != nil {
+		t.Fatal(err)
+	}
+	state = framework.NewCycleState()
+	cs.PreFilter(ctx, state, pg2p1)
+	if preFilterState, err := getPreFilterState(state); err != nil || preFilterState.gangReq == nil || preFilterState.gangReq.Memory != 1600 {
+		t.Errorf("expected 1600 requested for the rest of pg2, got %v", preFilterState)
+	}
+	if err := eq.deletePodIfPresent(pg2p0); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := eq.gangMembersOf("ns1/pg2"); count != 0 {
+		t.Errorf("expected no member of pg2 left, got %v", count)
+	}
+
+	// Until the PodGroups are synced, or without the PodGroup CRD, the members are admitted on their own.
+	cs.podGroupSynced = func() bool { return false }
+	if _, got := cs.PreFilter(ctx, framework.NewCycleState(), pg2p1); !got.IsSuccess() {
+		t.Errorf("expected pg2 member to be admitted on its own before the sync, got %v: %v", got.Code(), got.Message())
+	}
+	cs.podGroupReader, cs.podGroupSynced = nil, nil
+	if _, got := cs.PreFilter(ctx, framework.NewCycleState(), pg2p1); !got.IsSuccess() {
+		t.Errorf("expected pg2 member to be admitted on its own without PodGroups, got %v: %v", got.Code(), got.Message())
+	}
+}
+
+func TestPreFilterWithArgs(t *testing.T) {
+	tests := []struct {
+		name              string
+		borrowingDisabled bool
+		trackedResources  sets.Set[v1.ResourceName]
+		defaultQuota      *config.DefaultQuota
+		elasticQuotas     map[string]*ElasticQuotaInfo
+		pod               *v1.Pod
+		expected          framework.Code
+	}{
+		{
+			name: "borrowing enabled",
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": newElasticQuotaInfo("ns1", makeResourceList(0, 1000), makeResourceList(0, 2000), makeResourceList(0, 800)),
+				"ns2": newElasticQuotaInfo("ns2", makeResourceList(0, 1000), makeResourceList(0, 2000), nil),
+			},
+			pod:      makePod("p1", "ns1", 500, 0, 0, 0, "p1", ""),
+			expected: framework.Success,
+		},
+		{
+			name:              "borrowing disabled",
+			borrowingDisabled: true,
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": newElasticQuotaInfo("ns1", makeResourceList(0, 1000), makeResourceList(0, 2000), makeResourceList(0, 800)),
+				"ns2": newElasticQuotaInfo("ns2", makeResourceList(0, 1000), makeResourceList(0, 2000), nil),
+			},
+			pod:      makePod("p1", "ns1", 500, 0, 0, 0, "p1", ""),
+			expected: framework.Unschedulable,
+		},
+		{
+			name:             "untracked resources are not accounted",
+			trackedResources: sets.New(v1.ResourceCPU),
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": newElasticQuotaInfo("ns1", makeResourceList(1000, 1000), makeResourceList(2000, 1000), nil),
+			},
+			pod:      makePod("p1", "ns1", 1500, 500, 0, 0, "p1", ""),
+			expected: framework.Success,
+		},
+		{
+			name: "without elasticQuotaInfo and default quota",
+			defaultQuota: &config.DefaultQuota{
+				Min: makeResourceList(0, 1000),
+				Max: makeResourceList(0, 1000),
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{},
+			pod:           makePod("p1", "ns1", 1500, 0, 0, 0, "p1", ""),
+			expected:      framework.Unschedulable,
+		},
+		{
+			name: "catch-all quota borrows unused min",
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1":                  newElasticQuotaInfo("ns1", makeResourceList(0, 1000), makeResourceList(0, 2000), nil),
+				CatchAllQuotaNamespace: newElasticQuotaInfo(CatchAllQuotaNamespace, makeResourceList(0, 0), makeResourceList(0, 2000), makeResourceList(0, 200)),
+			},
+			pod:      makePod("p1", "ns2", 500, 0, 0, 0, "p1", ""),
+			expected: framework.Success,
+		},
+		{
+			name: "catch-all quota over max",
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1":                  newElasticQuotaInfo("ns1", makeResourceList(0, 1000), makeResourceList(0, 2000), nil),
+				CatchAllQuotaNamespace: newElasticQuotaInfo(CatchAllQuotaNamespace, makeResourceList(0, 0), makeResourceList(0, 600), makeResourceList(0, 200)),
+			},
+			pod:      makePod("p1", "ns2", 500, 0, 0, 0, "p1", ""),
+			expected: framework.Unschedulable,
+		},
+		{
+			name: "catch-all quota over sum of min",
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1":                  newElasticQuotaInfo("ns1", makeResourceList(0, 1000), makeResourceList(0, 2000), makeResourceList(0, 500)),
+				CatchAllQuotaNamespace: newElasticQuotaInfo(CatchAllQuotaNamespace, makeResourceList(0, 0), makeResourceList(0, 2000), makeResourceList(0, 200)),
+			},
+			pod:      makePod("p1", "ns2", 500, 0, 0, 0, "p1", ""),
+			expected: framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			registeredPlugins := []tf.RegisterPluginFunc{
+				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			}
+			fwk, err := tf.NewFramework(
+				ctx, registeredPlugins, "",
+				frameworkruntime.WithPodNominator(testutil.NewPodNominator(nil)),
+				frameworkruntime.WithSnapshotSharedLister(testutil.NewFakeSharedLister(make([]*v1.Pod, 0), make([]*v1.Node, 0))),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cs := &CapacityScheduling{
+				elasticQuotaInfos: tt.elasticQuotas,
+				fh:                fwk,
+				borrowingDisabled: tt.borrowingDisabled,
+				trackedResources:  tt.trackedResources,
+				defaultQuota:      tt.defaultQuota,
+			}
+			for _, eq := range cs.elasticQuotaInfos {
+				eq.trackedResources = tt.trackedResources
+			}
+
+			if _, got := cs.PreFilter(ctx, framework.NewCycleState(), tt.pod); got.Code() != tt.expected {
+				t.Errorf("expected %v, got %v : %v", tt.expected, got.Code(), got.Message())
+			}
+		})
+	}
+}
+
+func TestDefaultQuota(t *testing.T) {
+	RegisterMetrics()
+
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: NewElasticQuotaInfos(),
+		defaultQuota: &config.DefaultQuota{
+			Min:                makeResourceList(0, 1000),
+			Max:                makeResourceList(0, 2000),
+			ExcludedNamespaces: []string{"kube-system"},
+		},
+	}
+
+	// The pods of the excluded namespaces aren't accounted, and their namespaces
+	// don't add the min of the default quota to the sum of min of all quotas.
+	systemPod := makePod("p0", "kube-system", 500, 0, 0, 0, "p0", "node-a")
+	state := framework.NewCycleState()
+	if _, got := cs.PreFilter(context.TODO(), state, systemPod); !got.IsSuccess() {
+		t.Fatalf("unexpected PreFilter status: %v", got.Message())
+	}
+	if got := cs.Reserve(context.TODO(), state, systemPod, "node-a"); !got.IsSuccess() {
+		t.Fatalf("unexpected Reserve status: %v", got.Message())
+	}
+	if eq, ok := cs.elasticQuotaInfos["kube-system"]; ok {
+		t.Fatalf("expected pod of an excluded namespace not to be accounted, got %#v", eq)
+	}
+
+	pod := makePod("p1", "ns1", 500, 0, 0, 0, "p1", "node-a")
+	if got := cs.Reserve(context.TODO(), framework.NewCycleState(), pod, "node-a"); !got.IsSuccess() {
+		t.Fatalf("unexpected Reserve status: %v", got.Message())
+	}
+	eq := cs.elasticQuotaInfos["ns1"]
+	if eq == nil || !eq.defaulted || eq.Used.Memory != 500 || eq.Max.Memory != 2000 {
+		t.Fatalf("expected pod to be accounted in the default quota, got %#v", eq)
+	}
+
+	// An ElasticQuota created afterwards replaces the default quota and keeps its usage.
+	cs.addElasticQuota(makeEQ("ns1", "t1-eq1", makeResourceList(0, 4000), makeResourceList(0, 3000)))
+	eq = cs.elasticQuotaInfos["ns1"]
+	if eq.defaulted || eq.Used.Memory != 500 || eq.Max.Memory != 4000 {
+		t.Errorf("expected ElasticQuota to replace the default quota, got %#v", eq)
+	}
+
+	// Deleting the ElasticQuota falls back to the default quota.
+	cs.deleteElasticQuota(makeEQ("ns1", "t1-eq1", makeResourceList(0, 4000), makeResourceList(0, 3000)))
+	eq = cs.elasticQuotaInfos["ns1"]
+	if eq == nil || !eq.defaulted || eq.Used.Memory != 500 || eq.Max.Memory != 2000 {
+		t.Errorf("expected namespace to fall back to the default quota, got %#v", eq)
+	}
+
+	// The default quota is dropped, with its metrics, once the namespace has no pods left.
+	cs.Unreserve(context.TODO(), framework.NewCycleState(), pod, "node-a")
+	if eq, ok := cs.elasticQuotaInfos["ns1"]; ok {
+		t.Errorf("expected the unused default quota to be dropped, got %#v", eq)
+	}
+	if elasticQuotaMin.Delete(map[string]string{"quota": "ns1", "resource": string(v1.ResourceMemory)}) {
+		t.Errorf("expected the metrics of the dropped default quota not to be reported")
+	}
+
+	// The excluded namespaces are left out of the catch-all quota too.
+	cs = &CapacityScheduling{
+		elasticQuotaInfos: NewElasticQuotaInfos(),
+		defaultQuota: &config.DefaultQuota{
+			Min:                makeResourceList(0, 1000),
+			Max:                makeResourceList(0, 2000),
+			Shared:             true,
+			ExcludedNamespaces: []string{"kube-system"},
+		},
+	}
+	cs.elasticQuotaInfos[CatchAllQuotaNamespace] = cs.newDefaultElasticQuotaInfo(CatchAllQuotaNamespace)
+	state = framework.NewCycleState()
+	if _, got := cs.PreFilter(context.TODO(), state, systemPod); !got.IsSuccess() {
+		t.Fatalf("unexpected PreFilter status: %v", got.Message())
+	}
+	if got := cs.Reserve(context.TODO(), state, systemPod, "node-a"); !got.IsSuccess() {
+		t.Fatalf("unexpected Reserve status: %v", got.Message())
+	}
+	if got := cs.Reserve(context.TODO(), framework.NewCycleState(), pod, "node-a"); !got.IsSuccess() {
+		t.Fatalf("unexpected Reserve status: %v", got.Message())
+	}
+	if eq := cs.snapshotElasticQuota().elasticQuotaInfos.get("kube-system"); eq != nil {
+		t.Errorf("expected an excluded namespace not to be subject to the catch-all quota, got %#v", eq)
+	}
+	if catchAll := cs.elasticQuotaInfos[CatchAllQuotaNamespace]; catchAll.Used.Memory != 500 || catchAll.pods.Has("p0") {
+		t.Errorf("expected only the pod of ns1 to be accounted in the catch-all quota, got %#v", catchAll)
+	}
+}
+
+func TestElasticQuotaChangesKeepGangReservation(t *testing.T) {
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: NewElasticQuotaInfos(),
+		defaultQuota: &config.DefaultQuota{
+			Min: makeResourceList(0, 1000),
+			Max: makeResourceList(0, 2000),
+		},
+	}
+	expectReserved := func(step string) {
+		t.Helper()
+		eq := cs.elasticQuotaInfos["ns1"]
+		if reserved, ok := eq.gangReservation("ns1/pg1"); !ok || reserved.Memory != 800 {
+			t.Errorf("%s: expected 800 held for pg1, got %v", step, eq.gangReserved)
+		}
+		if eq.Used.Memory != 800 {
+			t.Errorf("%s: expected 800 used, got %v", step, eq.Used.Memory)
+		}
+	}
+
+	pod := makePod("pg1-p1", "ns1", 800, 0, 0, 0, "pg1-p1", "node-a")
+	pod.Labels = map[string]string{v1alpha1.PodGroupLabel: "pg1"}
+	state := framework.NewCycleState()
+	state.Write(preFilterStateKey, &PreFilterState{
+		pgFullName: "ns1/pg1",
+		gangReq:    &framework.Resource{Memory: 1600},
+	})
+	if got := cs.Reserve(context.TODO(), state, pod, "node-a"); !got.IsSuccess() {
+		t.Fatalf("unexpected Reserve status: %v", got.Message())
+	}
+	expectReserved("default quota")
+
+	eq := makeEQ("ns1", "t1-eq1", makeResourceList(0, 4000), makeResourceList(0, 3000))
+	cs.addElasticQuota(eq)
+	expectReserved("ElasticQuota replacing the default quota")
+
+	// A status-only update must not drop the reservation either.
+	updated := eq.DeepCopy()
+	updated.Status.Used = makeResourceList(0, 800)
+	cs.updateElasticQuota(eq, updated)
+	expectReserved("ElasticQuota update")
+
+	cs.deleteElasticQuota(updated)
+	expectReserved("fallback to the default quota")
+}
+
+func TestGangReservationRelease(t *testing.T) {
+	withPodGroup := func(pod *v1.Pod, pgName string) *v1.Pod {
+		pod.Labels = map[string]string{v1alpha1.PodGroupLabel: pgName}
+		return pod
+	}
+	pg2p1 := withPodGroup(makePod("pg2-p1", "ns1", 800, 0, 0, 0, "pg2-p1", ""), "pg2")
+	pg2p2 := withPodGroup(makePod("pg2-p2", "ns1", 800, 0, 0, 0, "pg2-p2", ""), "pg2")
+	pg3p1 := withPodGroup(makePod("pg3-p1", "ns1", 800, 0, 0, 0, "pg3-p1", "node-a"), "pg3")
+
+	cs := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	for _, pod := range []*v1.Pod{pg2p1, pg2p2, pg3p1} {
+		podInformer.Informer().GetStore().Add(pod)
+	}
+
+	eq := newElasticQuotaInfo("ns1", makeResourceList(0, 4000), makeResourceList(0, 8000), nil)
+	for _, pgName := range []string{"pg1", "pg2"} {
+		eq.reserveGang("ns1/"+pgName, framework.Resource{Memory: 800})
+	}
+	if err := eq.addPodIfNotPresent(pg3p1); err != nil {
+		t.Fatal(err)
+	}
+	eq.reserveGang("ns1/pg3", framework.Resource{Memory: 800})
+	c := &CapacityScheduling{
+		elasticQuotaInfos: ElasticQuotaInfos{"ns1": eq},
+		podLister:         podInformer.Lister(),
+	}
+	expectReserved := func(pgName string, reserved bool) {
+		t.Helper()
+		if _, ok := eq.gangReservation("ns1/" + pgName); ok != reserved {
+			t.Errorf("expected quota held for %s: %v, got %v", pgName, reserved, eq.gangReserved)
+		}
+	}
+
+	// Deleting the PodGroup releases the quota held for it.
+	c.deletePodGroup(testutil.MakePodGroup().Namespace("ns1").Name("pg1").MinMember(2).Obj())
+	expectReserved("pg1", false)
+	expectReserved("pg2", true)
+
+	// The quota is held as long as a member is pending.
+	podInformer.Informer().GetStore().Delete(pg2p1)
+	c.deletePendingGangPod(pg2p1)
+	expectReserved("pg2", true)
+	podInformer.Informer().GetStore().Delete(pg2p2)
+	c.deletePendingGangPod(cache.DeletedFinalStateUnknown{Key: "ns1/pg2-p2", Obj: pg2p2})
+	expectReserved("pg2", false)
+
+	// Deleting an assigned member without pending members releases the quota too.
+	podInformer.Informer().GetStore().Delete(pg3p1)
+	c.deletePod(pg3p1)
+	expectReserved("pg3", false)
+	if eq.Used.Memory != 0 {
+		t.Errorf("expected no quota used, got %v", eq.Used.Memory)
+	}
+}
+
+func TestCatchAllQuota(t *testing.T) {
+	pods := []*v1.Pod{
+		makePod("p1", "free1", 500, 0, 0, 0, "p1", "node-a"),
+		makePod("p2", "free2", 500, 0, 0, 0, "p2", "node-a"),
+	}
+	cs := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	for _, pod := range pods {
+		podInformer.Informer().GetStore().Add(pod)
+	}
+
+	c := &CapacityScheduling{
+		elasticQuotaInfos: NewElasticQuotaInfos(),
+		podLister:         podInformer.Lister(),
+		defaultQuota: &config.DefaultQuota{
+			Min:    makeResourceList(0, 0),
+			Max:    makeResourceList(0, 2000),
+			Shared: true,
+		},
+	}
+	c.elasticQuotaInfos[CatchAllQuotaNamespace] = c.newDefaultElasticQuotaInfo(CatchAllQuotaNamespace)
+	c.addElasticQuota(makeEQ("ns1", "t1-eq1", makeResourceList(0, 4000), makeResourceList(0, 1000)))
+
+	for _, pod := range pods {
+		if got := c.Reserve(context.TODO(), framework.NewCycleState(), pod, "node-a"); !got.IsSuccess() {
+			t.Fatalf("unexpected Reserve status: %v", got.Message())
+		}
+	}
+	catchAll := c.elasticQuotaInfos[CatchAllQuotaNamespace]
+	if catchAll.Used.Memory != 1000 || len(c.elasticQuotaInfos) != 2 {
+		t.Fatalf("expected pods of both namespaces to be accounted in the catch-all quota, got %#v", catchAll)
+	}
+
+	// An ElasticQuota created afterwards takes the pods of its namespace out of the catch-all quota.
+	c.addElasticQuota(makeEQ("free1", "t1-eq2", makeResourceList(0, 4000), makeResourceList(0, 1000)))
+	if eq := c.elasticQuotaInfos["free1"]; eq.Used.Memory != 500 || catchAll.Used.Memory != 500 {
+		t.Errorf("expected pod to move to the new quota, got %#v and %#v", eq, catchAll)
+	}
+
+	// Deleting the ElasticQuota moves them back.
+	c.deleteElasticQuota(makeEQ("free1", "t1-eq2", makeResourceList(0, 4000), makeResourceList(0, 1000)))
+	if _, ok := c.elasticQuotaInfos["free1"]; ok || catchAll.Used.Memory != 1000 {
+		t.Errorf("expected pod to move back to the catch-all quota, got %#v", catchAll)
+	}
+}
+
 func TestPostFilter(t *testing.T) {
+	RegisterMetrics()
 	res := map[v1.ResourceName]string{v1.ResourceMemory: "150"}
 	tests := []struct {
 		name                  string
@@ -193,6 +732,8 @@ func TestPostFilter(t *testing.T) {
 		elasticQuotas         map[string]*ElasticQuotaInfo
 		wantResult            *framework.PostFilterResult
 		wantStatus            *framework.Status
+		// wantVictimLabels are the quota of the preemptor and the quota of the victim.
+		wantVictimLabels []string
 	}{
 		{
 			name: "in-namespace preemption",
@@ -234,8 +775,9 @@ func TestPostFilter(t *testing.T) {
 					},
 				},
 			},
-			wantResult: framework.NewPostFilterResultWithNominatedNode("node-a"),
-			wantStatus: framework.NewStatus(framework.Success),
+			wantResult:       framework.NewPostFilterResultWithNominatedNode("node-a"),
+			wantStatus:       framework.NewStatus(framework.Success),
+			wantVictimLabels: []string{"ns1", "ns1"},
 		},
 		{
 			name: "cross-namespace preemption",
@@ -277,8 +819,9 @@ func TestPostFilter(t *testing.T) {
 					},
 				},
 			},
-			wantResult: framework.NewPostFilterResultWithNominatedNode("node-a"),
-			wantStatus: framework.NewStatus(framework.Success),
+			wantResult:       framework.NewPostFilterResultWithNominatedNode("node-a"),
+			wantStatus:       framework.NewStatus(framework.Success),
+			wantVictimLabels: []string{"ns1", "ns2"},
 		},
 		{
 			name: "without elasticQuotas",
@@ -294,9 +837,10 @@ func TestPostFilter(t *testing.T) {
 			filteredNodesStatuses: framework.NodeToStatusMap{
 				"node-a": framework.NewStatus(framework.Unschedulable),
 			},
-			elasticQuotas: map[string]*ElasticQuotaInfo{},
-			wantResult:    framework.NewPostFilterResultWithNominatedNode("node-a"),
-			wantStatus:    framework.NewStatus(framework.Success),
+			elasticQuotas:    map[string]*ElasticQuotaInfo{},
+			wantResult:       framework.NewPostFilterResultWithNominatedNode("node-a"),
+			wantStatus:       framework.NewStatus(framework.Success),
+			wantVictimLabels: []string{"", ""},
 		},
 	}
 
@@ -356,6 +900,7 @@ func TestPostFilter(t *testing.T) {
 				podLister:         informerFactory.Core().V1().Pods().Lister(),
 				pdbLister:         getPDBLister(informerFactory),
 			}
+			victimsBefore, _ := metricstestutil.GetCounterMetricValue(preemptionVictims.WithLabelValues(tt.wantVictimLabels...))
 			gotResult, gotStatus := c.PostFilter(ctx, state, tt.pod, tt.filteredNodesStatuses)
 			if diff := gocmp.Diff(tt.wantStatus, gotStatus); diff != "" {
 				t.Errorf("Unexpected status (-want, +got):\n%s", diff)
@@ -363,6 +908,10 @@ func TestPostFilter(t *testing.T) {
 			if diff := gocmp.Diff(tt.wantResult, gotResult); diff != "" {
 				t.Errorf("Unexpected postFilterResult (-want, +got):\n%s", diff)
 			}
+			victimsAfter, _ := metricstestutil.GetCounterMetricValue(preemptionVictims.WithLabelValues(tt.wantVictimLabels...))
+			if victimsAfter-victimsBefore != 1 {
+				t.Errorf("Expected 1 victim reported for %v, got %v", tt.wantVictimLabels, victimsAfter-victimsBefore)
+			}
 		})
 	}
 }
@@ -715,6 +1264,58 @@ func TestDryRunPreemption(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "no preemption across the namespaces of the catch-all quota",
+			pod:  makePod("t1-p", "free1", 50, 0, 0, highPriority, "t1-p", ""),
+			pods: []*v1.Pod{
+				makePod("t1-p1", "free1", 50, 0, 0, midPriority, "t1-p1", "node-a"),
+				makePod("t1-p2", "free2", 50, 0, 0, lowPriority, "t1-p2", "node-a"),
+				makePod("t1-p3", "free2", 50, 0, 0, lowPriority, "t1-p3", "node-a"),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node-a").Capacity(res).Obj(),
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				CatchAllQuotaNamespace: {
+					Namespace: CatchAllQuotaNamespace,
+					Max: &framework.Resource{
+						Memory: 200,
+					},
+					Min: &framework.Resource{
+						Memory: 50,
+					},
+					Used: &framework.Resource{
+						Memory: 150,
+					},
+				},
+				"ns1": {
+					Namespace: "ns1",
+					Max: &framework.Resource{
+						Memory: 1000,
+					},
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Used: &framework.Resource{
+						Memory: 0,
+					},
+				},
+			},
+			nodesStatuses: framework.NodeToStatusMap{
+				"node-a": framework.NewStatus(framework.Unschedulable),
+			},
+			want: []preemption.Candidate{
+				&candidate{
+					victims: &extenderv1.Victims{
+						Pods: []*v1.Pod{
+							makePod("t1-p1", "free1", 50, 0, 0, midPriority, "t1-p1", "node-a"),
+						},
+						NumPDBViolations: 0,
+					},
+					name: "node-a",
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -791,10 +1392,10 @@ func TestDryRunPreemption(t *testing.T) {
 				t.Fatalf("Unexpected candidate length: want %v, but bot %v", len(tt.want), len(got))
 			}
 			for i, c := range got {
-				if diff := gocmp.Diff(c.Victims(), got[i].Victims()); diff != "" {
+				if diff := gocmp.Diff(tt.want[i].Victims(), c.Victims()); diff != "" {
 					t.Errorf("Unexpected victims at index %v (-want, +got): %s", i, diff)
 				}
-				if diff := gocmp.Diff(c.Name(), got[i].Name()); diff != "" {
+				if diff := gocmp.Diff(tt.want[i].Name(), c.Name()); diff != "" {
 					t.Errorf("Unexpected victims at index %v (-want, +got): %s", i, diff)
 				}
 			}
@@ -27,10 +27,36 @@ import (
 )
 
 const (
+	// UpperBoundOfMax and LowerBoundOfMin are the default bounds of the resources a quota doesn't set.
 	UpperBoundOfMax = math.MaxInt64
 	LowerBoundOfMin = 0
+
+	// CatchAllQuotaNamespace is the key of the quota shared by all the namespaces
+	// without an ElasticQuota, it is not a valid namespace name.
+	CatchAllQuotaNamespace = "*"
 )
 
+// quotaBounds are the max and min of the resources a quota doesn't set.
+// A nil quotaBounds stands for UpperBoundOfMax and LowerBoundOfMin.
+type quotaBounds struct {
+	upperBoundOfMax int64
+	lowerBoundOfMin int64
+}
+
+func (b *quotaBounds) upperOfMax() int64 {
+	if b == nil {
+		return UpperBoundOfMax
+	}
+	return b.upperBoundOfMax
+}
+
+func (b *quotaBounds) lowerOfMin() int64 {
+	if b == nil {
+		return LowerBoundOfMin
+	}
+	return b.lowerBoundOfMin
+}
+
 type ElasticQuotaInfos map[string]*ElasticQuotaInfo
 
 func NewElasticQuotaInfos() ElasticQuotaInfos {
@@ -45,17 +71,33 @@ func (e ElasticQuotaInfos) clone() ElasticQuotaInfos {
 	return elasticQuotas
 }
 
+// get returns the quota of the namespace, or the catch-all quota if the namespace
+// doesn't have an ElasticQuota and isn't excluded from the default quota.
+// It returns nil if neither applies.
+func (e ElasticQuotaInfos) get(namespace string) *ElasticQuotaInfo {
+	if elasticQuotaInfo, ok := e[namespace]; ok {
+		return elasticQuotaInfo
+	}
+	if catchAll, ok := e[CatchAllQuotaNamespace]; ok && !catchAll.excludedNamespaces.Has(namespace) {
+		return catchAll
+	}
+	return nil
+}
+
 func (e ElasticQuotaInfos) aggregatedUsedOverMinWith(podRequest framework.Resource) bool {
 	used := framework.NewResource(nil)
 	min := framework.NewResource(nil)
+	// all the quotas share the bounds of the plugin
+	var bounds *quotaBounds
 
 	for _, elasticQuotaInfo := range e {
-		used.Add(util.ResourceList(elasticQuotaInfo.Used))
+		used.Add(util.ResourceList(elasticQuotaInfo.usedWithGangReserved()))
 		min.Add(util.ResourceList(elasticQuotaInfo.Min))
+		bounds = elasticQuotaInfo.bounds
 	}
 
 	used.Add(util.ResourceList(&podRequest))
-	return cmp(used, min, LowerBoundOfMin)
+	return cmp(used, min, bounds.lowerOfMin())
 }
 
 // ElasticQuotaInfo is a wrapper to a ElasticQuota with information.
@@ -66,14 +108,39 @@ type ElasticQuotaInfo struct {
 	Min       *framework.Resource
 	Max       *framework.Resource
 	Used      *framework.Resource
+	// gangReserved is the quota held on behalf of PodGroups that were admitted
+	// as a whole but whose members have not all been reserved yet, keyed by the
+	// full name of the PodGroup.
+	gangReserved map[string]*framework.Resource
+	// gangMembers are the accounted pods of each PodGroup, keyed by the full name of the PodGroup.
+	gangMembers map[string]*gangMembers
+	// trackedResources are the resources accounted against the quota, all of them if nil.
+	trackedResources sets.Set[v1.ResourceName]
+	// bounds are the max and min of the resources the quota doesn't set.
+	bounds *quotaBounds
+	// defaulted is set if the quota isn't backed by an ElasticQuota
+	// but created from the default quota of the plugin args.
+	defaulted bool
+	// excludedNamespaces are the namespaces the catch-all quota doesn't apply to.
+	excludedNamespaces sets.Set[string]
+}
+
+// gangMembers are the number and the total request of the accounted pods of a PodGroup.
+type gangMembers struct {
+	count   int32
+	request *framework.Resource
 }
 
 func newElasticQuotaInfo(namespace string, min, max, used v1.ResourceList) *ElasticQuotaInfo {
+	return newElasticQuotaInfoWithBounds(namespace, min, max, used, nil)
+}
+
+func newElasticQuotaInfoWithBounds(namespace string, min, max, used v1.ResourceList, bounds *quotaBounds) *ElasticQuotaInfo {
 	if min == nil {
-		min = makeResourceListForBound(LowerBoundOfMin)
+		min = makeResourceListForBound(bounds.lowerOfMin())
 	}
 	if max == nil {
-		max = makeResourceListForBound(UpperBoundOfMax)
+		max = makeResourceListForBound(bounds.upperOfMax())
 	}
 
 	elasticQuotaInfo := &ElasticQuotaInfo{
@@ -82,6 +149,7 @@ func newElasticQuotaInfo(namespace string, min, max, used v1.ResourceList) *Elas
 		Min:       framework.NewResource(min),
 		Max:       framework.NewResource(max),
 		Used:      framework.NewResource(used),
+		bounds:    bounds,
 	}
 	return elasticQuotaInfo
 }
@@ -111,7 +179,7 @@ func (e *ElasticQuotaInfo) usedOverMinWith(podRequest *framework.Resource) bool
 	if e.Min == nil {
 		return true
 	}
-	return cmp2(podRequest, e.Used, e.Min, LowerBoundOfMin)
+	return cmp2(podRequest, e.usedWithGangReserved(), e.Min, e.bounds.lowerOfMin())
 }
 
 func (e *ElasticQuotaInfo) usedOverMaxWith(podRequest *framework.Resource) bool {
@@ -119,7 +187,7 @@ func (e *ElasticQuotaInfo) usedOverMaxWith(podRequest *framework.Resource) bool
 	if e.Max == nil {
 		return false
 	}
-	return cmp2(podRequest, e.Used, e.Max, UpperBoundOfMax)
+	return cmp2(podRequest, e.usedWithGangReserved(), e.Max, e.bounds.upperOfMax())
 }
 
 func (e *ElasticQuotaInfo) usedOverMin() bool {
@@ -127,13 +195,116 @@ func (e *ElasticQuotaInfo) usedOverMin() bool {
 	if e.Min == nil {
 		return true
 	}
-	return cmp(e.Used, e.Min, LowerBoundOfMin)
+	return cmp(e.usedWithGangReserved(), e.Min, e.bounds.lowerOfMin())
+}
+
+// usedWithGangReserved returns the used resources plus the quota that is still
+// held for PodGroups whose members are being scheduled.
+func (e *ElasticQuotaInfo) usedWithGangReserved() *framework.Resource {
+	if len(e.gangReserved) == 0 {
+		return e.Used
+	}
+	used := e.Used.Clone()
+	for _, reserved := range e.gangReserved {
+		used.Add(util.ResourceList(reserved))
+	}
+	return used
+}
+
+// reserveGang holds the given request for the PodGroup until its members
+// are reserved one by one or the PodGroup is released.
+func (e *ElasticQuotaInfo) reserveGang(pgFullName string, request framework.Resource) {
+	if e.gangReserved == nil {
+		e.gangReserved = make(map[string]*framework.Resource)
+	}
+	e.gangReserved[pgFullName] = request.Clone()
+}
+
+// releaseGang drops the quota that is still held for the PodGroup.
+func (e *ElasticQuotaInfo) releaseGang(pgFullName string) {
+	delete(e.gangReserved, pgFullName)
+}
+
+func (e *ElasticQuotaInfo) gangReservation(pgFullName string) (*framework.Resource, bool) {
+	reserved, ok := e.gangReserved[pgFullName]
+	return reserved, ok
+}
+
+// gangMembersOf returns the number and the total request of the accounted pods of the PodGroup.
+func (e *ElasticQuotaInfo) gangMembersOf(pgFullName string) (int32, *framework.Resource) {
+	members, ok := e.gangMembers[pgFullName]
+	if !ok {
+		return 0, &framework.Resource{}
+	}
+	return members.count, members.request
+}
+
+// addGangMember accounts the pod as a member of its PodGroup, if any.
+func (e *ElasticQuotaInfo) addGangMember(pod *v1.Pod, podRequest *framework.Resource) {
+	pgFullName := util.GetPodGroupFullName(pod)
+	if len(pgFullName) == 0 {
+		return
+	}
+	if e.gangMembers == nil {
+		e.gangMembers = make(map[string]*gangMembers)
+	}
+	members, ok := e.gangMembers[pgFullName]
+	if !ok {
+		members = &gangMembers{request: &framework.Resource{}}
+		e.gangMembers[pgFullName] = members
+	}
+	members.count++
+	members.request.Add(util.ResourceList(podRequest))
+}
+
+// deleteGangMember stops accounting the pod as a member of its PodGroup, if any.
+func (e *ElasticQuotaInfo) deleteGangMember(pod *v1.Pod, podRequest *framework.Resource) {
+	pgFullName := util.GetPodGroupFullName(pod)
+	members, ok := e.gangMembers[pgFullName]
+	if !ok {
+		return
+	}
+	members.count--
+	if members.count <= 0 {
+		delete(e.gangMembers, pgFullName)
+		return
+	}
+	members.request = subtractNonNegative(members.request, podRequest)
+}
+
+// consumeGangReservation moves the request of a newly accounted PodGroup member
+// out of the quota held for its PodGroup, so the pod isn't counted twice.
+func (e *ElasticQuotaInfo) consumeGangReservation(pod *v1.Pod, podRequest *framework.Resource) {
+	pgFullName := util.GetPodGroupFullName(pod)
+	reserved, ok := e.gangReserved[pgFullName]
+	if !ok {
+		return
+	}
+	remaining := subtractNonNegative(reserved, podRequest)
+	if isZeroResource(remaining) {
+		delete(e.gangReserved, pgFullName)
+		return
+	}
+	e.gangReserved[pgFullName] = remaining
+}
+
+// inheritUsage carries the pods, the used resources and the quota held for PodGroups
+// over from the previous ElasticQuotaInfo of the namespace.
+func (e *ElasticQuotaInfo) inheritUsage(old *ElasticQuotaInfo) {
+	e.pods = old.pods
+	e.Used = old.Used
+	e.gangReserved = old.gangReserved
+	e.gangMembers = old.gangMembers
 }
 
 func (e *ElasticQuotaInfo) clone() *ElasticQuotaInfo {
 	newEQInfo := &ElasticQuotaInfo{
-		Namespace: e.Namespace,
-		pods:      sets.NewString(),
+		Namespace:          e.Namespace,
+		pods:               sets.NewString(),
+		trackedResources:   e.trackedResources,
+		bounds:             e.bounds,
+		defaulted:          e.defaulted,
+		excludedNamespaces: e.excludedNamespaces,
 	}
 
 	if e.Min != nil {
@@ -145,6 +316,18 @@ func (e *ElasticQuotaInfo) clone() *ElasticQuotaInfo {
 	if e.Used != nil {
 		newEQInfo.Used = e.Used.Clone()
 	}
+	if len(e.gangReserved) > 0 {
+		newEQInfo.gangReserved = make(map[string]*framework.Resource, len(e.gangReserved))
+		for pgFullName, reserved := range e.gangReserved {
+			newEQInfo.gangReserved[pgFullName] = reserved.Clone()
+		}
+	}
+	if len(e.gangMembers) > 0 {
+		newEQInfo.gangMembers = make(map[string]*gangMembers, len(e.gangMembers))
+		for pgFullName, members := range e.gangMembers {
+			newEQInfo.gangMembers[pgFullName] = &gangMembers{count: members.count, request: members.request.Clone()}
+		}
+	}
 	if len(e.pods) > 0 {
 		pods := e.pods.List()
 		for _, pod := range pods {
@@ -166,8 +349,10 @@ func (e *ElasticQuotaInfo) addPodIfNotPresent(pod *v1.Pod) error {
 	}
 
 	e.pods.Insert(key)
-	podRequest := computePodResourceRequest(pod)
+	podRequest := trackedResourceRequest(computePodResourceRequest(pod), e.trackedResources)
 	e.reserveResource(*podRequest)
+	e.consumeGangReservation(pod, podRequest)
+	e.addGangMember(pod, podRequest)
 
 	return nil
 }
@@ -183,8 +368,9 @@ func (e *ElasticQuotaInfo) deletePodIfPresent(pod *v1.Pod) error {
 	}
 
 	e.pods.Delete(key)
-	podRequest := computePodResourceRequest(pod)
+	podRequest := trackedResourceRequest(computePodResourceRequest(pod), e.trackedResources)
 	e.unreserveResource(*podRequest)
+	e.deleteGangMember(pod, podRequest)
 
 	return nil
 }
@@ -223,6 +409,75 @@ func cmp2(x1, x2, y *framework.Resource, bound int64) bool {
 	return false
 }
 
+// trackedResourceRequest returns the part of the request made of the tracked resources.
+// The whole request is returned if tracked is nil.
+func trackedResourceRequest(request *framework.Resource, tracked sets.Set[v1.ResourceName]) *framework.Resource {
+	if tracked == nil {
+		return request
+	}
+	result := &framework.Resource{}
+	if tracked.Has(v1.ResourceCPU) {
+		result.MilliCPU = request.MilliCPU
+	}
+	if tracked.Has(v1.ResourceMemory) {
+		result.Memory = request.Memory
+	}
+	if tracked.Has(v1.ResourceEphemeralStorage) {
+		result.EphemeralStorage = request.EphemeralStorage
+	}
+	if tracked.Has(v1.ResourcePods) {
+		result.AllowedPodNumber = request.AllowedPodNumber
+	}
+	for rName, rQuant := range request.ScalarResources {
+		if tracked.Has(rName) {
+			result.SetScalar(rName, rQuant)
+		}
+	}
+	return result
+}
+
+// subtractNonNegative returns x - y, flooring every resource dimension at zero.
+func subtractNonNegative(x, y *framework.Resource) *framework.Resource {
+	result := &framework.Resource{
+		MilliCPU:         max(x.MilliCPU-y.MilliCPU, 0),
+		Memory:           max(x.Memory-y.Memory, 0),
+		EphemeralStorage: max(x.EphemeralStorage-y.EphemeralStorage, 0),
+		AllowedPodNumber: max(x.AllowedPodNumber-y.AllowedPodNumber, 0),
+	}
+	for rName, rQuant := range x.ScalarResources {
+		if remaining := rQuant - y.ScalarResources[rName]; remaining > 0 {
+			result.SetScalar(rName, remaining)
+		}
+	}
+	return result
+}
+
+// scaleResource returns r multiplied by n in every resource dimension.
+func scaleResource(r *framework.Resource, n int64) *framework.Resource {
+	result := &framework.Resource{
+		MilliCPU:         r.MilliCPU * n,
+		Memory:           r.Memory * n,
+		EphemeralStorage: r.EphemeralStorage * n,
+		AllowedPodNumber: r.AllowedPodNumber * int(n),
+	}
+	for rName, rQuant := range r.ScalarResources {
+		result.SetScalar(rName, rQuant*n)
+	}
+	return result
+}
+
+func isZeroResource(r *framework.Resource) bool {
+	if r.MilliCPU != 0 || r.Memory != 0 || r.EphemeralStorage != 0 || r.AllowedPodNumber != 0 {
+		return false
+	}
+	for _, rQuant := range r.ScalarResources {
+		if rQuant != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func makeResourceListForBound(bound int64) v1.ResourceList {
 	return v1.ResourceList{
 		v1.ResourceCPU:              *resource.NewMilliQuantity(bound, resource.DecimalSI),
@@ -381,6 +381,29 @@ func TestUsedOverMaxWith(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			before: &ElasticQuotaInfo{
+				Namespace: "ns1",
+				Used: &framework.Resource{
+					MilliCPU: 10,
+					Memory:   10,
+				},
+				Max: &framework.Resource{
+					MilliCPU: 3000,
+					Memory:   100,
+				},
+				bounds: &quotaBounds{upperBoundOfMax: 4},
+			},
+			name: "ElasticQuotaInfo OverMaxWith GPU Over The Configured Upper Bound Of Max",
+			podRequest: &framework.Resource{
+				MilliCPU: 10,
+				Memory:   10,
+				ScalarResources: map[v1.ResourceName]int64{
+					ResourceGPU: 5,
+				},
+			},
+			expected: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -509,6 +532,7 @@ func TestNewElasticQuotaInfo(t *testing.T) {
 		max       v1.ResourceList
 		min       v1.ResourceList
 		used      v1.ResourceList
+		bounds    *quotaBounds
 	}
 
 	tests := []struct {
@@ -620,11 +644,40 @@ func TestNewElasticQuotaInfo(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "ElasticQuota Without Max and Min With Configured Bounds",
+			elasticQuotaParam: elasticQuotaParam{
+				namespace: "ns1",
+				max:       nil,
+				min:       nil,
+				used:      makeResourceList(0, 0),
+				bounds:    &quotaBounds{upperBoundOfMax: 1000, lowerBoundOfMin: 10},
+			},
+			expected: &ElasticQuotaInfo{
+				Namespace: "ns1",
+				pods:      sets.String{},
+				Max: &framework.Resource{
+					MilliCPU:         1000,
+					Memory:           1000,
+					EphemeralStorage: 1000,
+				},
+				Min: &framework.Resource{
+					MilliCPU:         10,
+					Memory:           10,
+					EphemeralStorage: 10,
+				},
+				Used: &framework.Resource{
+					MilliCPU: 0,
+					Memory:   0,
+				},
+				bounds: &quotaBounds{upperBoundOfMax: 1000, lowerBoundOfMin: 10},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			eqp := tt.elasticQuotaParam
-			if got := newElasticQuotaInfo(eqp.namespace, eqp.min, eqp.max, eqp.used); !reflect.DeepEqual(got, tt.expected) {
+			if got := newElasticQuotaInfoWithBounds(eqp.namespace, eqp.min, eqp.max, eqp.used, eqp.bounds); !reflect.DeepEqual(got, tt.expected) {
 				t.Errorf("expected %v, got %v", tt.expected, got)
 			}
 		})
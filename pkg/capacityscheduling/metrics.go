@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	schedulermetrics "k8s.io/kubernetes/pkg/scheduler/metrics"
+)
+
+const (
+	// capacitySchedulingSubsystem is the subsystem of the metrics of the plugin.
+	capacitySchedulingSubsystem = "capacity_scheduling"
+
+	// Below are the possible values of the reason label of the admission rejections.
+	rejectionReasonOverMax           = "OverMax"
+	rejectionReasonBorrowingDisabled = "BorrowingDisabled"
+	rejectionReasonOverAggregatedMin = "OverAggregatedMin"
+)
+
+// The quota label is the namespace of the ElasticQuota, or "*" for the catch-all quota.
+// The quantities are in the base unit of the resource, e.g. cores for cpu and bytes for memory.
+var (
+	elasticQuotaMin = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      capacitySchedulingSubsystem,
+			Name:           "elastic_quota_min",
+			Help:           "Guaranteed resources of the quota, by resource.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota", "resource"})
+
+	elasticQuotaMax = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      capacitySchedulingSubsystem,
+			Name:           "elastic_quota_max",
+			Help:           "Upper bound of the resources used by the quota, by resource. Unbounded resources aren't reported.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota", "resource"})
+
+	elasticQuotaUsed = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      capacitySchedulingSubsystem,
+			Name:           "elastic_quota_used",
+			Help:           "Resources used by the pods of the quota, and held for the PodGroups being admitted, by resource.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota", "resource"})
+
+	elasticQuotaBorrowed = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      capacitySchedulingSubsystem,
+			Name:           "elastic_quota_borrowed",
+			Help:           "Resources used by the quota above its min, by resource.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota", "resource"})
+
+	elasticQuotaLent = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      capacitySchedulingSubsystem,
+			Name:           "elastic_quota_lent",
+			Help:           "Guaranteed resources of the quota it doesn't use, and the other quotas can borrow, by resource.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota", "resource"})
+
+	admissionRejections = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      capacitySchedulingSubsystem,
+			Name:           "admission_rejections_total",
+			Help:           "Number of pods rejected in PreFilter, by quota and reason.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota", "reason"})
+
+	preemptionVictims = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      capacitySchedulingSubsystem,
+			Name:           "preemption_victims_total",
+			Help:           "Number of pods preempted, by quota of the preemptor and quota of the victim. The quota is empty for pods without one.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"preemptor_quota", "victim_quota"})
+
+	metricsList = []metrics.Registerable{
+		elasticQuotaMin,
+		elasticQuotaMax,
+		elasticQuotaUsed,
+		elasticQuotaBorrowed,
+		elasticQuotaLent,
+		admissionRejections,
+		preemptionVictims,
+	}
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the metrics of the plugin.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		schedulermetrics.RegisterMetrics(metricsList...)
+	})
+}
+
+// quotaResources returns the resources reported for the quota: cpu, memory, ephemeral-storage and
+// the scalar resources of its min, max or usage, restricted to the tracked resources if any.
+func quotaResources(e *ElasticQuotaInfo) sets.Set[v1.ResourceName] {
+	resources := sets.New(v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage)
+	for _, r := range []*framework.Resource{e.Min, e.Max, e.usedWithGangReserved()} {
+		for name := range r.ScalarResources {
+			resources.Insert(name)
+		}
+	}
+	if e.trackedResources != nil {
+		resources = resources.Intersection(e.trackedResources)
+	}
+	return resources
+}
+
+// resourceValue returns the value of the resource, in millicores for cpu, or the bound if it is a scalar
+// resource r doesn't set, like the quota checks do.
+func resourceValue(r *framework.Resource, name v1.ResourceName, bound int64) int64 {
+	switch name {
+	case v1.ResourceCPU:
+		return r.MilliCPU
+	case v1.ResourceMemory:
+		return r.Memory
+	case v1.ResourceEphemeralStorage:
+		return r.EphemeralStorage
+	default:
+		if value, ok := r.ScalarResources[name]; ok {
+			return value
+		}
+		return bound
+	}
+}
+
+// resourceQuantity converts the value of the resource to its base unit.
+func resourceQuantity(name v1.ResourceName, value int64) float64 {
+	if name == v1.ResourceCPU {
+		return float64(value) / 1000
+	}
+	return float64(value)
+}
+
+// updateQuotaMetrics reports the min, max and usage of the quota. The usage includes the quota held for
+// the PodGroups being admitted, as the admission checks do.
+func updateQuotaMetrics(e *ElasticQuotaInfo) {
+	used := e.usedWithGangReserved()
+	for name := range quotaResources(e) {
+		minValue := resourceValue(e.Min, name, e.bounds.lowerOfMin())
+		maxValue := resourceValue(e.Max, name, e.bounds.upperOfMax())
+		usedValue := resourceValue(used, name, 0)
+		labels := []string{e.Namespace, string(name)}
+
+		elasticQuotaMin.WithLabelValues(labels...).Set(resourceQuantity(name, minValue))
+		if maxValue == e.bounds.upperOfMax() {
+			elasticQuotaMax.Delete(map[string]string{"quota": e.Namespace, "resource": string(name)})
+		} else {
+			elasticQuotaMax.WithLabelValues(labels...).Set(resourceQuantity(name, maxValue))
+		}
+		elasticQuotaUsed.WithLabelValues(labels...).Set(resourceQuantity(name, usedValue))
+		elasticQuotaBorrowed.WithLabelValues(labels...).Set(resourceQuantity(name, max(usedValue-minValue, 0)))
+		elasticQuotaLent.WithLabelValues(labels...).Set(resourceQuantity(name, max(minValue-usedValue, 0)))
+	}
+}
+
+// deleteQuotaMetrics stops reporting the quota.
+func deleteQuotaMetrics(e *ElasticQuotaInfo) {
+	for name := range quotaResources(e) {
+		labels := map[string]string{"quota": e.Namespace, "resource": string(name)}
+		for _, gauge := range []*metrics.GaugeVec{elasticQuotaMin, elasticQuotaMax, elasticQuotaUsed, elasticQuotaBorrowed, elasticQuotaLent} {
+			gauge.Delete(labels)
+		}
+	}
+}
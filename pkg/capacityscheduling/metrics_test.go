@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+
+	schedulingtestutil "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestQuotaMetrics(t *testing.T) {
+	RegisterMetrics()
+
+	gaugeValue := func(gauge *metrics.GaugeVec, quota string, resource v1.ResourceName) float64 {
+		value, err := testutil.GetGaugeMetricValue(gauge.WithLabelValues(quota, string(resource)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	c := &CapacityScheduling{elasticQuotaInfos: NewElasticQuotaInfos()}
+	c.addElasticQuota(makeEQ("metrics-ns1", "eq1", makeResourceList(2000, 1000), makeResourceList(1000, 1000)))
+	if got := c.Reserve(context.TODO(), framework.NewCycleState(), makePod("p1", "metrics-ns1", 200, 1500, 1, 0, "p1", "node-a"), "node-a"); !got.IsSuccess() {
+		t.Fatalf("unexpected Reserve status: %v", got.Message())
+	}
+
+	tests := []struct {
+		name     string
+		gauge    *metrics.GaugeVec
+		resource v1.ResourceName
+		expected float64
+	}{
+		{name: "cpu min in cores", gauge: elasticQuotaMin, resource: v1.ResourceCPU, expected: 1},
+		{name: "cpu max in cores", gauge: elasticQuotaMax, resource: v1.ResourceCPU, expected: 2},
+		{name: "cpu used in cores", gauge: elasticQuotaUsed, resource: v1.ResourceCPU, expected: 1.5},
+		{name: "cpu borrowed", gauge: elasticQuotaBorrowed, resource: v1.ResourceCPU, expected: 0.5},
+		{name: "cpu lent", gauge: elasticQuotaLent, resource: v1.ResourceCPU, expected: 0},
+		{name: "memory borrowed", gauge: elasticQuotaBorrowed, resource: v1.ResourceMemory, expected: 0},
+		{name: "memory lent", gauge: elasticQuotaLent, resource: v1.ResourceMemory, expected: 800},
+		{name: "scalar resource min defaulting to the lower bound", gauge: elasticQuotaMin, resource: ResourceGPU, expected: 0},
+		{name: "scalar resource used", gauge: elasticQuotaUsed, resource: ResourceGPU, expected: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gaugeValue(tt.gauge, "metrics-ns1", tt.resource); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// The quota held for a PodGroup being admitted is used, as the admission checks count it.
+	eq1 := c.elasticQuotaInfos["metrics-ns1"]
+	eq1.reserveGang("metrics-ns1/pg1", *framework.NewResource(makeResourceList(300, 100)))
+	updateQuotaMetrics(eq1)
+	for _, tt := range []struct {
+		name     string
+		gauge    *metrics.GaugeVec
+		resource v1.ResourceName
+		expected float64
+	}{
+		{name: "cpu used with the PodGroup reservation", gauge: elasticQuotaUsed, resource: v1.ResourceCPU, expected: 1.8},
+		{name: "cpu borrowed with the PodGroup reservation", gauge: elasticQuotaBorrowed, resource: v1.ResourceCPU, expected: 0.8},
+		{name: "memory lent with the PodGroup reservation", gauge: elasticQuotaLent, resource: v1.ResourceMemory, expected: 700},
+	} {
+		if got := gaugeValue(tt.gauge, "metrics-ns1", tt.resource); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+	eq1.releaseGang("metrics-ns1/pg1")
+	updateQuotaMetrics(eq1)
+	if got := gaugeValue(elasticQuotaUsed, "metrics-ns1", v1.ResourceCPU); got != 1.5 {
+		t.Errorf("expected the released PodGroup reservation not to be used, got %v", got)
+	}
+
+	// The max of a scalar resource the quota doesn't set is unbounded, so it isn't reported.
+	if elasticQuotaMax.Delete(map[string]string{"quota": "metrics-ns1", "resource": string(ResourceGPU)}) {
+		t.Errorf("expected the unbounded max of a scalar resource not to be reported")
+	}
+
+	c.deleteElasticQuota(makeEQ("metrics-ns1", "eq1", makeResourceList(2000, 1000), makeResourceList(1000, 1000)))
+	if elasticQuotaUsed.Delete(map[string]string{"quota": "metrics-ns1", "resource": string(v1.ResourceCPU)}) {
+		t.Errorf("expected the metrics of a deleted quota not to be reported")
+	}
+}
+
+func TestAdmissionRejectionMetrics(t *testing.T) {
+	RegisterMetrics()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fwk, err := tf.NewFramework(
+		ctx,
+		[]tf.RegisterPluginFunc{
+			tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		},
+		"",
+		frameworkruntime.WithPodNominator(schedulingtestutil.NewPodNominator(nil)),
+		frameworkruntime.WithSnapshotSharedLister(schedulingtestutil.NewFakeSharedLister(nil, nil)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &CapacityScheduling{
+		fh: fwk,
+		elasticQuotaInfos: ElasticQuotaInfos{
+			"metrics-ns2": newElasticQuotaInfo("metrics-ns2", makeResourceList(0, 1000), makeResourceList(0, 2000), makeResourceList(0, 1800)),
+		},
+	}
+	counter := admissionRejections.WithLabelValues("metrics-ns2", rejectionReasonOverMax)
+	before, _ := testutil.GetCounterMetricValue(counter)
+	if _, got := c.PreFilter(ctx, framework.NewCycleState(), makePod("p1", "metrics-ns2", 500, 0, 0, 0, "p1", "")); got.Code() != framework.Unschedulable {
+		t.Fatalf("expected pod to be rejected, got %v", got)
+	}
+	after, _ := testutil.GetCounterMetricValue(counter)
+	if after-before != 1 {
+		t.Errorf("expected 1 rejection reported, got %v", after-before)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// CheckpointAnnotationKey is the annotation set to "true" on pods that checkpoint their work
+// and can resume from it, so that evicting them loses little work.
+const CheckpointAnnotationKey = "scheduling.x-k8s.io/checkpointed"
+
+// victimCostModel estimates how much work is lost by evicting a pod during preemption.
+type victimCostModel interface {
+	// Cost returns the cost of evicting the pod, between 0 (nothing is lost) and 1.
+	Cost(pod *v1.Pod, now time.Time) float64
+}
+
+// newVictimCostModel returns the cost model selected in the plugin args,
+// or nil if the victims are ordered by priority only.
+func newVictimCostModel(args config.VictimCostModel) (victimCostModel, error) {
+	switch args.Type {
+	case config.VictimCostModelPriority, "":
+		return nil, nil
+	case config.VictimCostModelWorkLost:
+		return &workLostCostModel{
+			runtimeWeight:        float64(args.RuntimeWeight),
+			restartsWeight:       float64(args.RestartsWeight),
+			checkpointWeight:     float64(args.CheckpointWeight),
+			qosWeight:            float64(args.QoSWeight),
+			runtimeNormalization: time.Duration(args.RuntimeNormalizationSeconds) * time.Second,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown victim cost model %q", args.Type)
+	}
+}
+
+// workLostCostModel is the weighted average of the following factors, each between 0 and 1:
+// - runtime: the time the pod has been running, up to runtimeNormalization;
+// - restarts: 1 / (1 + the number of container restarts), as a restarting pod has little progress to lose;
+// - checkpoint: 0 if the pod is checkpointed, 1 otherwise;
+// - QoS: 0 for BestEffort, 0.5 for Burstable and 1 for Guaranteed pods.
+type workLostCostModel struct {
+	runtimeWeight        float64
+	restartsWeight       float64
+	checkpointWeight     float64
+	qosWeight            float64
+	runtimeNormalization time.Duration
+}
+
+func (m *workLostCostModel) Cost(pod *v1.Pod, now time.Time) float64 {
+	totalWeight := m.runtimeWeight + m.restartsWeight + m.checkpointWeight + m.qosWeight
+	if totalWeight <= 0 {
+		return 0
+	}
+
+	runtime := 0.0
+	if pod.Status.StartTime != nil && m.runtimeNormalization > 0 {
+		runtime = min(float64(now.Sub(pod.Status.StartTime.Time))/float64(m.runtimeNormalization), 1)
+		runtime = max(runtime, 0)
+	}
+
+	var restartCount int32
+	for _, status := range pod.Status.ContainerStatuses {
+		restartCount += status.RestartCount
+	}
+	restarts := 1 / (1 + float64(restartCount))
+
+	checkpoint := 1.0
+	if pod.Annotations[CheckpointAnnotationKey] == "true" {
+		checkpoint = 0
+	}
+
+	var qos float64
+	switch v1qos.GetPodQOS(pod) {
+	case v1.PodQOSGuaranteed:
+		qos = 1
+	case v1.PodQOSBurstable:
+		qos = 0.5
+	}
+
+	return (m.runtimeWeight*runtime + m.restartsWeight*restarts + m.checkpointWeight*checkpoint + m.qosWeight*qos) / totalWeight
+}
+
+// moreImportantVictim returns a function that reports whether pod1 should be reprieved before pod2.
+// Pods are ordered by priority first; pods with the same priority are ordered by the cost
+// of evicting them, or by start time if no cost model is configured.
+func moreImportantVictim(model victimCostModel, now time.Time) func(pod1, pod2 *v1.Pod) bool {
+	if model == nil {
+		return schedutil.MoreImportantPod
+	}
+	return func(pod1, pod2 *v1.Pod) bool {
+		p1 := corev1helpers.PodPriority(pod1)
+		p2 := corev1helpers.PodPriority(pod2)
+		if p1 != p2 {
+			return p1 > p2
+		}
+		c1 := model.Cost(pod1, now)
+		c2 := model.Cost(pod2, now)
+		if c1 != c2 {
+			return c1 > c2
+		}
+		return schedutil.MoreImportantPod(pod1, pod2)
+	}
+}
+
+// victimCostScale converts the costs of the victims to integer scores without losing their order.
+const victimCostScale = 1e6
+
+// victimCostScoreFuncs returns the score functions choosing the node to preempt on, in order of precedence,
+// or nil to keep the default ones if no cost model is configured. They are the default ones, with the nodes
+// whose victims cost the least to evict preferred right after the nodes whose highest victim priority is the lowest.
+func victimCostScoreFuncs(model victimCostModel, nodesToVictims map[string]*extenderv1.Victims, now time.Time) []func(node string) int64 {
+	if model == nil {
+		return nil
+	}
+	minNumPDBViolating := func(node string) int64 {
+		return -nodesToVictims[node].NumPDBViolations
+	}
+	minHighestPriority := func(node string) int64 {
+		highest := int32(math.MinInt32)
+		for _, pod := range nodesToVictims[node].Pods {
+			highest = max(highest, corev1helpers.PodPriority(pod))
+		}
+		return -int64(highest)
+	}
+	minTotalCost := func(node string) int64 {
+		var cost float64
+		for _, pod := range nodesToVictims[node].Pods {
+			cost += model.Cost(pod, now)
+		}
+		return -int64(math.Round(cost * victimCostScale))
+	}
+	minSumPriorities := func(node string) int64 {
+		var sumPriorities int64
+		for _, pod := range nodesToVictims[node].Pods {
+			// Offset the priorities to make them all positive, as the default score functions do.
+			sumPriorities += int64(corev1helpers.PodPriority(pod)) + int64(math.MaxInt32+1)
+		}
+		return -sumPriorities
+	}
+	minNumPods := func(node string) int64 {
+		return -int64(len(nodesToVictims[node].Pods))
+	}
+	latestStartTime := func(node string) int64 {
+		earliestStartTime := schedutil.GetEarliestPodStartTime(nodesToVictims[node])
+		if earliestStartTime == nil {
+			return math.MinInt64
+		}
+		return earliestStartTime.UnixNano()
+	}
+	return []func(node string) int64{minNumPDBViolating, minHighestPriority, minTotalCost, minSumPriorities, minNumPods, latestStartTime}
+}
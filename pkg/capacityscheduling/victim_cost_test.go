@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework/preemption"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+func TestMoreImportantVictim(t *testing.T) {
+	now := time.Now()
+	withStartTime := func(pod *v1.Pod, runtime time.Duration) *v1.Pod {
+		startTime := metav1.NewTime(now.Add(-runtime))
+		pod.Status.StartTime = &startTime
+		return pod
+	}
+	withRestarts := func(pod *v1.Pod, restarts int32) *v1.Pod {
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{RestartCount: restarts}}
+		return pod
+	}
+	withCheckpoint := func(pod *v1.Pod) *v1.Pod {
+		pod.Annotations = map[string]string{CheckpointAnnotationKey: "true"}
+		return pod
+	}
+
+	workLost := config.VictimCostModel{
+		Type:                        config.VictimCostModelWorkLost,
+		RuntimeWeight:               1,
+		RestartsWeight:              1,
+		CheckpointWeight:            1,
+		QoSWeight:                   1,
+		RuntimeNormalizationSeconds: 3600,
+	}
+
+	tests := []struct {
+		name      string
+		costModel config.VictimCostModel
+		pods      []*v1.Pod
+		expected  []string
+	}{
+		{
+			name:      "priority cost model keeps the oldest pods first",
+			costModel: config.VictimCostModel{Type: config.VictimCostModelPriority},
+			pods: []*v1.Pod{
+				withStartTime(makePod("p1", "ns1", 50, 0, 0, midPriority, "p1", "node-a"), time.Minute),
+				withStartTime(withCheckpoint(makePod("p2", "ns1", 50, 0, 0, midPriority, "p2", "node-a")), 2*time.Hour),
+				withStartTime(makePod("p3", "ns1", 50, 0, 0, highPriority, "p3", "node-a"), time.Minute),
+			},
+			expected: []string{"p3", "p2", "p1"},
+		},
+		{
+			name:      "work lost cost model reprieves long running pods first",
+			costModel: workLost,
+			pods: []*v1.Pod{
+				withStartTime(makePod("p1", "ns1", 50, 0, 0, midPriority, "p1", "node-a"), time.Minute),
+				withStartTime(makePod("p2", "ns1", 50, 0, 0, midPriority, "p2", "node-a"), 2*time.Hour),
+			},
+			expected: []string{"p2", "p1"},
+		},
+		{
+			name:      "work lost cost model evicts checkpointed and restarting pods first",
+			costModel: workLost,
+			pods: []*v1.Pod{
+				withStartTime(withCheckpoint(makePod("p1", "ns1", 50, 0, 0, midPriority, "p1", "node-a")), 2*time.Hour),
+				withStartTime(withRestarts(makePod("p2", "ns1", 50, 0, 0, midPriority, "p2", "node-a"), 5), 2*time.Hour),
+				withStartTime(makePod("p3", "ns1", 50, 0, 0, midPriority, "p3", "node-a"), time.Hour),
+			},
+			expected: []string{"p3", "p2", "p1"},
+		},
+		{
+			name:      "work lost cost model still orders by priority first",
+			costModel: workLost,
+			pods: []*v1.Pod{
+				withStartTime(makePod("p1", "ns1", 50, 0, 0, midPriority, "p1", "node-a"), 2*time.Hour),
+				withStartTime(withCheckpoint(makePod("p2", "ns1", 50, 0, 0, highPriority, "p2", "node-a")), time.Minute),
+			},
+			expected: []string{"p2", "p1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := newVictimCostModel(tt.costModel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			moreImportant := moreImportantVictim(model, now)
+			sort.Slice(tt.pods, func(i, j int) bool {
+				return moreImportant(tt.pods[i], tt.pods[j])
+			})
+			var got []string
+			for _, pod := range tt.pods {
+				got = append(got, pod.Name)
+			}
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+type fakeCandidate struct {
+	name    string
+	victims *extenderv1.Victims
+}
+
+func (c *fakeCandidate) Victims() *extenderv1.Victims { return c.victims }
+func (c *fakeCandidate) Name() string                 { return c.name }
+
+func TestSelectCandidateByVictimCost(t *testing.T) {
+	now := time.Now()
+	withStartTime := func(pod *v1.Pod, runtime time.Duration) *v1.Pod {
+		startTime := metav1.NewTime(now.Add(-runtime))
+		pod.Status.StartTime = &startTime
+		return pod
+	}
+	// The victim on node-a started last, the one on node-b is checkpointed.
+	candidates := []preemption.Candidate{
+		&fakeCandidate{name: "node-a", victims: &extenderv1.Victims{Pods: []*v1.Pod{
+			withStartTime(makePod("p1", "ns1", 50, 0, 0, midPriority, "p1", "node-a"), 10*time.Minute),
+		}}},
+		&fakeCandidate{name: "node-b", victims: &extenderv1.Victims{Pods: []*v1.Pod{
+			withStartTime(makePod("p2", "ns1", 50, 0, 0, midPriority, "p2", "node-b"), time.Hour),
+		}}},
+		&fakeCandidate{name: "node-c", victims: &extenderv1.Victims{Pods: []*v1.Pod{
+			withStartTime(makePod("p3", "ns1", 50, 0, 0, highPriority, "p3", "node-c"), time.Minute),
+		}}},
+	}
+	candidates[1].Victims().Pods[0].Annotations = map[string]string{CheckpointAnnotationKey: "true"}
+
+	tests := []struct {
+		name      string
+		costModel config.VictimCostModel
+		expected  string
+	}{
+		{
+			name:      "priority cost model preempts on the node whose victims started last",
+			costModel: config.VictimCostModel{Type: config.VictimCostModelPriority},
+			expected:  "node-a",
+		},
+		{
+			name: "work lost cost model preempts on the node whose victims cost the least to evict",
+			costModel: config.VictimCostModel{
+				Type:                        config.VictimCostModelWorkLost,
+				RuntimeWeight:               1,
+				RestartsWeight:              1,
+				CheckpointWeight:            1,
+				QoSWeight:                   1,
+				RuntimeNormalizationSeconds: 3600,
+			},
+			expected: "node-b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := newVictimCostModel(tt.costModel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ev := preemption.Evaluator{Interface: &preemptor{victimCostModel: model}}
+			if got := ev.SelectCandidate(context.TODO(), candidates); got.Name() != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got.Name())
+			}
+		})
+	}
+}
+
+func TestNewVictimCostModel(t *testing.T) {
+	if _, err := newVictimCostModel(config.VictimCostModel{Type: "Unknown"}); err == nil {
+		t.Errorf("expected an error for an unknown victim cost model")
+	}
+}
@@ -18,53 +18,132 @@ package cache
 
 import (
 	"context"
+	"math"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
 )
 
+const (
+	watchBackoffInitial = time.Second
+	watchBackoffFactor  = 2.0
+	watchBackoffJitter  = 0.1
+	watchBackoffCap     = time.Minute
+)
+
 type Watcher struct {
-	lh    logr.Logger
-	nrts  *nrtStore
+	lh logr.Logger
+	// lock protects nrts and nodes, shared with the cache
+	lock sync.Locker
+	nrts *nrtStore
+	// nodes tracks the nodes whose configuration attributes changed, nil to ignore the attributes
 	nodes counter
+	// onFingerprintChange, if set, is called with the updated NRT objects whose podset fingerprint
+	// differs from the cached one
+	onFingerprintChange func(nrt *topologyv1alpha2.NodeResourceTopology)
 }
 
+// NodeResourceTopologies watches the NRT objects until ctx is done. The watch is restarted from the last
+// resourceVersion seen when it expires, backing off exponentially with jitter while it keeps failing.
 func (wt Watcher) NodeResourceTopologies(ctx context.Context, client ctrlclient.WithWatch) {
-	done := false
-	for !done {
-		wt.lh.Info("start watching NRT objects")
+	resourceVersion := ""
+	backoff := newWatchBackoff()
+	for {
+		wt.lh.Info("start watching NRT objects", "resourceVersion", resourceVersion)
 
 		nrtObjs := topologyv1alpha2.NodeResourceTopologyList{}
-		wa, err := client.Watch(ctx, &nrtObjs)
+		wa, err := client.Watch(ctx, &nrtObjs, &ctrlclient.ListOptions{
+			Raw: &metav1.ListOptions{
+				ResourceVersion:     resourceVersion,
+				AllowWatchBookmarks: true,
+			},
+		})
 		if err != nil {
 			wt.lh.Error(err, "cannot watch NRT objects")
-			return
+			if isResourceVersionTooOld(err) {
+				resourceVersion = ""
+			}
+		} else {
+			var received bool
+			resourceVersion, received = wt.processEvents(ctx, wa, resourceVersion)
+			if received {
+				backoff = newWatchBackoff()
+			}
 		}
 
-		for !done {
-			select {
-			case ev := <-wa.ResultChan():
-				wt.ProcessEvent(ev)
+		delay := backoff.Step()
+		wt.lh.Info("done watching NRT objects", "retryAfter", delay)
+		select {
+		case <-ctx.Done():
+			wt.lh.Info("stop watching NRT objects")
+			return
+		case <-time.After(delay):
+		}
+	}
+}
 
-			case <-ctx.Done():
-				wt.lh.Info("stop watching NRT objects")
-				wa.Stop()
-				done = true
+// processEvents processes the events of the watch until it ends or ctx is done. It returns the resourceVersion
+// to resume watching from, and whether any event was received.
+func (wt Watcher) processEvents(ctx context.Context, wa watch.Interface, resourceVersion string) (string, bool) {
+	defer wa.Stop()
+	received := false
+	for {
+		select {
+		case ev, ok := <-wa.ResultChan():
+			if !ok {
+				// the watch expired
+				return resourceVersion, received
+			}
+			if ev.Type == watch.Error {
+				err := apierrors.FromObject(ev.Object)
+				wt.lh.Error(err, "error watching NRT objects")
+				if isResourceVersionTooOld(err) {
+					resourceVersion = ""
+				}
+				return resourceVersion, received
+			}
+			received = true
+			if obj, err := meta.Accessor(ev.Object); err == nil {
+				resourceVersion = obj.GetResourceVersion()
 			}
+			wt.ProcessEvent(ev)
+
+		case <-ctx.Done():
+			return resourceVersion, received
 		}
+	}
+}
 
-		wt.lh.Info("done watching NRT objects")
+func newWatchBackoff() *wait.Backoff {
+	return &wait.Backoff{
+		Duration: watchBackoffInitial,
+		Factor:   watchBackoffFactor,
+		Jitter:   watchBackoffJitter,
+		Steps:    math.MaxInt32,
+		Cap:      watchBackoffCap,
 	}
 }
 
+// isResourceVersionTooOld returns true if the watch can't resume from the resourceVersion anymore
+func isResourceVersionTooOld(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
+
 func (wt Watcher) ProcessEvent(ev watch.Event) bool {
 	if ev.Type != watch.Modified {
 		return false
@@ -76,24 +155,47 @@ func (wt Watcher) ProcessEvent(ev watch.Event) bool {
 		return false
 	}
 
+	wt.lock.Lock()
 	nrtCur := wt.nrts.GetNRTCopyByNodeName(nrtObj.Name)
+	wt.lock.Unlock()
 	if nrtCur == nil {
 		wt.lh.Info("modified non-existent NRT", logging.KeyNode, nrtObj.Name)
 		return false
 	}
 
-	if !areAttrsChanged(nrtCur, nrtObj) {
+	if wt.onFingerprintChange != nil && isFingerprintChanged(nrtCur, nrtObj) {
+		wt.lh.V(4).Info("podset fingerprint change", logging.KeyNode, nrtObj.Name)
+		// runs outside the lock, because the resync takes it
+		wt.onFingerprintChange(nrtObj)
+	}
+
+	if wt.nodes == nil || !areAttrsChanged(nrtCur, nrtObj) {
 		return false
 	}
 
 	wt.lh.V(4).Info("attribute change", logging.KeyNode, nrtObj.Name)
+	wt.lock.Lock()
 	wt.nodes.Incr(nrtObj.Name)
+	wt.lock.Unlock()
 	return true
 }
 
+// isFingerprintChanged returns true if the new NRT object reports a podset fingerprint different from the old one.
+// The resync method doesn't matter, because it only tells which pods the fingerprint includes.
+func isFingerprintChanged(oldNrt, newNrt *topologyv1alpha2.NodeResourceTopology) bool {
+	oldPfp, _ := podFingerprintForNodeTopology(oldNrt, apiconfig.CacheResyncAutodetect)
+	newPfp, _ := podFingerprintForNodeTopology(newNrt, apiconfig.CacheResyncAutodetect)
+	return newPfp != "" && newPfp != oldPfp
+}
+
 func areAttrsChanged(oldNrt, newNrt *topologyv1alpha2.NodeResourceTopology) bool {
 	lh := logr.Discard() // avoid spam in the logs
 	oldConf := nodeconfig.TopologyManagerFromNodeResourceTopology(lh, oldNrt)
 	newConf := nodeconfig.TopologyManagerFromNodeResourceTopology(lh, newNrt)
-	return !oldConf.Equal(newConf)
+	if !oldConf.Equal(newConf) {
+		return true
+	}
+	oldManagers := nodeconfig.ResourceManagersFromNodeResourceTopology(oldNrt)
+	newManagers := nodeconfig.ResourceManagersFromNodeResourceTopology(newNrt)
+	return !oldManagers.Equal(newManagers)
 }
@@ -17,7 +17,10 @@ limitations under the License.
 package cache
 
 import (
+	"context"
+	"net/http"
 	"reflect"
+	"sync"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,6 +29,7 @@ import (
 	"k8s.io/klog/v2"
 
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
 )
 
 func TestWatcherProcessEvent(t *testing.T) {
@@ -137,6 +141,7 @@ func TestWatcherProcessEvent(t *testing.T) {
 
 	wt := Watcher{
 		lh:    klog.Background(),
+		lock:  &sync.Mutex{},
 		nrts:  newNrtStore(klog.Background(), nrts),
 		nodes: newCounter(),
 	}
@@ -151,3 +156,140 @@ func TestWatcherProcessEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestWatcherFingerprintChange(t *testing.T) {
+	makeNRT := func(pfp string) *topologyv1alpha2.NodeResourceTopology {
+		nrt := &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-0",
+			},
+		}
+		if pfp != "" {
+			nrt.Attributes = []topologyv1alpha2.AttributeInfo{
+				{
+					Name:  podfingerprint.Attribute,
+					Value: pfp,
+				},
+			}
+		}
+		return nrt
+	}
+
+	tcases := []struct {
+		description string
+		ev          watch.Event
+		expected    []string
+	}{
+		{
+			description: "same fingerprint",
+			ev: watch.Event{
+				Type:   watch.Modified,
+				Object: makeNRT("pfp0v001aaaaaaaaaaaaaaaa"),
+			},
+		},
+		{
+			description: "missing fingerprint",
+			ev: watch.Event{
+				Type:   watch.Modified,
+				Object: makeNRT(""),
+			},
+		},
+		{
+			description: "new object",
+			ev: watch.Event{
+				Type:   watch.Added,
+				Object: makeNRT("pfp0v001bbbbbbbbbbbbbbbb"),
+			},
+		},
+		{
+			description: "fingerprint change",
+			ev: watch.Event{
+				Type:   watch.Modified,
+				Object: makeNRT("pfp0v001bbbbbbbbbbbbbbbb"),
+			},
+			expected: []string{"node-0"},
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.description, func(t *testing.T) {
+			var got []string
+			wt := Watcher{
+				lh:   klog.Background(),
+				lock: &sync.Mutex{},
+				nrts: newNrtStore(klog.Background(), []topologyv1alpha2.NodeResourceTopology{*makeNRT("pfp0v001aaaaaaaaaaaaaaaa")}),
+				onFingerprintChange: func(nrt *topologyv1alpha2.NodeResourceTopology) {
+					got = append(got, nrt.Name)
+				},
+			}
+			if wt.ProcessEvent(tcase.ev) {
+				t.Errorf("unexpected attribute change without tracked nodes")
+			}
+			if !reflect.DeepEqual(got, tcase.expected) {
+				t.Errorf("got=%+v expected=%+v", got, tcase.expected)
+			}
+		})
+	}
+}
+
+func TestWatcherProcessEventsResourceVersion(t *testing.T) {
+	makeNRT := func(resourceVersion string) *topologyv1alpha2.NodeResourceTopology {
+		return &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "node-0",
+				ResourceVersion: resourceVersion,
+			},
+		}
+	}
+
+	tcases := []struct {
+		description             string
+		events                  []watch.Event
+		expectedResourceVersion string
+		expectedReceived        bool
+	}{
+		{
+			description:             "no events",
+			expectedResourceVersion: "10",
+		},
+		{
+			description: "resume from the last event",
+			events: []watch.Event{
+				{Type: watch.Modified, Object: makeNRT("11")},
+				{Type: watch.Bookmark, Object: makeNRT("12")},
+			},
+			expectedResourceVersion: "12",
+			expectedReceived:        true,
+		},
+		{
+			description: "expired resource version",
+			events: []watch.Event{
+				{Type: watch.Error, Object: &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonExpired}},
+			},
+			expectedResourceVersion: "",
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.description, func(t *testing.T) {
+			wt := Watcher{
+				lh:   klog.Background(),
+				lock: &sync.Mutex{},
+				nrts: newNrtStore(klog.Background(), nil),
+			}
+			wa := watch.NewFakeWithChanSize(len(tcase.events), false)
+			for _, ev := range tcase.events {
+				wa.Action(ev.Type, ev.Object)
+			}
+			wa.Stop()
+
+			resourceVersion, received := wt.processEvents(context.Background(), wa, "10")
+			if resourceVersion != tcase.expectedResourceVersion {
+				t.Errorf("resourceVersion=%q expected=%q", resourceVersion, tcase.expectedResourceVersion)
+			}
+			if received != tcase.expectedReceived {
+				t.Errorf("received=%v expected=%v", received, tcase.expectedReceived)
+			}
+		})
+	}
+}
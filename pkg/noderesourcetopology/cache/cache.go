@@ -18,12 +18,44 @@ package cache
 
 import (
 	"context"
+	"sort"
+	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
 )
 
+// NUMAReservation maps the NUMA zone IDs to the resources a pod is expected to be allocated on them.
+type NUMAReservation map[int]corev1.ResourceList
+
+func (nr NUMAReservation) DeepCopy() NUMAReservation {
+	if nr == nil {
+		return nil
+	}
+	ret := make(NUMAReservation, len(nr))
+	for numaID, res := range nr {
+		ret[numaID] = res.DeepCopy()
+	}
+	return ret
+}
+
+func (nr NUMAReservation) String() string {
+	numaIDs := make([]int, 0, len(nr))
+	for numaID := range nr {
+		numaIDs = append(numaIDs, numaID)
+	}
+	sort.Ints(numaIDs)
+	var sb strings.Builder
+	for _, numaID := range numaIDs {
+		sb.WriteString("numa" + strconv.Itoa(numaID) + "=[" + stringify.ResourceList(nr[numaID]) + "];")
+	}
+	return sb.String()
+}
+
 type Interface interface {
 	// GetCachedNRTCopy retrieves a NRT copy from cache, and then deducts over-reserved resources if necessary.
 	// It will be used as the source of truth across the Pod's scheduling cycle.
@@ -58,6 +90,12 @@ type Interface interface {
 	// this sequence of events as the previous pod required too much - a possible and benign condition.
 	ReserveNodeResources(nodeName string, pod *corev1.Pod)
 
+	// ReserveNodeNUMAResources is like ReserveNodeResources, but the resources of the pod in the reservation
+	// are added to the assumed resources of the given NUMA zones only, like the kubelet is expected to allocate them.
+	// The resources of the pod not in the reservation are still pessimistically overallocated on ALL the NUMA zones.
+	// The caches which don't track the NUMA zones handle it like ReserveNodeResources.
+	ReserveNodeNUMAResources(nodeName string, pod *corev1.Pod, reservation NUMAReservation)
+
 	// UnreserveNodeResources decrement from the node assumed resources the resources required by the given pod.
 	UnreserveNodeResources(nodeName string, pod *corev1.Pod)
 
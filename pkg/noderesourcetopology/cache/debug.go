@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DebugNodesPath is the path of the debug endpoints serving the state of the cache per node,
+// followed by the name of the scheduler profile
+const DebugNodesPath = "/debug/noderesourcetopology/cache"
+
+// NodeState is the state of the cache for a node
+type NodeState struct {
+	// NRT is the cached NodeResourceTopology, without the assumed resources
+	NRT *topologyv1alpha2.NodeResourceTopology `json:"nrt,omitempty"`
+	// AssumedResources are the resources of the pods reserved on the node since its latest resync, by pod namespace/name
+	AssumedResources map[string]corev1.ResourceList `json:"assumedResources,omitempty"`
+	// AssumedNUMAResources are the resources of the reserved pods assumed on specific NUMA zones only, by pod namespace/name
+	AssumedNUMAResources map[string]NUMAReservation `json:"assumedNUMAResources,omitempty"`
+	// MaybeOverReservedCount is how many times the node was filtered out since its latest reserve or resync
+	MaybeOverReservedCount int  `json:"maybeOverReservedCount,omitempty"`
+	HasForeignPods         bool `json:"hasForeignPods,omitempty"`
+	ConfigChanged          bool `json:"configChanged,omitempty"`
+}
+
+// NodesState returns a copy of the state of the cache, by node name, for all the nodes if nodeName is empty
+func (ov *OverReserve) NodesState(nodeName string) map[string]*NodeState {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	nodes := make(map[string]*NodeState)
+	getState := func(name string) *NodeState {
+		state, ok := nodes[name]
+		if !ok {
+			state = &NodeState{}
+			nodes[name] = state
+		}
+		return state
+	}
+	isRequested := func(name string) bool {
+		return nodeName == "" || name == nodeName
+	}
+
+	for name, nrt := range ov.nrts.data {
+		if isRequested(name) {
+			getState(name).NRT = nrt.DeepCopy()
+		}
+	}
+	for name, store := range ov.assumedResources {
+		if !isRequested(name) || len(store.data) == 0 {
+			continue
+		}
+		assumed := make(map[string]corev1.ResourceList, len(store.data))
+		for key, res := range store.data {
+			assumed[key] = res.DeepCopy()
+		}
+		getState(name).AssumedResources = assumed
+		if len(store.numaData) == 0 {
+			continue
+		}
+		assumedNUMA := make(map[string]NUMAReservation, len(store.numaData))
+		for key, reservation := range store.numaData {
+			assumedNUMA[key] = reservation.DeepCopy()
+		}
+		getState(name).AssumedNUMAResources = assumedNUMA
+	}
+	for name, count := range ov.nodesMaybeOverreserved {
+		if isRequested(name) {
+			getState(name).MaybeOverReservedCount = count
+		}
+	}
+	for _, name := range ov.nodesWithForeignPods.Keys() {
+		if isRequested(name) {
+			getState(name).HasForeignPods = true
+		}
+	}
+	for _, name := range ov.nodesWithAttrUpdate.Keys() {
+		if isRequested(name) {
+			getState(name).ConfigChanged = true
+		}
+	}
+	return nodes
+}
+
+// ServeHTTP serves the state of the cache as JSON, by node name, optionally restricted to the node given as query parameter
+func (ov *OverReserve) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	nodes := ov.NodesState(req.URL.Query().Get("node"))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(nodes); err != nil {
+		ov.lh.Error(err, "cannot encode the cache state")
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestServeHTTPNodesState(t *testing.T) {
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nrtCache := mustOverReserve(t, fakeClient, &fakePodLister{})
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+		other := obj.DeepCopy()
+		other.Name = "node2"
+		nrtCache.Store().Update(other)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("2"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nrtCache.ReserveNodeResources("node1", pod)
+	nrtCache.NodeMaybeOverReserved("node1", pod)
+	nrtCache.NodeMaybeOverReserved("node1", pod)
+	nrtCache.NodeHasForeignPods("node2", pod)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantNodes []string
+	}{
+		{
+			name:      "all nodes",
+			wantNodes: []string{"node1", "node2"},
+		},
+		{
+			name:      "one node",
+			query:     "?node=node1",
+			wantNodes: []string{"node1"},
+		},
+		{
+			name:  "unknown node",
+			query: "?node=node-bogus",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			nrtCache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugNodesPath+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("unexpected status code %d", rec.Code)
+			}
+
+			var nodes map[string]*NodeState
+			if err := json.Unmarshal(rec.Body.Bytes(), &nodes); err != nil {
+				t.Fatal(err)
+			}
+			if len(nodes) != len(tt.wantNodes) {
+				t.Fatalf("expected nodes %v, got %d nodes", tt.wantNodes, len(nodes))
+			}
+			for _, nodeName := range tt.wantNodes {
+				if nodes[nodeName] == nil || nodes[nodeName].NRT == nil {
+					t.Errorf("missing cached NRT of node %q", nodeName)
+				}
+			}
+
+			if node1, ok := nodes["node1"]; ok {
+				qty := node1.AssumedResources["namespace1/pod1"][corev1.ResourceCPU]
+				if qty.Cmp(resource.MustParse("2")) != 0 {
+					t.Errorf("unexpected assumed cpu %v", qty.String())
+				}
+				if node1.MaybeOverReservedCount != 2 || node1.HasForeignPods {
+					t.Errorf("unexpected state of node1: %+v", node1)
+				}
+			}
+			if node2, ok := nodes["node2"]; ok {
+				if len(node2.AssumedResources) != 0 || !node2.HasForeignPods {
+					t.Errorf("unexpected state of node2: %+v", node2)
+				}
+			}
+		})
+	}
+}
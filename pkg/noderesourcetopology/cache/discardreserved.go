@@ -88,6 +88,11 @@ func (pt *DiscardReserved) ReserveNodeResources(nodeName string, pod *corev1.Pod
 	pt.reservationMap[nodeName][pod.GetUID()] = true
 }
 
+// ReserveNodeNUMAResources is like ReserveNodeResources: the node is discarded regardless of the NUMA zones
+func (pt *DiscardReserved) ReserveNodeNUMAResources(nodeName string, pod *corev1.Pod, _ NUMAReservation) {
+	pt.ReserveNodeResources(nodeName, pod)
+}
+
 func (pt *DiscardReserved) UnreserveNodeResources(nodeName string, pod *corev1.Pod) {
 	pt.lh.V(5).Info("NRT Unreserve", logging.KeyPod, klog.KObj(pod), logging.KeyPodUID, logging.PodUID(pod), logging.KeyNode, nodeName)
 
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	schedulermetrics "k8s.io/kubernetes/pkg/scheduler/metrics"
+)
+
+const (
+	// nrtCacheSubsystem is the subsystem of the metrics of the overreserve cache.
+	nrtCacheSubsystem = "noderesourcetopology_cache"
+
+	// Below are the possible values of the reason label of the dirty nodes.
+	dirtyReasonForeignPods       = "ForeignPods"
+	dirtyReasonMaybeOverReserved = "MaybeOverReserved"
+	dirtyReasonConfigChanged     = "ConfigChanged"
+
+	// Below are the possible values of the result label of the node resyncs.
+	resyncResultSuccess = "success"
+	resyncResultFailure = "failure"
+
+	// Below are the possible values of the reason label of the node resyncs.
+	resyncReasonResynced            = "Resynced"
+	resyncReasonConfigChanged       = "ConfigChanged"
+	resyncReasonPodListFailed       = "PodListFailed"
+	resyncReasonNRTGetFailed        = "NRTGetFailed"
+	resyncReasonMissingPods         = "MissingPods"
+	resyncReasonMissingFingerprint  = "MissingFingerprint"
+	resyncReasonFingerprintMismatch = "FingerprintMismatch"
+	resyncReasonFingerprintError    = "FingerprintError"
+)
+
+var (
+	dirtyNodes = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      nrtCacheSubsystem,
+			Name:           "dirty_nodes",
+			Help:           "Number of nodes the cache considers for resync at the latest resync step, by reason. A node may be dirty for more than one reason.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"reason"})
+
+	resyncDuration = metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Subsystem:      nrtCacheSubsystem,
+			Name:           "resync_duration_seconds",
+			Help:           "Duration of the resync steps which found dirty nodes, in seconds.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		})
+
+	resyncNodes = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      nrtCacheSubsystem,
+			Name:           "resync_nodes_total",
+			Help:           "Number of attempts to resync a dirty node, by result and reason.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"result", "reason"})
+
+	fingerprintMismatches = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      nrtCacheSubsystem,
+			Name:           "fingerprint_mismatches_total",
+			Help:           "Number of resyncs of a node whose podset fingerprint didn't match the pods the scheduler knows about.",
+			StabilityLevel: metrics.ALPHA,
+		})
+
+	foreignPodDetections = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      nrtCacheSubsystem,
+			Name:           "foreign_pod_detections_total",
+			Help:           "Number of pods not scheduled by this scheduler detected on the nodes tracked by the cache.",
+			StabilityLevel: metrics.ALPHA,
+		})
+
+	metricsList = []metrics.Registerable{
+		dirtyNodes,
+		resyncDuration,
+		resyncNodes,
+		fingerprintMismatches,
+		foreignPodDetections,
+	}
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the metrics of the overreserve cache.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		schedulermetrics.RegisterMetrics(metricsList...)
+	})
+}
+
+// updateDirtyNodesMetrics reports the count of the dirty nodes, by reason.
+func updateDirtyNodesMetrics(foreignCount, overreservedCount, configChangeCount int) {
+	dirtyNodes.WithLabelValues(dirtyReasonForeignPods).Set(float64(foreignCount))
+	dirtyNodes.WithLabelValues(dirtyReasonMaybeOverReserved).Set(float64(overreservedCount))
+	dirtyNodes.WithLabelValues(dirtyReasonConfigChanged).Set(float64(configChangeCount))
+}
+
+func recordResyncSuccess(reason string) {
+	resyncNodes.WithLabelValues(resyncResultSuccess, reason).Inc()
+}
+
+func recordResyncFailure(reason string) {
+	resyncNodes.WithLabelValues(resyncResultFailure, reason).Inc()
+}
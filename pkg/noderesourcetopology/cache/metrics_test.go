@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/klog/v2"
+
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestResyncMetrics(t *testing.T) {
+	RegisterMetrics()
+
+	counterValue := func(result, reason string) float64 {
+		value, err := testutil.GetCounterMetricValue(resyncNodes.WithLabelValues(result, reason))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	mismatchesValue := func() float64 {
+		value, err := testutil.GetCounterMetricValue(fingerprintMismatches)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	dirtyValue := func(reason string) float64 {
+		value, err := testutil.GetGaugeMetricValue(dirtyNodes.WithLabelValues(reason))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakePodLister := &fakePodLister{}
+	nrtCache := mustOverReserve(t, fakeClient, fakePodLister)
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+	}
+
+	// the fingerprint of the pod namespace1/pod1 running on node1
+	nrt := &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Attributes: topologyv1alpha2.AttributeList{
+			{
+				Name:  podfingerprint.Attribute,
+				Value: "pfp0v0019e0420efb37746c6",
+			},
+		},
+		TopologyPolicies: []string{string(topologyv1alpha2.SingleNUMANodeContainerLevel)},
+	}
+	if err := fakeClient.Create(context.Background(), nrt); err != nil {
+		t.Fatal(err)
+	}
+
+	makeRunningPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "namespace1",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node1",
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+			},
+		}
+	}
+
+	mismatches := mismatchesValue()
+	mismatchFailures := counterValue(resyncResultFailure, resyncReasonFingerprintMismatch)
+	successes := counterValue(resyncResultSuccess, resyncReasonResynced)
+
+	fakePodLister.AddPod(makeRunningPod("pod2"))
+	nrtCache.NodeMaybeOverReserved("node1", &corev1.Pod{})
+	nrtCache.Resync()
+
+	if got := dirtyValue(dirtyReasonMaybeOverReserved); got != 1 {
+		t.Errorf("expected 1 dirty node, got %v", got)
+	}
+	if got := mismatchesValue() - mismatches; got != 1 {
+		t.Errorf("expected 1 fingerprint mismatch, got %v", got)
+	}
+	if got := counterValue(resyncResultFailure, resyncReasonFingerprintMismatch) - mismatchFailures; got != 1 {
+		t.Errorf("expected 1 resync failure, got %v", got)
+	}
+
+	fakePodLister.pods = nil
+	fakePodLister.AddPod(makeRunningPod("pod1"))
+	nrtCache.Resync()
+
+	if got := counterValue(resyncResultSuccess, resyncReasonResynced) - successes; got != 1 {
+		t.Errorf("expected 1 resync success, got %v", got)
+	}
+	if dirty := nrtCache.GetDesyncedNodes(klog.Background()); dirty.Len() != 0 {
+		t.Errorf("node still dirty after resyncing with good data: %v", dirty)
+	}
+	if got := dirtyValue(dirtyReasonMaybeOverReserved); got != 0 {
+		t.Errorf("expected no dirty nodes, got %v", got)
+	}
+}
+
+func TestForeignPodDetectionsMetric(t *testing.T) {
+	RegisterMetrics()
+
+	detectionsValue := func() float64 {
+		value, err := testutil.GetCounterMetricValue(foreignPodDetections)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nrtCache := mustOverReserve(t, fakeClient, &fakePodLister{})
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+	}
+
+	detections := detectionsValue()
+	nrtCache.NodeHasForeignPods("node-bogus", &corev1.Pod{})
+	nrtCache.NodeHasForeignPods("node1", &corev1.Pod{})
+
+	if got := detectionsValue() - detections; got != 1 {
+		t.Errorf("expected 1 foreign pod detection on the known nodes, got %v", got)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
@@ -42,9 +43,11 @@ import (
 )
 
 type OverReserve struct {
-	lh               logr.Logger
-	client           ctrlclient.Reader
-	lock             sync.Mutex
+	lh     logr.Logger
+	client ctrlclient.Reader
+	lock   sync.Mutex
+	// resyncLock serializes the periodic and the targeted resyncs
+	resyncLock       sync.Mutex
 	nrts             *nrtStore
 	assumedResources map[string]*resourceStore // nodeName -> resourceStore
 	// nodesMaybeOverreserved counts how many times a node is filtered out. This is used as trigger condition to try
@@ -85,14 +88,16 @@ func NewOverReserve(ctx context.Context, lh logr.Logger, cfg *apiconfig.NodeReso
 		isPodRelevant:          isPodRelevant,
 	}
 
+	wt := Watcher{
+		lh:                  obj.lh,
+		lock:                &obj.lock,
+		nrts:                obj.nrts,
+		onFingerprintChange: obj.ResyncNode,
+	}
 	if resyncScope == apiconfig.CacheResyncScopeAll {
-		wt := Watcher{
-			lh:    obj.lh,
-			nrts:  obj.nrts,
-			nodes: obj.nodesWithAttrUpdate,
-		}
-		go wt.NodeResourceTopologies(ctx, client)
+		wt.nodes = obj.nodesWithAttrUpdate
 	}
+	go wt.NodeResourceTopologies(ctx, client)
 
 	return obj, nil
 }
@@ -139,10 +144,15 @@ func (ov *OverReserve) NodeHasForeignPods(nodeName string, pod *corev1.Pod) {
 		return
 	}
 	val := ov.nodesWithForeignPods.Incr(nodeName)
+	foreignPodDetections.Inc()
 	lh.V(2).Info("marked with foreign pods", logging.KeyNode, nodeName, "count", val)
 }
 
 func (ov *OverReserve) ReserveNodeResources(nodeName string, pod *corev1.Pod) {
+	ov.ReserveNodeNUMAResources(nodeName, pod, nil)
+}
+
+func (ov *OverReserve) ReserveNodeNUMAResources(nodeName string, pod *corev1.Pod, reservation NUMAReservation) {
 	lh := ov.lh.WithValues(logging.KeyPod, klog.KObj(pod), logging.KeyPodUID, logging.PodUID(pod), logging.KeyNode, nodeName)
 	ov.lock.Lock()
 	defer ov.lock.Unlock()
@@ -152,7 +162,7 @@ func (ov *OverReserve) ReserveNodeResources(nodeName string, pod *corev1.Pod) {
 		ov.assumedResources[nodeName] = nodeAssumedResources
 	}
 
-	nodeAssumedResources.AddPod(pod)
+	nodeAssumedResources.AddPodWithNUMAReservation(pod, reservation)
 	lh.V(2).Info("post reserve", logging.KeyNode, nodeName, "assumedResources", nodeAssumedResources.String())
 
 	ov.nodesMaybeOverreserved.Delete(nodeName)
@@ -224,6 +234,7 @@ func (ov *OverReserve) GetDesyncedNodes(lh logr.Logger) DesyncedNodes {
 	// always use local copies
 	configChangeNodes := ov.nodesWithAttrUpdate.Clone()
 	configChangeCount := configChangeNodes.Len()
+	updateDirtyNodesMetrics(foreignCount, overreservedCount, configChangeCount)
 
 	if nodes.Len() > 0 {
 		lh.V(4).Info("found dirty nodes", "foreign", foreignCount, "discarded", overreservedCount, "configChange", configChangeCount, "total", nodes.Len())
@@ -242,12 +253,17 @@ func (ov *OverReserve) GetDesyncedNodes(lh logr.Logger) DesyncedNodes {
 // If *both* a node has pessimistic overallocation accounted to it *and* was discarded "too many" (how much is too much is a runtime parameter
 // which needs to be set and tuned) times, then it becomes a candidate for resync. Just using one of these two factors would lead to
 // too aggressive resync attempts, so to more, likely unnecessary, computation work on the scheduler side.
+// Resync runs periodically as safety net: the dirty nodes are usually resynced as soon as their NRT object
+// is updated with a new podset fingerprint, see ResyncNode below.
 func (ov *OverReserve) Resync() {
 	// we are not working with a specific pod, so we need a unique key to track this flow
 	lh_ := ov.lh.WithName(logging.FlowCacheSync).WithValues(logging.KeyLogID, logging.TimeLogID())
 	lh_.V(4).Info(logging.FlowBegin)
 	defer lh_.V(4).Info(logging.FlowEnd)
 
+	ov.resyncLock.Lock()
+	defer ov.resyncLock.Unlock()
+
 	nodes := ov.GetDesyncedNodes(lh_)
 	// avoid as much as we can unnecessary work and logs.
 	if nodes.Len() == 0 {
@@ -255,10 +271,16 @@ func (ov *OverReserve) Resync() {
 		return
 	}
 
+	start := time.Now()
+	defer func() {
+		resyncDuration.Observe(time.Since(start).Seconds())
+	}()
+
 	// node -> pod identifier (namespace, name)
 	nodeToObjsMap, err := makeNodeToPodDataMap(lh_, ov.podLister, ov.isPodRelevant)
 	if err != nil {
 		lh_.Error(err, "cannot find the mapping between running pods and nodes")
+		resyncNodes.WithLabelValues(resyncResultFailure, resyncReasonPodListFailed).Add(float64(nodes.Len()))
 		return
 	}
 
@@ -269,41 +291,18 @@ func (ov *OverReserve) Resync() {
 		nrtCandidate := &topologyv1alpha2.NodeResourceTopology{}
 		if err := ov.client.Get(context.Background(), types.NamespacedName{Name: nodeName}, nrtCandidate); err != nil {
 			lh.V(2).Info("failed to get NodeTopology", "error", err)
+			recordResyncFailure(resyncReasonNRTGetFailed)
 			continue
 		}
 		if nrtCandidate == nil {
 			lh.V(2).Info("missing NodeTopology")
+			recordResyncFailure(resyncReasonNRTGetFailed)
 			continue
 		}
 
-		objs, ok := nodeToObjsMap[nodeName]
-		if !ok {
-			// this really should never happen
-			lh.Info("cannot find any pod for node")
-			continue
-		}
-
-		pfpExpected, onlyExclRes := podFingerprintForNodeTopology(nrtCandidate, ov.resyncMethod)
-		if pfpExpected == "" {
-			lh.V(2).Info("missing NodeTopology podset fingerprint data")
-			continue
-		}
-
-		lh.V(4).Info("trying to sync NodeTopology", "fingerprint", pfpExpected, "onlyExclusiveResources", onlyExclRes)
-
-		err = checkPodFingerprintForNode(lh, objs, nodeName, pfpExpected, onlyExclRes)
-		if errors.Is(err, podfingerprint.ErrSignatureMismatch) {
-			// can happen, not critical
-			lh.V(4).Info("NodeTopology podset fingerprint mismatch")
-			continue
-		}
-		if err != nil {
-			// should never happen, let's be vocal
-			lh.Error(err, "checking NodeTopology podset fingerprint")
+		if !ov.matchesPodFingerprint(lh, nrtCandidate, nodeToObjsMap[nodeName]) {
 			continue
 		}
-
-		lh.V(4).Info("overriding cached info", "reason", "resynced")
 		nrtUpdates = append(nrtUpdates, nrtCandidate)
 	}
 
@@ -313,20 +312,104 @@ func (ov *OverReserve) Resync() {
 		nrtCandidate := &topologyv1alpha2.NodeResourceTopology{}
 		if err := ov.client.Get(context.Background(), types.NamespacedName{Name: nodeName}, nrtCandidate); err != nil {
 			lh.V(2).Info("failed to get NodeTopology", "error", err)
+			recordResyncFailure(resyncReasonNRTGetFailed)
 			continue
 		}
 		if nrtCandidate == nil {
 			lh.V(2).Info("missing NodeTopology")
+			recordResyncFailure(resyncReasonNRTGetFailed)
 			continue
 		}
 
 		lh.V(4).Info("overriding cached info", "reason", "configChanged")
+		recordResyncSuccess(resyncReasonConfigChanged)
 		nrtUpdates = append(nrtUpdates, nrtCandidate)
 	}
 
 	ov.FlushNodes(lh_, nrtUpdates...)
 }
 
+// ResyncNode implements the targeted resync of a node, triggered by an update of its NRT object changing the
+// podset fingerprint. Like Resync does for all the dirty nodes, the cache of the node is Flush()ed with the
+// updated NRT object if the node is dirty and the fingerprint matches the pods running on the node.
+// The nodes not dirty are left untouched, because their cached data is still consistent.
+func (ov *OverReserve) ResyncNode(nrt *topologyv1alpha2.NodeResourceTopology) {
+	lh_ := ov.lh.WithName(logging.FlowCacheSync).WithValues(logging.KeyLogID, logging.TimeLogID())
+	lh := lh_.WithValues(logging.KeyNode, nrt.Name)
+	lh.V(4).Info(logging.FlowBegin)
+	defer lh.V(4).Info(logging.FlowEnd)
+
+	ov.resyncLock.Lock()
+	defer ov.resyncLock.Unlock()
+
+	if !ov.isNodeMaybeOverReserved(nrt.Name) {
+		lh.V(5).Info("node not dirty")
+		return
+	}
+
+	start := time.Now()
+	defer func() {
+		resyncDuration.Observe(time.Since(start).Seconds())
+	}()
+
+	objs, err := makePodDataForNode(lh, ov.podLister, ov.isPodRelevant, nrt.Name)
+	if err != nil {
+		lh.Error(err, "cannot find the pods running on node")
+		recordResyncFailure(resyncReasonPodListFailed)
+		return
+	}
+
+	if !ov.matchesPodFingerprint(lh, nrt, objs) {
+		return
+	}
+	ov.FlushNodes(lh_, nrt)
+}
+
+func (ov *OverReserve) isNodeMaybeOverReserved(nodeName string) bool {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	return ov.nodesMaybeOverreserved.IsSet(nodeName) || ov.nodesWithForeignPods.IsSet(nodeName)
+}
+
+// matchesPodFingerprint returns true if the podset fingerprint of the NRT object matches the pods running on its node,
+// so the NRT object reflects all the pods the scheduler knows about and can replace the cached data.
+func (ov *OverReserve) matchesPodFingerprint(lh logr.Logger, nrtCandidate *topologyv1alpha2.NodeResourceTopology, objs []podData) bool {
+	if len(objs) == 0 {
+		// this really should never happen
+		lh.Info("cannot find any pod for node")
+		recordResyncFailure(resyncReasonMissingPods)
+		return false
+	}
+
+	pfpExpected, onlyExclRes := podFingerprintForNodeTopology(nrtCandidate, ov.resyncMethod)
+	if pfpExpected == "" {
+		lh.V(2).Info("missing NodeTopology podset fingerprint data")
+		recordResyncFailure(resyncReasonMissingFingerprint)
+		return false
+	}
+
+	lh.V(4).Info("trying to sync NodeTopology", "fingerprint", pfpExpected, "onlyExclusiveResources", onlyExclRes)
+
+	err := checkPodFingerprintForNode(lh, objs, nrtCandidate.Name, pfpExpected, onlyExclRes)
+	if errors.Is(err, podfingerprint.ErrSignatureMismatch) {
+		// can happen, not critical
+		lh.V(4).Info("NodeTopology podset fingerprint mismatch")
+		fingerprintMismatches.Inc()
+		recordResyncFailure(resyncReasonFingerprintMismatch)
+		return false
+	}
+	if err != nil {
+		// should never happen, let's be vocal
+		lh.Error(err, "checking NodeTopology podset fingerprint")
+		recordResyncFailure(resyncReasonFingerprintError)
+		return false
+	}
+
+	lh.V(4).Info("overriding cached info", "reason", "resynced")
+	recordResyncSuccess(resyncReasonResynced)
+	return true
+}
+
 // FlushNodes drops all the cached information about a given node, resetting its state clean.
 func (ov *OverReserve) FlushNodes(lh logr.Logger, nrts ...*topologyv1alpha2.NodeResourceTopology) {
 	ov.lock.Lock()
@@ -356,17 +439,35 @@ func makeNodeToPodDataMap(lh logr.Logger, podLister podlisterv1.PodLister, isPod
 		if !isPodRelevant(lh, pod) {
 			continue
 		}
-		nodeObjs := nodeToObjsMap[pod.Spec.NodeName]
-		nodeObjs = append(nodeObjs, podData{
-			Namespace:             pod.Namespace,
-			Name:                  pod.Name,
-			HasExclusiveResources: resourcerequests.AreExclusiveForPod(pod),
-		})
-		nodeToObjsMap[pod.Spec.NodeName] = nodeObjs
+		nodeToObjsMap[pod.Spec.NodeName] = append(nodeToObjsMap[pod.Spec.NodeName], makePodData(pod))
 	}
 	return nodeToObjsMap, nil
 }
 
+// makePodDataForNode is like makeNodeToPodDataMap, but collects only the pods running on the given node.
+func makePodDataForNode(lh logr.Logger, podLister podlisterv1.PodLister, isPodRelevant podprovider.PodFilterFunc, nodeName string) ([]podData, error) {
+	pods, err := podLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var objs []podData
+	for _, pod := range pods {
+		if pod.Spec.NodeName != nodeName || !isPodRelevant(lh, pod) {
+			continue
+		}
+		objs = append(objs, makePodData(pod))
+	}
+	return objs, nil
+}
+
+func makePodData(pod *corev1.Pod) podData {
+	return podData{
+		Namespace:             pod.Namespace,
+		Name:                  pod.Name,
+		HasExclusiveResources: resourcerequests.AreExclusiveForPod(pod),
+	}
+}
+
 func getCacheResyncMethod(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache) apiconfig.CacheResyncMethod {
 	var resyncMethod apiconfig.CacheResyncMethod
 	if cfg != nil && cfg.ResyncMethod != nil {
@@ -624,6 +624,102 @@ func TestResyncMatchFingerprint(t *testing.T) {
 	}
 }
 
+func TestResyncNode(t *testing.T) {
+	// the fingerprint of the pod namespace1/pod1 running on node1
+	makeNRT := func(pfp string) *topologyv1alpha2.NodeResourceTopology {
+		return &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node1",
+			},
+			Attributes: topologyv1alpha2.AttributeList{
+				{
+					Name:  podfingerprint.Attribute,
+					Value: pfp,
+				},
+			},
+			TopologyPolicies: []string{string(topologyv1alpha2.SingleNUMANodeContainerLevel)},
+			Zones: topologyv1alpha2.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha2.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "32", "22"),
+						MakeTopologyResInfo(memory, "64Gi", "44Gi"),
+					},
+				},
+			},
+		}
+	}
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+
+	tcases := []struct {
+		description string
+		dirty       bool
+		nrt         *topologyv1alpha2.NodeResourceTopology
+		expectFlush bool
+	}{
+		{
+			description: "dirty node, matching fingerprint",
+			dirty:       true,
+			nrt:         makeNRT("pfp0v0019e0420efb37746c6"),
+			expectFlush: true,
+		},
+		{
+			description: "dirty node, mismatching fingerprint",
+			dirty:       true,
+			nrt:         makeNRT("pfp0v001aaaaaaaaaaaaaaaa"),
+		},
+		{
+			description: "clean node, matching fingerprint",
+			nrt:         makeNRT("pfp0v0019e0420efb37746c6"),
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.description, func(t *testing.T) {
+			fakeClient, err := tu.NewFakeClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fakePodLister := &fakePodLister{}
+			fakePodLister.AddPod(testPod)
+
+			nrtCache := mustOverReserve(t, fakeClient, fakePodLister)
+			for _, obj := range makeDefaultTestTopology() {
+				nrtCache.Store().Update(obj)
+			}
+			if tcase.dirty {
+				nrtCache.NodeMaybeOverReserved("node1", testPod)
+			}
+
+			nrtCache.ResyncNode(tcase.nrt)
+
+			expectedDirty := 0
+			if tcase.dirty && !tcase.expectFlush {
+				expectedDirty = 1
+			}
+			if dirtyNodes := nrtCache.GetDesyncedNodes(klog.Background()); dirtyNodes.Len() != expectedDirty {
+				t.Errorf("unexpected dirty nodes after resync: %v", dirtyNodes)
+			}
+			nrtObj, _ := nrtCache.GetCachedNRTCopy(context.Background(), "node1", testPod)
+			if flushed := isNRTEqual(nrtObj, tcase.nrt); flushed != tcase.expectFlush {
+				t.Errorf("flushed=%v expected=%v", flushed, tcase.expectFlush)
+			}
+		})
+	}
+}
+
 func isNRTEqual(a, b *topologyv1alpha2.NodeResourceTopology) bool {
 	return equality.Semantic.DeepDerivative(a.Zones, b.Zones) &&
 		equality.Semantic.DeepDerivative(a.TopologyPolicies, b.TopologyPolicies) &&
@@ -55,3 +55,6 @@ func (pt Passthrough) NodeHasForeignPods(nodeName string, pod *corev1.Pod)     {
 func (pt Passthrough) ReserveNodeResources(nodeName string, pod *corev1.Pod)   {}
 func (pt Passthrough) UnreserveNodeResources(nodeName string, pod *corev1.Pod) {}
 func (pt Passthrough) PostBind(nodeName string, pod *corev1.Pod)               {}
+
+func (pt Passthrough) ReserveNodeNUMAResources(nodeName string, pod *corev1.Pod, reservation NUMAReservation) {
+}
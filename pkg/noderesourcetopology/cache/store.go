@@ -25,6 +25,7 @@ import (
 	"github.com/go-logr/logr"
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 	topologyv1alpha2attr "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/attribute"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/numanode"
 	"github.com/k8stopologyawareschedwg/podfingerprint"
 
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
@@ -79,26 +80,39 @@ func (nrs *nrtStore) Update(nrt *topologyv1alpha2.NodeResourceTopology) {
 type resourceStore struct {
 	// key: namespace + "/" name
 	data map[string]corev1.ResourceList
-	lh   logr.Logger
+	// numaData holds the resources reserved on specific NUMA zones, which are not in data. key: namespace + "/" name
+	numaData map[string]NUMAReservation
+	lh       logr.Logger
 }
 
 func newResourceStore(lh logr.Logger) *resourceStore {
 	return &resourceStore{
-		data: make(map[string]corev1.ResourceList),
-		lh:   lh,
+		data:     make(map[string]corev1.ResourceList),
+		numaData: make(map[string]NUMAReservation),
+		lh:       lh,
 	}
 }
 
 func (rs *resourceStore) String() string {
 	var sb strings.Builder
 	for podKey, podRes := range rs.data {
-		sb.WriteString(podKey + "::[" + stringify.ResourceList(podRes) + "];")
+		sb.WriteString(podKey + "::[" + stringify.ResourceList(podRes) + "]")
+		if reservation, ok := rs.numaData[podKey]; ok {
+			sb.WriteString("::{" + reservation.String() + "}")
+		}
+		sb.WriteString(";")
 	}
 	return sb.String()
 }
 
 // AddPod returns true if updating existing pod, false if adding for the first time
 func (rs *resourceStore) AddPod(pod *corev1.Pod) bool {
+	return rs.AddPodWithNUMAReservation(pod, nil)
+}
+
+// AddPodWithNUMAReservation is like AddPod, but the resources in the reservation are tracked on their NUMA zones only.
+// The resources of the pod exceeding the reservation are tracked like AddPod does.
+func (rs *resourceStore) AddPodWithNUMAReservation(pod *corev1.Pod, reservation NUMAReservation) bool {
 	key := pod.Namespace + "/" + pod.Name
 	_, ok := rs.data[key]
 	if ok {
@@ -106,6 +120,12 @@ func (rs *resourceStore) AddPod(pod *corev1.Pod) bool {
 		rs.lh.V(4).Info("updating existing entry", "key", key)
 	}
 	resData := util.GetPodEffectiveRequest(pod)
+	delete(rs.numaData, key)
+	if len(reservation) > 0 {
+		resData = subtractReservation(resData, reservation)
+		rs.numaData[key] = reservation.DeepCopy()
+		rs.lh.V(5).Info("resourcestore ADD NUMA", "reservation", rs.numaData[key].String())
+	}
 	rs.lh.V(5).Info("resourcestore ADD", stringify.ResourceListToLoggable(resData)...)
 	rs.data[key] = resData
 	return ok
@@ -121,11 +141,12 @@ func (rs *resourceStore) DeletePod(pod *corev1.Pod) bool {
 	}
 	rs.lh.V(5).Info("resourcestore DEL", stringify.ResourceListToLoggable(rs.data[key])...)
 	delete(rs.data, key)
+	delete(rs.numaData, key)
 	return ok
 }
 
 // UpdateNRT updates the provided Node Resource Topology object with the resources tracked in this store,
-// performing pessimistic overallocation across all the NUMA zones.
+// performing pessimistic overallocation across all the NUMA zones, except for the resources reserved on specific NUMA zones.
 func (rs *resourceStore) UpdateNRT(nrt *topologyv1alpha2.NodeResourceTopology, logKeysAndValues ...any) {
 	for key, res := range rs.data {
 		// We cannot predict on which Zone the workload will be placed.
@@ -133,30 +154,69 @@ func (rs *resourceStore) UpdateNRT(nrt *topologyv1alpha2.NodeResourceTopology, l
 		// choice is to decrement the available resources from *all* the zones.
 		// This can cause false negatives, but will never cause false positives,
 		// which are much worse.
+		for zi := 0; zi < len(nrt.Zones); zi++ {
+			rs.subtractFromZone(nrt, &nrt.Zones[zi], key, res, logKeysAndValues...)
+		}
+	}
+	for key, reservation := range rs.numaData {
+		// the filter aligned these resources to known zones, so we can decrement them
+		// where the kubelet is expected to allocate them.
 		for zi := 0; zi < len(nrt.Zones); zi++ {
 			zone := &nrt.Zones[zi] // shortcut
-			for ri := 0; ri < len(zone.Resources); ri++ {
-				zr := &zone.Resources[ri] // shortcut
-				qty, ok := res[corev1.ResourceName(zr.Name)]
-				if !ok {
-					// this is benign; it is totally possible some resources are not
-					// available on some zones (think PCI devices), hence we don't
-					// even report this error, being an expected condition
-					continue
-				}
-				if zr.Available.Cmp(qty) < 0 {
-					// this should happen rarely, and it is likely caused by
-					// a bug elsewhere.
-					logKeysAndValues = append(logKeysAndValues, "zone", zr.Name, logging.KeyNode, nrt.Name, "available", zr.Available, "requestor", key, "quantity", qty.String())
-					rs.lh.V(3).Info("cannot decrement resource", logKeysAndValues...)
-					zr.Available = resource.Quantity{}
-					continue
-				}
-
-				zr.Available.Sub(qty)
+			numaID, err := numanode.NameToID(zone.Name)
+			if err != nil {
+				continue
+			}
+			res, ok := reservation[numaID]
+			if !ok {
+				continue
+			}
+			rs.subtractFromZone(nrt, zone, key, res, logKeysAndValues...)
+		}
+	}
+}
+
+func (rs *resourceStore) subtractFromZone(nrt *topologyv1alpha2.NodeResourceTopology, zone *topologyv1alpha2.Zone, key string, res corev1.ResourceList, logKeysAndValues ...any) {
+	for ri := 0; ri < len(zone.Resources); ri++ {
+		zr := &zone.Resources[ri] // shortcut
+		qty, ok := res[corev1.ResourceName(zr.Name)]
+		if !ok {
+			// this is benign; it is totally possible some resources are not
+			// available on some zones (think PCI devices), hence we don't
+			// even report this error, being an expected condition
+			continue
+		}
+		if zr.Available.Cmp(qty) < 0 {
+			// this should happen rarely, and it is likely caused by
+			// a bug elsewhere.
+			logKeysAndValues = append(logKeysAndValues, "zone", zr.Name, logging.KeyNode, nrt.Name, "available", zr.Available, "requestor", key, "quantity", qty.String())
+			rs.lh.V(3).Info("cannot decrement resource", logKeysAndValues...)
+			zr.Available = resource.Quantity{}
+			continue
+		}
+
+		zr.Available.Sub(qty)
+	}
+}
+
+// subtractReservation returns the resources of the pod not covered by the reservation
+func subtractReservation(res corev1.ResourceList, reservation NUMAReservation) corev1.ResourceList {
+	remaining := res.DeepCopy()
+	for _, numaRes := range reservation {
+		for name, qty := range numaRes {
+			rem, ok := remaining[name]
+			if !ok {
+				continue
+			}
+			rem.Sub(qty)
+			if rem.Sign() <= 0 {
+				delete(remaining, name)
+				continue
 			}
+			remaining[name] = rem
 		}
 	}
+	return remaining
 }
 
 type counter map[string]int
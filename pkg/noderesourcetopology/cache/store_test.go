@@ -565,6 +565,113 @@ func TestResourceStoreUpdate(t *testing.T) {
 	}
 }
 
+func TestResourceStoreUpdateNUMAReservation(t *testing.T) {
+	nrt := &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node"},
+		TopologyPolicies: []string{string(topologyv1alpha2.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha2.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+					MakeTopologyResInfo(nicName, "8", "8"),
+				},
+			},
+		},
+	}
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:           resource.MustParse("16"),
+							corev1.ResourceMemory:        resource.MustParse("4Gi"),
+							corev1.ResourceName(nicName): resource.MustParse("2"),
+						},
+					},
+				},
+				{
+					Name: "cnt-1",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("2"),
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// cnt-0 aligned on NUMA 1, cnt-1 not recorded: its resources must be overallocated on all the zones.
+	reservation := NUMAReservation{
+		1: corev1.ResourceList{
+			corev1.ResourceCPU:           resource.MustParse("16"),
+			corev1.ResourceMemory:        resource.MustParse("4Gi"),
+			corev1.ResourceName(nicName): resource.MustParse("2"),
+		},
+	}
+
+	rs := newResourceStore(klog.Background())
+	existed := rs.AddPodWithNUMAReservation(&pod, reservation)
+	if existed {
+		t.Fatalf("replacing a pod into a empty resourceStore")
+	}
+
+	rs.UpdateNRT(nrt, "logID", "testResourceStoreUpdateNUMAReservation")
+
+	expected := []struct {
+		zone     int
+		resName  string
+		quantity string
+	}{
+		{zone: 0, resName: cpu, quantity: "18"},
+		{zone: 0, resName: memory, quantity: "30Gi"},
+		{zone: 1, resName: cpu, quantity: "2"},
+		{zone: 1, resName: memory, quantity: "26Gi"},
+		{zone: 1, resName: nicName, quantity: "6"},
+	}
+	for _, exp := range expected {
+		resInfo := findResourceInfo(nrt.Zones[exp.zone].Resources, exp.resName)
+		if resInfo == nil {
+			t.Fatalf("expected resource %q on zone %d, but missing", exp.resName, exp.zone)
+		}
+		if resInfo.Available.Cmp(resource.MustParse(exp.quantity)) != 0 {
+			t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", exp.resName, exp.zone, exp.quantity, resInfo.Available)
+		}
+	}
+
+	existed = rs.AddPod(&pod)
+	if !existed {
+		t.Fatalf("added pod twice")
+	}
+	if _, ok := rs.numaData["ns-0/pod-0"]; ok {
+		t.Errorf("stale NUMA reservation after re-adding the pod without reservation")
+	}
+	rs.AddPodWithNUMAReservation(&pod, reservation)
+	rs.DeletePod(&pod)
+	if len(rs.data) != 0 || len(rs.numaData) != 0 {
+		t.Errorf("unexpected data left after deleting the pod: %s", rs.String())
+	}
+}
+
 func TestCheckPodFingerprintForNode(t *testing.T) {
 	tcases := []struct {
 		description string
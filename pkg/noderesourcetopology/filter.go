@@ -28,6 +28,7 @@ import (
 
 	"github.com/go-logr/logr"
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/resourcerequests"
@@ -41,7 +42,7 @@ const highestNUMAID = 8
 
 type PolicyHandler func(pod *v1.Pod, zoneMap topologyv1alpha2.ZoneList) *framework.Status
 
-func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo, managers nodeconfig.ResourceManagers, reservation *numaReservation) *framework.Status {
 	lh.V(5).Info("container level single NUMA node handler")
 
 	// prepare NUMANodes list from zoneMap
@@ -53,13 +54,37 @@ func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topology
 
 	// the init containers are running SERIALLY and BEFORE the normal containers.
 	// https://kubernetes.io/docs/concepts/workloads/pods/init-containers/#understanding-init-containers
-	// therefore, we don't need to accumulate their resources together
+	// therefore, we don't need to accumulate their resources together.
+	// The restartable init containers (sidecars) are the exception: they keep running alongside the
+	// init containers started after them and the normal containers, so their resources are accumulated.
+	// https://kubernetes.io/docs/concepts/workloads/pods/sidecar-containers/
 	for _, initContainer := range pod.Spec.InitContainers {
-		// TODO: handle sidecar explicitely (new kind)
+		if util.IsRestartableInitContainer(&initContainer) {
+			clh := lh.WithValues(logging.KeyContainer, initContainer.Name, logging.KeyContainerKind, logging.KindContainerSidecar)
+			clh.V(6).Info("container requests", stringify.ResourceListToLoggable(initContainer.Resources.Requests)...)
+
+			requests := resourcerequests.NUMAAligned(qos, initContainer.Resources.Requests, managers)
+			numaID, match := resourcesAvailableInAnyNUMANodes(clh, nodes, requests, qos, nodeInfo)
+			if !match {
+				clh.V(2).Info("cannot align container")
+				return framework.NewStatus(framework.Unschedulable, "cannot align sidecar container")
+			}
+
+			err := subtractResourcesFromNUMANodeList(clh, nodes, numaID, qos, requests)
+			if err != nil {
+				// this is an internal error which should never happen
+				return framework.NewStatus(framework.Error, "inconsistent resource accounting", err.Error())
+			}
+			reservation.add(numaID, requests)
+			clh.V(4).Info("container aligned", "numaCell", numaID)
+			continue
+		}
+
 		clh := lh.WithValues(logging.KeyContainer, initContainer.Name, logging.KeyContainerKind, logging.KindContainerInit)
 		clh.V(6).Info("desired resources", stringify.ResourceListToLoggable(initContainer.Resources.Requests)...)
 
-		_, match := resourcesAvailableInAnyNUMANodes(clh, nodes, initContainer.Resources.Requests, qos, nodeInfo)
+		requests := resourcerequests.NUMAAligned(qos, initContainer.Resources.Requests, managers)
+		_, match := resourcesAvailableInAnyNUMANodes(clh, nodes, requests, qos, nodeInfo)
 		if !match {
 			// we can't align init container, so definitely we can't align a pod
 			clh.V(2).Info("cannot align container")
@@ -71,7 +96,8 @@ func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topology
 		clh := lh.WithValues(logging.KeyContainer, container.Name, logging.KeyContainerKind, logging.KindContainerApp)
 		clh.V(6).Info("container requests", stringify.ResourceListToLoggable(container.Resources.Requests)...)
 
-		numaID, match := resourcesAvailableInAnyNUMANodes(clh, nodes, container.Resources.Requests, qos, nodeInfo)
+		requests := resourcerequests.NUMAAligned(qos, container.Resources.Requests, managers)
+		numaID, match := resourcesAvailableInAnyNUMANodes(clh, nodes, requests, qos, nodeInfo)
 		if !match {
 			// we can't align container, so definitely we can't align a pod
 			clh.V(2).Info("cannot align container")
@@ -80,11 +106,12 @@ func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topology
 
 		// subtract the resources requested by the container from the given NUMA.
 		// this is necessary, so we won't allocate the same resources for the upcoming containers
-		err := subtractResourcesFromNUMANodeList(clh, nodes, numaID, qos, container.Resources.Requests)
+		err := subtractResourcesFromNUMANodeList(clh, nodes, numaID, qos, requests)
 		if err != nil {
 			// this is an internal error which should never happen
 			return framework.NewStatus(framework.Error, "inconsistent resource accounting", err.Error())
 		}
+		reservation.add(numaID, requests)
 		clh.V(4).Info("container aligned", "numaCell", numaID)
 	}
 	return nil
@@ -160,10 +187,10 @@ func resourcesAvailableInAnyNUMANodes(lh logr.Logger, numaNodes NUMANodeList, re
 	return numaID, ret
 }
 
-func singleNUMAPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+func singleNUMAPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo, managers nodeconfig.ResourceManagers, reservation *numaReservation) *framework.Status {
 	lh.V(5).Info("pod level single NUMA node handler")
 
-	resources := util.GetPodEffectiveRequest(pod)
+	resources := resourcerequests.NUMAAlignedForPod(pod, managers)
 
 	nodes := createNUMANodeList(lh, zones)
 
@@ -176,11 +203,26 @@ func singleNUMAPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alph
 		lh.V(2).Info("cannot align pod", "name", pod.Name)
 		return framework.NewStatus(framework.Unschedulable, "cannot align pod")
 	}
+	reservation.add(numaID, resources)
 	lh.V(4).Info("all container placed", "numaCell", numaID)
 	return nil
 }
 
-// Filter Now only single-numa-node supported
+// PreFilter creates the state the filter records the NUMA zones it aligns the pod resources to in,
+// before the nodes are filtered in parallel.
+func (tm *TopologyMatch) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	if tm.reserveNUMAZones {
+		cycleState.Write(NUMAReservationKey, &NUMAReservationStateData{nodes: make(map[string]nrtcache.NUMAReservation)})
+	}
+	return nil, nil
+}
+
+// PreFilterExtensions returns nil, as the plugin has no AddPod/RemovePod extensions
+func (tm *TopologyMatch) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// Filter supports the single-numa-node and restricted policies
 func (tm *TopologyMatch) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
 	if nodeInfo.Node() == nil {
 		return framework.NewStatus(framework.Error, "node not found")
@@ -206,29 +248,80 @@ func (tm *TopologyMatch) Filter(ctx context.Context, cycleState *framework.Cycle
 	}
 
 	conf := nodeconfig.TopologyManagerFromNodeResourceTopology(lh, nodeTopology)
+	managers := nodeconfig.ResourceManagersFromNodeResourceTopology(nodeTopology)
 
-	lh.V(4).Info("found nrt data", "object", stringify.NodeResourceTopologyResources(nodeTopology), "conf", conf.String())
+	lh.V(4).Info("found nrt data", "object", stringify.NodeResourceTopologyResources(nodeTopology), "conf", conf.String(), "managers", managers.String())
+
+	// the kubelet enforces the SMT alignment regardless of the Topology Manager policy
+	if status := smtAlignmentStatus(lh, pod, managers.CPU); status != nil {
+		return status
+	}
 
 	handler := filterHandlerFromTopologyManager(conf)
 	if handler == nil {
 		return nil
 	}
-	status := handler(lh, pod, nodeTopology.Zones, nodeInfo)
+	if numaNodes := len(createNUMANodeList(lh, nodeTopology.Zones)); numaNodes > conf.MaxNUMANodes() {
+		// the kubelet refuses to run the Topology Manager on such nodes
+		lh.V(2).Info("too many NUMA nodes", "count", numaNodes, "max", conf.MaxNUMANodes())
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "too many NUMA nodes for the topology manager")
+	}
+	var reservation *numaReservation
+	if tm.reserveNUMAZones {
+		reservation = newNUMAReservation()
+	}
+	status := handler(lh, pod, nodeTopology.Zones, nodeInfo, managers, reservation)
 	if status != nil {
 		tm.nrtCache.NodeMaybeOverReserved(nodeName, pod)
+		return status
+	}
+	if decided, ok := reservation.get(); ok {
+		lh.V(4).Info("NUMA zones decided", "reservation", decided.String())
+		if reservations, err := getNUMAReservationState(cycleState); err == nil {
+			reservations.record(nodeName, decided)
+		}
 	}
-	return status
+	return nil
 }
 
-func filterHandlerFromTopologyManager(conf nodeconfig.TopologyManager) filterFn {
-	if conf.Policy != kubeletconfig.SingleNumaNodeTopologyManagerPolicy {
-		return nil
-	}
-	if conf.Scope == kubeletconfig.PodTopologyManagerScope {
-		return singleNUMAPodLevelHandler
+// smtAlignmentStatus rejects the pods with containers whose exclusive CPUs don't make full physical cores,
+// which the kubelet rejects with the full-pcpus-only CPU Manager option
+func smtAlignmentStatus(lh logr.Logger, pod *v1.Pod, cpuManager nodeconfig.CPUManager) *framework.Status {
+	qos := v1qos.GetPodQOS(pod)
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		cpus, ok := container.Resources.Requests[v1.ResourceCPU]
+		if !ok || !resourcerequests.IsExclusive(qos, v1.ResourceCPU, cpus) {
+			continue
+		}
+		if !cpuManager.IsSMTAligned(cpus.Value()) {
+			lh.V(2).Info("cannot allocate full physical cores", logging.KeyContainer, container.Name, "cpus", cpus.Value(), "threadsPerCore", cpuManager.ThreadsPerCore)
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, "cannot allocate full physical cores")
+		}
 	}
-	if conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
-		return singleNUMAContainerLevelHandler
+	return nil
+}
+
+func filterHandlerFromTopologyManager(conf nodeconfig.TopologyManager) filterFn {
+	switch conf.Policy {
+	case kubeletconfig.SingleNumaNodeTopologyManagerPolicy:
+		if conf.Scope == kubeletconfig.PodTopologyManagerScope {
+			return singleNUMAPodLevelHandler
+		}
+		if conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
+			return singleNUMAContainerLevelHandler
+		}
+	case kubeletconfig.RestrictedTopologyManagerPolicy:
+		if conf.Scope == kubeletconfig.PodTopologyManagerScope {
+			return func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo, managers nodeconfig.ResourceManagers, reservation *numaReservation) *framework.Status {
+				return restrictedPodLevelHandler(lh, pod, zones, nodeInfo, conf, managers, reservation)
+			}
+		}
+		if conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
+			return func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo, managers nodeconfig.ResourceManagers, reservation *numaReservation) *framework.Status {
+				return restrictedContainerLevelHandler(lh, pod, zones, nodeInfo, conf, managers, reservation)
+			}
+		}
 	}
-	return nil // cannot happen
+	// the best-effort and none policies admit any pod
+	return nil
 }
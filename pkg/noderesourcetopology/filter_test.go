@@ -1179,6 +1179,308 @@ func TestNodeResourceTopologyMultiContainerContainerScope(t *testing.T) {
 	}
 }
 
+func TestNodeResourceTopologyMaxAllowableNUMANodes(t *testing.T) {
+	makeNRT := func(name string, attrs topologyv1alpha2.AttributeList) *topologyv1alpha2.NodeResourceTopology {
+		nrt := &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Attributes: append(topologyv1alpha2.AttributeList{
+				{Name: "topologyManagerPolicy", Value: "single-numa-node"},
+				{Name: "topologyManagerScope", Value: "pod"},
+			}, attrs...),
+		}
+		for numaID := 0; numaID < 12; numaID++ {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha2.Zone{
+				Name: fmt.Sprintf("node-%d", numaID),
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "4", "4"),
+					MakeTopologyResInfo(memory, "4Gi", "4Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+	nrts := []*topologyv1alpha2.NodeResourceTopology{
+		makeNRT("default", nil),
+		makeNRT("max-16", topologyv1alpha2.AttributeList{{Name: "topologyManagerOptionMaxAllowableNumaNodes", Value: "16"}}),
+	}
+
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatalf("failed to create fake client: %v", err)
+	}
+	for _, nrt := range nrts {
+		if err := fakeClient.Create(context.Background(), nrt.DeepCopy()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tm := TopologyMatch{
+		nrtCache: nrtcache.NewPassthrough(klog.Background(), fakeClient),
+	}
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+
+	tests := []struct {
+		nrt        *topologyv1alpha2.NodeResourceTopology
+		wantStatus *framework.Status
+	}{
+		{
+			nrt:        nrts[0],
+			wantStatus: framework.NewStatus(framework.UnschedulableAndUnresolvable, "too many NUMA nodes for the topology manager"),
+		},
+		{
+			nrt: nrts[1],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nrt.Name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNodeResourceTopologyResourceManagers(t *testing.T) {
+	makeNRT := func(name string, availableCPUs []string, attrs ...topologyv1alpha2.AttributeInfo) *topologyv1alpha2.NodeResourceTopology {
+		nrt := &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Attributes: attrs,
+		}
+		for numaID, available := range availableCPUs {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha2.Zone{
+				Name: fmt.Sprintf("node-%d", numaID),
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", available),
+					MakeTopologyResInfo(memory, "4Gi", "4Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+	singleNUMANode := []topologyv1alpha2.AttributeInfo{
+		{Name: "topologyManagerPolicy", Value: "single-numa-node"},
+		{Name: "topologyManagerScope", Value: "pod"},
+	}
+	restricted := []topologyv1alpha2.AttributeInfo{
+		{Name: "topologyManagerPolicy", Value: "restricted"},
+		{Name: "topologyManagerScope", Value: "pod"},
+	}
+	nrts := []*topologyv1alpha2.NodeResourceTopology{
+		makeNRT("static", []string{"4", "4"}, singleNUMANode...),
+		makeNRT("memory-none", []string{"4", "4"}, append(singleNUMANode,
+			topologyv1alpha2.AttributeInfo{Name: "memoryManagerPolicy", Value: "None"})...),
+		makeNRT("full-pcpus-only", []string{"4", "4"}, append(singleNUMANode,
+			topologyv1alpha2.AttributeInfo{Name: "cpuManagerOptionFullPcpusOnly", Value: "true"},
+			topologyv1alpha2.AttributeInfo{Name: "threadsPerCore", Value: "2"})...),
+		makeNRT("restricted", []string{"8", "2"}, restricted...),
+		makeNRT("restricted-distribute", []string{"8", "2"}, append(restricted,
+			topologyv1alpha2.AttributeInfo{Name: "cpuManagerOptionDistributeCpusAcrossNuma", Value: "true"})...),
+	}
+
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatalf("failed to create fake client: %v", err)
+	}
+	for _, nrt := range nrts {
+		if err := fakeClient.Create(context.Background(), nrt.DeepCopy()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tm := TopologyMatch{
+		nrtCache: nrtcache.NewPassthrough(klog.Background(), fakeClient),
+	}
+
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		nrt        *topologyv1alpha2.NodeResourceTopology
+		wantStatus *framework.Status
+	}{
+		{
+			name: "exclusive CPUs, static policies",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("6"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt:        nrts[0],
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod"),
+		},
+		{
+			name: "shared CPUs, static policies",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("5500m"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt: nrts[0],
+		},
+		{
+			name: "memory, static policies",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("6Gi"),
+			}),
+			nrt:        nrts[0],
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod"),
+		},
+		{
+			name: "memory, none memory manager policy",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("6Gi"),
+			}),
+			nrt: nrts[1],
+		},
+		{
+			name: "full physical cores",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt: nrts[2],
+		},
+		{
+			name: "partial physical core",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt:        nrts[2],
+			wantStatus: framework.NewStatus(framework.UnschedulableAndUnresolvable, "cannot allocate full physical cores"),
+		},
+		{
+			name: "CPUs across NUMA nodes",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt: nrts[3],
+		},
+		{
+			name: "CPUs distributed across NUMA nodes",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt:        nrts[4],
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), tt.pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNodeResourceTopologySidecarContainers(t *testing.T) {
+	makeNRT := func(name string, attrs ...topologyv1alpha2.AttributeInfo) *topologyv1alpha2.NodeResourceTopology {
+		nrt := &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Attributes: attrs,
+		}
+		for numaID := 0; numaID < 2; numaID++ {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha2.Zone{
+				Name: fmt.Sprintf("node-%d", numaID),
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "4"),
+					MakeTopologyResInfo(memory, "4Gi", "4Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+	nrts := []*topologyv1alpha2.NodeResourceTopology{
+		makeNRT("single-numa-node",
+			topologyv1alpha2.AttributeInfo{Name: "topologyManagerPolicy", Value: "single-numa-node"},
+			topologyv1alpha2.AttributeInfo{Name: "topologyManagerScope", Value: "container"}),
+		makeNRT("restricted",
+			topologyv1alpha2.AttributeInfo{Name: "topologyManagerPolicy", Value: "restricted"},
+			topologyv1alpha2.AttributeInfo{Name: "topologyManagerScope", Value: "container"}),
+	}
+
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatalf("failed to create fake client: %v", err)
+	}
+	for _, nrt := range nrts {
+		if err := fakeClient.Create(context.Background(), nrt.DeepCopy()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tm := TopologyMatch{
+		nrtCache: nrtcache.NewPassthrough(klog.Background(), fakeClient),
+	}
+
+	cpus := func(count ...string) []v1.ResourceList {
+		var rl []v1.ResourceList
+		for _, c := range count {
+			rl = append(rl, v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(c),
+				v1.ResourceMemory: resource.MustParse("256Mi"),
+			})
+		}
+		return rl
+	}
+
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		wantStatus *framework.Status
+	}{
+		{
+			name: "sidecar and container on different NUMA nodes",
+			pod:  makePod("sidecar-fit", withMultiInitContainers(cpus("2")), withSidecarInitContainers(0), withMultiContainers(cpus("3"))),
+		},
+		{
+			name:       "sidecar holding the CPUs of the containers",
+			pod:        makePod("sidecar-nofit", withMultiInitContainers(cpus("3")), withSidecarInitContainers(0), withMultiContainers(cpus("3", "3"))),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align container"),
+		},
+		{
+			name: "init container releasing the CPUs of the containers",
+			pod:  makePod("init-fit", withMultiInitContainers(cpus("3", "4")), withSidecarInitContainers(0), withMultiContainers(cpus("4"))),
+		},
+		{
+			name:       "sidecars holding the CPUs of the init container",
+			pod:        makePod("init-nofit", withMultiInitContainers(cpus("3", "3", "2")), withSidecarInitContainers(0, 1), withMultiContainers(cpus("1"))),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align init container"),
+		},
+		{
+			name:       "sidecar too large for a NUMA node",
+			pod:        makePod("sidecar-large", withMultiInitContainers(cpus("5")), withSidecarInitContainers(0), withMultiContainers(cpus("1"))),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align sidecar container"),
+		},
+	}
+	for _, nrt := range nrts {
+		for _, tt := range tests {
+			t.Run(nrt.Name+"/"+tt.name, func(t *testing.T) {
+				nodeInfo := framework.NewNodeInfo()
+				nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+				gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), tt.pod, nodeInfo)
+
+				if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+					t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+				}
+			})
+		}
+	}
+}
+
 func makeNodeFromNodeResourceTopology(nrt *topologyv1alpha2.NodeResourceTopology) *v1.Node {
 	res := makeResourceListFromZones(nrt.Zones)
 	return &v1.Node{
@@ -1240,6 +1542,16 @@ func withMultiInitContainers(resourcesList []v1.ResourceList) func(*v1.Pod) {
 	}
 }
 
+// withSidecarInitContainers turns the init containers at the given indexes into restartable init containers (sidecars)
+func withSidecarInitContainers(idxs ...int) func(*v1.Pod) {
+	return func(pod *v1.Pod) {
+		restartPolicy := v1.ContainerRestartPolicyAlways
+		for _, idx := range idxs {
+			pod.Spec.InitContainers[idx].RestartPolicy = &restartPolicy
+		}
+	}
+}
+
 func cloneResourceList(rl v1.ResourceList) v1.ResourceList {
 	res := make(v1.ResourceList)
 	for name, qty := range rl {
@@ -26,6 +26,7 @@ import (
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 	"gonum.org/v1/gonum/stat/combin"
 
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
@@ -34,7 +35,7 @@ const (
 	maxDistanceValue = 255
 )
 
-func leastNUMAContainerScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList) (int64, *framework.Status) {
+func leastNUMAContainerScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, conf nodeconfig.TopologyManager) (int64, *framework.Status) {
 	nodes := createNUMANodeList(lh, zones)
 	qos := v1qos.GetPodQOS(pod)
 
@@ -42,12 +43,12 @@ func leastNUMAContainerScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1a
 	allContainersMinAvgDistance := true
 	// the order how TopologyManager asks for hint is important so doing it in the same order
 	// https://github.com/kubernetes/kubernetes/blob/master/pkg/kubelet/cm/topologymanager/scope_container.go#L52
-	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+	for idx, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
 		// if a container requests only non NUMA just continue
 		if onlyNonNUMAResources(nodes, container.Resources.Requests) {
 			continue
 		}
-		numaNodes, isMinAvgDistance := numaNodesRequired(lh, qos, nodes, container.Resources.Requests)
+		numaNodes, isMinAvgDistance := numaNodesRequired(lh, qos, nodes, container.Resources.Requests, leastNUMAPreferClosest(conf))
 		// container's resources can't fit onto node, return MinNodeScore for whole pod
 		if numaNodes == nil {
 			// score plugin should be running after resource filter plugin so we should always find sufficient amount of NUMA nodes
@@ -63,6 +64,12 @@ func leastNUMAContainerScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1a
 			maxNUMANodesCount = numaNodes.Count()
 		}
 
+		// the regular init containers run to completion before the next container starts,
+		// so their resources are released and available for the upcoming containers
+		if !isLongLivedContainer(pod, idx) {
+			continue
+		}
+
 		// subtract the resources requested by the container from the given NUMA.
 		// this is necessary, so we won't allocate the same resources for the upcoming containers
 		subtractFromNUMAs(container.Resources.Requests, nodes, numaNodes.GetBits()...)
@@ -72,10 +79,10 @@ func leastNUMAContainerScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1a
 		return framework.MaxNodeScore, nil
 	}
 
-	return normalizeScore(maxNUMANodesCount, allContainersMinAvgDistance), nil
+	return normalizeScore(maxNUMANodesCount, allContainersMinAvgDistance, conf.MaxNUMANodes()), nil
 }
 
-func leastNUMAPodScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList) (int64, *framework.Status) {
+func leastNUMAPodScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, conf nodeconfig.TopologyManager) (int64, *framework.Status) {
 	nodes := createNUMANodeList(lh, zones)
 	qos := v1qos.GetPodQOS(pod)
 
@@ -85,7 +92,7 @@ func leastNUMAPodScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.
 		return framework.MaxNodeScore, nil
 	}
 
-	numaNodes, isMinAvgDistance := numaNodesRequired(lh, qos, nodes, resources)
+	numaNodes, isMinAvgDistance := numaNodesRequired(lh, qos, nodes, resources, leastNUMAPreferClosest(conf))
 	// pod's resources can't fit onto node, return MinNodeScore
 	if numaNodes == nil {
 		// score plugin should be running after resource filter plugin so we should always find sufficient amount of NUMA nodes
@@ -93,11 +100,12 @@ func leastNUMAPodScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.
 		return framework.MinNodeScore, nil
 	}
 
-	return normalizeScore(numaNodes.Count(), isMinAvgDistance), nil
+	return normalizeScore(numaNodes.Count(), isMinAvgDistance, conf.MaxNUMANodes()), nil
 }
 
-func normalizeScore(numaNodesCount int, isMinAvgDistance bool) int64 {
-	numaNodeScore := framework.MaxNodeScore / highestNUMAID
+// normalizeScore spreads the node scores over the maximum number of NUMA nodes the Topology Manager allows
+func normalizeScore(numaNodesCount int, isMinAvgDistance bool, maxNUMANodes int) int64 {
+	numaNodeScore := framework.MaxNodeScore / int64(maxNUMANodes)
 	score := framework.MaxNodeScore - int64(numaNodesCount)*numaNodeScore
 	if isMinAvgDistance {
 		// if distance between NUMA domains is optimal add half of numaNodeScore to make this node more favorable
@@ -161,13 +169,21 @@ func combineResources(numaNodes NUMANodeList, combination []int) v1.ResourceList
 	return resources
 }
 
+// leastNUMAPreferClosest returns whether the LeastNUMANodes scores reward the closest NUMA nodes. They did regardless
+// of the prefer-closest-numa-nodes option before it was reported, so they keep doing it unless the node reports the option.
+func leastNUMAPreferClosest(conf nodeconfig.TopologyManager) bool {
+	return conf.PreferClosest(true)
+}
+
 // numaNodesRequired returns bitmask with minimal NUMA nodes required to run given resources
 // or nil when resources can't be fitted onto the worker node
 // second value returned is a boolean indicating if bitmask is optimal from distance perspective
-func numaNodesRequired(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList) (bitmask.BitMask, bool) {
+// preferClosest reflects the prefer-closest-numa-nodes Topology Manager option: unless it's set, the kubelet
+// selects the lowest NUMA nodes among the minimal ones, regardless of their distance
+func numaNodesRequired(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList, preferClosest bool) (bitmask.BitMask, bool) {
 	for bitmaskLen := 1; bitmaskLen <= len(numaNodes); bitmaskLen++ {
 		numaNodesCombination := combin.Combinations(len(numaNodes), bitmaskLen)
-		suitableCombination, isMinDistance := findSuitableCombination(lh, qos, numaNodes, resources, numaNodesCombination)
+		suitableCombination, isMinDistance := findSuitableCombination(lh, qos, numaNodes, resources, numaNodesCombination, preferClosest)
 		// we have found suitable combination for given bitmaskLen
 		if suitableCombination != nil {
 			bm := bitmask.NewEmptyBitMask()
@@ -183,8 +199,9 @@ func numaNodesRequired(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeLis
 
 // findSuitableCombination returns combination from numaNodesCombination that can fit resources, otherwise return nil
 // second value returned is a boolean indicating if returned combination is optimal from distance perspective
-// this function will always return combination that provides minimal average distance between nodes in combination
-func findSuitableCombination(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList, numaNodesCombination [][]int) ([]int, bool) {
+// this function will return combination that provides minimal average distance between nodes in combination
+// if preferClosest is set, otherwise the first combination that can fit resources
+func findSuitableCombination(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList, numaNodesCombination [][]int, preferClosest bool) ([]int, bool) {
 	minAvgDistance := minAvgDistanceInCombinations(lh, numaNodes, numaNodesCombination)
 	var (
 		minDistanceCombination []int
@@ -200,6 +217,9 @@ func findSuitableCombination(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMAN
 
 		if resourcesFit {
 			distance := nodesAvgDistance(lh, numaNodes, combination...)
+			if !preferClosest {
+				return combination, distance == minAvgDistance
+			}
 			if distance == minAvgDistance {
 				// return early if we can fit resources into combination and provide minDistance
 				return combination, true
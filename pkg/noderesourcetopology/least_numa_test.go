@@ -25,6 +25,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager/bitmask"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
 )
 
 const (
@@ -684,7 +687,7 @@ func TestNUMANodesRequired(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			bm, isMinDistance := numaNodesRequired(klog.Background(), v1.PodQOSGuaranteed, tc.numaNodes, tc.podResources)
+			bm, isMinDistance := numaNodesRequired(klog.Background(), v1.PodQOSGuaranteed, tc.numaNodes, tc.podResources, leastNUMAPreferClosest(nodeconfig.TopologyManager{}))
 
 			if bm != nil && !bm.IsEqual(tc.expectedBitmask) {
 				t.Errorf("wrong bitmask expected: %d got: %d", tc.expectedBitmask, bm)
@@ -702,12 +705,64 @@ func NewTestBitmask(bits ...int) bitmask.BitMask {
 	return bm
 }
 
+func TestNUMANodesRequiredPreferClosest(t *testing.T) {
+	costs := map[int]int{0: 10, 1: 32, 2: 12, 3: 32}
+	numaNodes := NUMANodeList{}
+	for numaID := 0; numaID < 4; numaID++ {
+		numaNodes = append(numaNodes, NUMANode{
+			NUMAID:    numaID,
+			Resources: v1.ResourceList{v1.ResourceCPU: *resource.NewQuantity(8, resource.DecimalSI)},
+			Costs:     costs,
+		})
+	}
+	podResources := v1.ResourceList{v1.ResourceCPU: *resource.NewQuantity(12, resource.DecimalSI)}
+
+	testCases := []struct {
+		description         string
+		preferClosest       *bool
+		expectedBitmask     bitmask.BitMask
+		expectedMinDistance bool
+	}{
+		{
+			description:         "option not reported, closest NUMA nodes",
+			expectedBitmask:     NewTestBitmask(0, 2),
+			expectedMinDistance: true,
+		},
+		{
+			description:         "lowest NUMA nodes",
+			preferClosest:       ptr.To(false),
+			expectedBitmask:     NewTestBitmask(0, 1),
+			expectedMinDistance: false,
+		},
+		{
+			description:         "closest NUMA nodes",
+			preferClosest:       ptr.To(true),
+			expectedBitmask:     NewTestBitmask(0, 2),
+			expectedMinDistance: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			conf := nodeconfig.TopologyManager{PreferClosestNUMANodes: tc.preferClosest}
+			bm, isMinDistance := numaNodesRequired(klog.Background(), v1.PodQOSGuaranteed, numaNodes, podResources, leastNUMAPreferClosest(conf))
+			if !bm.IsEqual(tc.expectedBitmask) {
+				t.Errorf("Wrong bitmask expected: %v got: %v", tc.expectedBitmask, bm)
+			}
+			if isMinDistance != tc.expectedMinDistance {
+				t.Errorf("Wrong minDistance expected: %v got: %v", tc.expectedMinDistance, isMinDistance)
+			}
+		})
+	}
+}
+
 func TestNormalizeScore(t *testing.T) {
 	tcases := []struct {
 		description     string
 		score           int
 		expectedScore   int64
 		optimalDistance bool
+		maxNUMANodes    int
 	}{
 		{
 			description:   "1 numa node, non optimal distance",
@@ -742,11 +797,23 @@ func TestNormalizeScore(t *testing.T) {
 			expectedScore:   10,
 			optimalDistance: true,
 		},
+		{
+			description:   "2 numa nodes, non optimal distance, 16 numa nodes allowed",
+			score:         2,
+			expectedScore: 88,
+			maxNUMANodes:  16,
+		},
+		{
+			description:   "16 numa nodes, non optimal distance, 16 numa nodes allowed",
+			score:         16,
+			expectedScore: 4,
+			maxNUMANodes:  16,
+		},
 	}
 
 	for _, tc := range tcases {
 		t.Run(tc.description, func(t *testing.T) {
-			normalizedScore := normalizeScore(tc.score, tc.optimalDistance)
+			normalizedScore := normalizeScore(tc.score, tc.optimalDistance, nodeconfig.TopologyManager{MaxAllowableNUMANodes: tc.maxNUMANodes}.MaxNUMANodes())
 			if normalizedScore != tc.expectedScore {
 				t.Errorf("Expected normalizedScore to be %d not %d", tc.expectedScore, normalizedScore)
 			}
@@ -45,8 +45,9 @@ const (
 )
 
 const (
-	KindContainerInit string = "init"
-	KindContainerApp  string = "app"
+	KindContainerInit    string = "init"
+	KindContainerSidecar string = "sidecar"
+	KindContainerApp     string = "app"
 )
 
 const (
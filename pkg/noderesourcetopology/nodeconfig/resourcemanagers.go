@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconfig
+
+import (
+	"fmt"
+	"strconv"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+)
+
+const (
+	AttributeCPUManagerPolicy    = "cpuManagerPolicy"
+	AttributeMemoryManagerPolicy = "memoryManagerPolicy"
+
+	// the cpuManagerPolicyOptions, expanded like described in the README
+	AttributeCPUManagerOptionFullPCPUsOnly            = "cpuManagerOptionFullPcpusOnly"
+	AttributeCPUManagerOptionDistributeCPUsAcrossNUMA = "cpuManagerOptionDistributeCpusAcrossNuma"
+
+	// AttributeThreadsPerCore is not a kubelet configuration option but a property of the hardware,
+	// required to check the SMT alignment of the full-pcpus-only option
+	AttributeThreadsPerCore = "threadsPerCore"
+)
+
+// the kubelet doesn't export these constants
+const (
+	CPUManagerPolicyNone   = "none"
+	CPUManagerPolicyStatic = "static"
+
+	MemoryManagerPolicyNone   = "None"
+	MemoryManagerPolicyStatic = "Static"
+)
+
+func IsValidCPUManagerPolicy(policy string) bool {
+	return policy == CPUManagerPolicyNone || policy == CPUManagerPolicyStatic
+}
+
+func IsValidMemoryManagerPolicy(policy string) bool {
+	return policy == MemoryManagerPolicyNone || policy == MemoryManagerPolicyStatic
+}
+
+type CPUManager struct {
+	Policy string
+	// FullPCPUsOnly is set by the full-pcpus-only option: the kubelet rejects the containers
+	// whose exclusive CPUs don't make full physical cores
+	FullPCPUsOnly bool
+	// DistributeCPUsAcrossNUMA is set by the distribute-cpus-across-numa option: the kubelet spreads
+	// the exclusive CPUs of the containers requiring more than a NUMA node evenly across the NUMA nodes
+	DistributeCPUsAcrossNUMA bool
+	// ThreadsPerCore is the number of hardware threads of a physical core. Zero means unknown.
+	ThreadsPerCore int
+}
+
+type MemoryManager struct {
+	Policy string
+}
+
+// ResourceManagers is the configuration of the kubelet resource managers which align resources to NUMA nodes.
+// The device manager has no configuration, and always aligns the devices reporting a NUMA affinity.
+type ResourceManagers struct {
+	CPU    CPUManager
+	Memory MemoryManager
+}
+
+// ResourceManagersDefaults assumes the static policies, which the NodeResourceTopology producers
+// not reporting the policies are expected to run with, since they are required for any NUMA alignment.
+func ResourceManagersDefaults() ResourceManagers {
+	return ResourceManagers{
+		CPU: CPUManager{
+			Policy: CPUManagerPolicyStatic,
+		},
+		Memory: MemoryManager{
+			Policy: MemoryManagerPolicyStatic,
+		},
+	}
+}
+
+func ResourceManagersFromNodeResourceTopology(nodeTopology *topologyv1alpha2.NodeResourceTopology) ResourceManagers {
+	conf := ResourceManagersDefaults()
+	conf.updateFromAttributes(nodeTopology.Attributes)
+	return conf
+}
+
+// IsStatic returns true if the CPU Manager allocates exclusive CPUs, aligned to NUMA nodes
+func (conf CPUManager) IsStatic() bool {
+	return conf.Policy == CPUManagerPolicyStatic
+}
+
+// IsSMTAligned returns false if the kubelet would reject a container requesting the exclusive CPUs
+// because they don't make full physical cores
+func (conf CPUManager) IsSMTAligned(cpus int64) bool {
+	if !conf.IsStatic() || !conf.FullPCPUsOnly || conf.ThreadsPerCore <= 1 {
+		return true
+	}
+	return cpus%int64(conf.ThreadsPerCore) == 0
+}
+
+// IsStatic returns true if the Memory Manager pins the memory and the hugepages to NUMA nodes
+func (conf MemoryManager) IsStatic() bool {
+	return conf.Policy == MemoryManagerPolicyStatic
+}
+
+func (conf ResourceManagers) String() string {
+	return fmt.Sprintf("cpuManagerPolicy=%q fullPCPUsOnly=%v distributeCPUsAcrossNUMA=%v threadsPerCore=%d memoryManagerPolicy=%q",
+		conf.CPU.Policy, conf.CPU.FullPCPUsOnly, conf.CPU.DistributeCPUsAcrossNUMA, conf.CPU.ThreadsPerCore, conf.Memory.Policy)
+}
+
+func (conf ResourceManagers) Equal(other ResourceManagers) bool {
+	return conf == other
+}
+
+func (conf *ResourceManagers) updateFromAttributes(attrs topologyv1alpha2.AttributeList) {
+	for _, attr := range attrs {
+		if attr.Name == AttributeCPUManagerPolicy && IsValidCPUManagerPolicy(attr.Value) {
+			conf.CPU.Policy = attr.Value
+			continue
+		}
+		if attr.Name == AttributeMemoryManagerPolicy && IsValidMemoryManagerPolicy(attr.Value) {
+			conf.Memory.Policy = attr.Value
+			continue
+		}
+		if attr.Name == AttributeCPUManagerOptionFullPCPUsOnly {
+			if val, err := strconv.ParseBool(attr.Value); err == nil {
+				conf.CPU.FullPCPUsOnly = val
+			}
+			continue
+		}
+		if attr.Name == AttributeCPUManagerOptionDistributeCPUsAcrossNUMA {
+			if val, err := strconv.ParseBool(attr.Value); err == nil {
+				conf.CPU.DistributeCPUsAcrossNUMA = val
+			}
+			continue
+		}
+		if attr.Name == AttributeThreadsPerCore {
+			if val, err := strconv.Atoi(attr.Value); err == nil && val > 0 {
+				conf.CPU.ThreadsPerCore = val
+			}
+			continue
+		}
+	}
+}
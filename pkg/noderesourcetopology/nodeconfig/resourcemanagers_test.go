@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconfig
+
+import (
+	"reflect"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+)
+
+func TestResourceManagersFromNRT(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    topologyv1alpha2.AttributeList
+		expected ResourceManagers
+	}{
+		{
+			name:     "nil",
+			attrs:    nil,
+			expected: ResourceManagersDefaults(),
+		},
+		{
+			name: "policies",
+			attrs: topologyv1alpha2.AttributeList{
+				{
+					Name:  "cpuManagerPolicy",
+					Value: "none",
+				},
+				{
+					Name:  "memoryManagerPolicy",
+					Value: "None",
+				},
+			},
+			expected: ResourceManagers{
+				CPU: CPUManager{
+					Policy: CPUManagerPolicyNone,
+				},
+				Memory: MemoryManager{
+					Policy: MemoryManagerPolicyNone,
+				},
+			},
+		},
+		{
+			name: "options",
+			attrs: topologyv1alpha2.AttributeList{
+				{
+					Name:  "cpuManagerOptionFullPcpusOnly",
+					Value: "true",
+				},
+				{
+					Name:  "cpuManagerOptionDistributeCpusAcrossNuma",
+					Value: "true",
+				},
+				{
+					Name:  "threadsPerCore",
+					Value: "2",
+				},
+			},
+			expected: ResourceManagers{
+				CPU: CPUManager{
+					Policy:                   CPUManagerPolicyStatic,
+					FullPCPUsOnly:            true,
+					DistributeCPUsAcrossNUMA: true,
+					ThreadsPerCore:           2,
+				},
+				Memory: MemoryManager{
+					Policy: MemoryManagerPolicyStatic,
+				},
+			},
+		},
+		{
+			name: "error-case",
+			attrs: topologyv1alpha2.AttributeList{
+				{
+					Name:  "cpuManagerPolicy",
+					Value: "Static",
+				},
+				{
+					Name:  "memoryManagerPolicy",
+					Value: "none",
+				},
+				{
+					Name:  "cpuManagerOptionFullPcpusOnly",
+					Value: "yes",
+				},
+				{
+					Name:  "threadsPerCore",
+					Value: "0",
+				},
+			},
+			expected: ResourceManagersDefaults(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResourceManagersFromNodeResourceTopology(&topologyv1alpha2.NodeResourceTopology{Attributes: tt.attrs})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("conf got=%+#v expected=%+#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsSMTAligned(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     CPUManager
+		cpus     int64
+		expected bool
+	}{
+		{
+			name:     "full cores",
+			conf:     CPUManager{Policy: CPUManagerPolicyStatic, FullPCPUsOnly: true, ThreadsPerCore: 2},
+			cpus:     4,
+			expected: true,
+		},
+		{
+			name:     "partial core",
+			conf:     CPUManager{Policy: CPUManagerPolicyStatic, FullPCPUsOnly: true, ThreadsPerCore: 2},
+			cpus:     3,
+			expected: false,
+		},
+		{
+			name:     "option disabled",
+			conf:     CPUManager{Policy: CPUManagerPolicyStatic, ThreadsPerCore: 2},
+			cpus:     3,
+			expected: true,
+		},
+		{
+			name:     "unknown threads per core",
+			conf:     CPUManager{Policy: CPUManagerPolicyStatic, FullPCPUsOnly: true},
+			cpus:     3,
+			expected: true,
+		},
+		{
+			name:     "none policy",
+			conf:     CPUManager{Policy: CPUManagerPolicyNone, FullPCPUsOnly: true, ThreadsPerCore: 2},
+			cpus:     3,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.conf.IsSMTAligned(tt.cpus)
+			if got != tt.expected {
+				t.Errorf("cpus=%d got=%v expected=%v", tt.cpus, got, tt.expected)
+			}
+		})
+	}
+}
@@ -18,8 +18,10 @@ package nodeconfig
 
 import (
 	"fmt"
+	"strconv"
 
 	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
+	"k8s.io/utils/ptr"
 
 	"github.com/go-logr/logr"
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
@@ -28,9 +30,16 @@ import (
 const (
 	AttributeScope  = "topologyManagerScope"
 	AttributePolicy = "topologyManagerPolicy"
+
+	// the topologyManagerPolicyOptions, expanded like described in the README
+	AttributeOptionPreferClosestNUMANodes = "topologyManagerOptionPreferClosestNumaNodes"
+	AttributeOptionMaxAllowableNUMANodes  = "topologyManagerOptionMaxAllowableNumaNodes"
 )
 
-// TODO: handle topologyManagerPolicyOptions added in k8s 1.26
+// DefaultMaxAllowableNUMANodes is the maximum number of NUMA nodes the kubelet Topology Manager allows
+// unless the max-allowable-numa-nodes option is set
+// https://kubernetes.io/docs/tasks/administer-cluster/topology-manager/#known-limitations
+const DefaultMaxAllowableNUMANodes = 8
 
 func IsValidScope(scope string) bool {
 	if scope == kubeletconfig.ContainerTopologyManagerScope || scope == kubeletconfig.PodTopologyManagerScope {
@@ -50,6 +59,13 @@ func IsValidPolicy(policy string) bool {
 type TopologyManager struct {
 	Scope  string
 	Policy string
+	// PreferClosestNUMANodes is set by the prefer-closest-numa-nodes option: among the narrowest hints,
+	// the kubelet picks the NUMA nodes with the lowest average distance instead of the lowest NUMA IDs.
+	// It is nil if the node doesn't report the option.
+	PreferClosestNUMANodes *bool
+	// MaxAllowableNUMANodes is set by the max-allowable-numa-nodes option: the kubelet Topology Manager
+	// refuses to run on nodes with more NUMA nodes. Zero means DefaultMaxAllowableNUMANodes.
+	MaxAllowableNUMANodes int
 }
 
 func TopologyManagerDefaults() TopologyManager {
@@ -69,8 +85,26 @@ func TopologyManagerFromNodeResourceTopology(lh logr.Logger, nodeTopology *topol
 	return conf
 }
 
+// MaxNUMANodes returns the maximum number of NUMA nodes the kubelet Topology Manager allows
+func (conf TopologyManager) MaxNUMANodes() int {
+	if conf.MaxAllowableNUMANodes == 0 {
+		return DefaultMaxAllowableNUMANodes
+	}
+	return conf.MaxAllowableNUMANodes
+}
+
+// PreferClosest returns whether the kubelet picks the closest NUMA nodes among the narrowest hints,
+// or defaultValue if the node doesn't report the prefer-closest-numa-nodes option
+func (conf TopologyManager) PreferClosest(defaultValue bool) bool {
+	return ptr.Deref(conf.PreferClosestNUMANodes, defaultValue)
+}
+
 func (conf TopologyManager) String() string {
-	return fmt.Sprintf("policy=%q scope=%q", conf.Policy, conf.Scope)
+	preferClosest := "unset"
+	if conf.PreferClosestNUMANodes != nil {
+		preferClosest = strconv.FormatBool(*conf.PreferClosestNUMANodes)
+	}
+	return fmt.Sprintf("policy=%q scope=%q preferClosestNUMANodes=%s maxAllowableNUMANodes=%d", conf.Policy, conf.Scope, preferClosest, conf.MaxNUMANodes())
 }
 
 func (conf TopologyManager) Equal(other TopologyManager) bool {
@@ -80,6 +114,12 @@ func (conf TopologyManager) Equal(other TopologyManager) bool {
 	if conf.Policy != other.Policy {
 		return false
 	}
+	if !ptr.Equal(conf.PreferClosestNUMANodes, other.PreferClosestNUMANodes) {
+		return false
+	}
+	if conf.MaxNUMANodes() != other.MaxNUMANodes() {
+		return false
+	}
 	return true
 }
 
@@ -93,7 +133,18 @@ func (conf *TopologyManager) updateFromAttributes(attrs topologyv1alpha2.Attribu
 			conf.Policy = attr.Value
 			continue
 		}
-		// TODO: handle topologyManagerPolicyOptions added in k8s 1.26
+		if attr.Name == AttributeOptionPreferClosestNUMANodes {
+			if val, err := strconv.ParseBool(attr.Value); err == nil {
+				conf.PreferClosestNUMANodes = &val
+			}
+			continue
+		}
+		if attr.Name == AttributeOptionMaxAllowableNUMANodes {
+			if val, err := strconv.Atoi(attr.Value); err == nil && val >= DefaultMaxAllowableNUMANodes {
+				conf.MaxAllowableNUMANodes = val
+			}
+			continue
+		}
 	}
 }
 
@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/klog/v2"
 	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
+	"k8s.io/utils/ptr"
 
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 )
@@ -193,6 +194,48 @@ func TestTopologyManagerEqual(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "prefer closest NUMA nodes diff",
+			tmA: TopologyManager{
+				Scope:                  "container",
+				Policy:                 "restricted",
+				PreferClosestNUMANodes: ptr.To(true),
+			},
+			tmB: TopologyManager{
+				Scope:  "container",
+				Policy: "restricted",
+			},
+			expected: false,
+		},
+		{
+			name: "prefer closest NUMA nodes unset",
+			tmA: TopologyManager{
+				Scope:                  "container",
+				Policy:                 "restricted",
+				PreferClosestNUMANodes: ptr.To(false),
+			},
+			tmB: TopologyManager{
+				Scope:  "container",
+				Policy: "restricted",
+			},
+			expected: false,
+		},
+		{
+			name: "max allowable NUMA nodes default",
+			tmA: TopologyManager{
+				MaxAllowableNUMANodes: DefaultMaxAllowableNUMANodes,
+			},
+			tmB:      TopologyManager{},
+			expected: true,
+		},
+		{
+			name: "max allowable NUMA nodes diff",
+			tmA: TopologyManager{
+				MaxAllowableNUMANodes: 16,
+			},
+			tmB:      TopologyManager{},
+			expected: false,
+		},
 		{
 			name: "scope matching, policy diff",
 			tmA: TopologyManager{
@@ -292,6 +335,42 @@ func TestConfigFromAttributes(t *testing.T) {
 				Scope:  kubeletconfig.PodTopologyManagerScope,
 			},
 		},
+		{
+			name: "options",
+			attrs: topologyv1alpha2.AttributeList{
+				{
+					Name:  "topologyManagerPolicy",
+					Value: "restricted",
+				},
+				{
+					Name:  "topologyManagerOptionPreferClosestNumaNodes",
+					Value: "true",
+				},
+				{
+					Name:  "topologyManagerOptionMaxAllowableNumaNodes",
+					Value: "16",
+				},
+			},
+			expected: TopologyManager{
+				Policy:                 kubeletconfig.RestrictedTopologyManagerPolicy,
+				PreferClosestNUMANodes: ptr.To(true),
+				MaxAllowableNUMANodes:  16,
+			},
+		},
+		{
+			name: "error-options",
+			attrs: topologyv1alpha2.AttributeList{
+				{
+					Name:  "topologyManagerOptionPreferClosestNumaNodes",
+					Value: "yes",
+				},
+				{
+					Name:  "topologyManagerOptionMaxAllowableNumaNodes",
+					Value: "4",
+				},
+			},
+			expected: TopologyManager{},
+		},
 		{
 			name: "error-case-1",
 			attrs: topologyv1alpha2.AttributeList{
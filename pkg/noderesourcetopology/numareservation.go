@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/go-logr/logr"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+)
+
+// NUMAReservationKey is the key in CycleState of the NUMA zones the filter aligned the pod resources to
+const NUMAReservationKey = Name + ".NUMAReservation"
+
+// NUMAReservationStateData holds the NUMA zones the filter aligned the pod resources to, by node name.
+// Only the nodes on which the decision of the filter is unambiguous are recorded.
+type NUMAReservationStateData struct {
+	nodes map[string]nrtcache.NUMAReservation
+	// for safe access to nodes, as the nodes are filtered in parallel
+	mu sync.Mutex
+}
+
+// Clone returns the state itself, which is created by PreFilter and written only by Filter
+func (s *NUMAReservationStateData) Clone() framework.StateData {
+	return s
+}
+
+func (s *NUMAReservationStateData) record(nodeName string, reservation nrtcache.NUMAReservation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes[nodeName] = reservation
+}
+
+func (s *NUMAReservationStateData) get(nodeName string) (nrtcache.NUMAReservation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reservation, ok := s.nodes[nodeName]
+	return reservation, ok
+}
+
+func getNUMAReservationState(cycleState *framework.CycleState) (*NUMAReservationStateData, error) {
+	stateData, err := cycleState.Read(NUMAReservationKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q from cycleState: %w", NUMAReservationKey, err)
+	}
+	reservations, ok := stateData.(*NUMAReservationStateData)
+	if !ok {
+		return nil, fmt.Errorf("invalid NUMA reservation state, got type %T", stateData)
+	}
+	return reservations, nil
+}
+
+// numaReservation collects the NUMA zones the filter handlers align the pod resources to on a node.
+// A nil numaReservation collects nothing, so the handlers don't need to check whether it is enabled.
+type numaReservation struct {
+	reservation nrtcache.NUMAReservation
+	// the decision spans more than one NUMA zone for a resource, so we can't tell how the kubelet splits it
+	ambiguous bool
+}
+
+func newNUMAReservation() *numaReservation {
+	return &numaReservation{
+		reservation: make(nrtcache.NUMAReservation),
+	}
+}
+
+// add records the resources are aligned to the given NUMA zone
+func (nr *numaReservation) add(numaID int, resources v1.ResourceList) {
+	if nr == nil {
+		return
+	}
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
+			continue
+		}
+		numaRes, ok := nr.reservation[numaID]
+		if !ok {
+			numaRes = make(v1.ResourceList)
+			nr.reservation[numaID] = numaRes
+		}
+		qty := numaRes[resource]
+		qty.Add(quantity)
+		numaRes[resource] = qty
+	}
+}
+
+// addAffinity records the resources are aligned to the NUMA nodes of the affinity, as indexes in numaNodes.
+// The resources whose affinity spans more than one NUMA node make the reservation ambiguous.
+func (nr *numaReservation) addAffinity(numaNodes NUMANodeList, resources v1.ResourceList, affinity map[v1.ResourceName][]int) {
+	if nr == nil {
+		return
+	}
+	for resource, nodes := range affinity {
+		if len(nodes) != 1 {
+			nr.ambiguous = true
+			continue
+		}
+		nr.add(numaNodes[nodes[0]].NUMAID, v1.ResourceList{resource: resources[resource]})
+	}
+}
+
+// get returns the collected reservation, and false if there is nothing to reserve on specific NUMA zones
+func (nr *numaReservation) get() (nrtcache.NUMAReservation, bool) {
+	if nr == nil || nr.ambiguous || len(nr.reservation) == 0 {
+		return nil, false
+	}
+	return nr.reservation, true
+}
+
+func getReserveMode(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache) apiconfig.CacheReserveMode {
+	var reserveMode apiconfig.CacheReserveMode
+	if cfg != nil && cfg.ReserveMode != nil {
+		reserveMode = *cfg.ReserveMode
+	} else { // explicitly set to nil?
+		reserveMode = apiconfig.CacheReservePessimistic
+		lh.Info("cache reserve mode value missing", "fallback", reserveMode)
+	}
+	return reserveMode
+}
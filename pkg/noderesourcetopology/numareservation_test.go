@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	podlisterv1 "k8s.io/client-go/listers/core/v1"
+	k8scache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/podprovider"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestNUMAReservation(t *testing.T) {
+	makeNRT := func(policy, scope string) *topologyv1alpha2.NodeResourceTopology {
+		nrt := &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+			Attributes: topologyv1alpha2.AttributeList{
+				{Name: "topologyManagerPolicy", Value: policy},
+				{Name: "topologyManagerScope", Value: scope},
+			},
+		}
+		for numaID := 0; numaID < 2; numaID++ {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha2.Zone{
+				Name: fmt.Sprintf("node-%d", numaID),
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "8"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+	requests := func(cpus string) []v1.ResourceList {
+		return []v1.ResourceList{
+			{
+				v1.ResourceCPU:    resource.MustParse(cpus),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		nrt              *topologyv1alpha2.NodeResourceTopology
+		reserveNUMAZones bool
+		reservedPod      *v1.Pod
+		wantReservation  nrtcache.NUMAReservation
+		pod              *v1.Pod
+		wantStatus       *framework.Status
+	}{
+		{
+			name:        "pessimistic reservation on all the NUMA zones",
+			nrt:         makeNRT("single-numa-node", "container"),
+			reservedPod: makePod("reserved", withMultiContainers(requests("4"))),
+			pod:         makePod("pod", withMultiContainers(requests("6"))),
+			wantStatus:  framework.NewStatus(framework.Unschedulable, "cannot align container"),
+		},
+		{
+			name:             "container scope reservation on the aligned NUMA zone",
+			nrt:              makeNRT("single-numa-node", "container"),
+			reserveNUMAZones: true,
+			reservedPod:      makePod("reserved", withMultiContainers(requests("4"))),
+			wantReservation: nrtcache.NUMAReservation{
+				0: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+			pod: makePod("pod", withMultiContainers(requests("6"))),
+		},
+		{
+			name:             "pod scope reservation on the aligned NUMA zone",
+			nrt:              makeNRT("single-numa-node", "pod"),
+			reserveNUMAZones: true,
+			reservedPod:      makePod("reserved", withMultiContainers(append(requests("2"), requests("2")...))),
+			wantReservation: nrtcache.NUMAReservation{
+				0: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+			pod: makePod("pod", withMultiContainers(requests("6"))),
+		},
+		{
+			name:             "ambiguous reservation spanning NUMA zones falls back to pessimistic",
+			nrt:              makeNRT("restricted", "container"),
+			reserveNUMAZones: true,
+			reservedPod:      makePod("reserved", withMultiContainers(requests("12"))),
+			pod:              makePod("pod", withMultiContainers(requests("4"))),
+			wantStatus:       framework.NewStatus(framework.Unschedulable, "cannot align container"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeClient, err := tu.NewFakeClient()
+			if err != nil {
+				t.Fatalf("failed to create fake client: %v", err)
+			}
+			if err := fakeClient.Create(ctx, tt.nrt.DeepCopy()); err != nil {
+				t.Fatal(err)
+			}
+			podLister := podlisterv1.NewPodLister(k8scache.NewIndexer(k8scache.MetaNamespaceKeyFunc, k8scache.Indexers{}))
+			nrtCache, err := nrtcache.NewOverReserve(ctx, klog.Background(), nil, fakeClient, podLister, podprovider.IsPodRelevantAlways)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tm := TopologyMatch{
+				nrtCache:         nrtCache,
+				reserveNUMAZones: tt.reserveNUMAZones,
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+
+			cycleState := framework.NewCycleState()
+			if _, status := tm.PreFilter(ctx, cycleState, tt.reservedPod); !status.IsSuccess() {
+				t.Fatalf("unexpected status prefiltering the reserved pod: %v", status)
+			}
+			if status := tm.Filter(ctx, cycleState, tt.reservedPod, nodeInfo); status != nil {
+				t.Fatalf("unexpected status filtering the reserved pod: %v", status)
+			}
+			var gotReservation nrtcache.NUMAReservation
+			if reservations, err := getNUMAReservationState(cycleState); err == nil {
+				gotReservation, _ = reservations.get(nodeInfo.Node().Name)
+			}
+			if gotReservation.String() != tt.wantReservation.String() {
+				t.Errorf("reservation does not match: %v, want: %v", gotReservation, tt.wantReservation)
+			}
+			if status := tm.Reserve(ctx, cycleState, tt.reservedPod, nodeInfo.Node().Name); !status.IsSuccess() {
+				t.Fatalf("unexpected status reserving the pod: %v", status)
+			}
+
+			gotStatus := tm.Filter(ctx, framework.NewCycleState(), tt.pod, nodeInfo)
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
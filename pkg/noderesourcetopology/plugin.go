@@ -30,6 +30,7 @@ import (
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
 
 	"github.com/go-logr/logr"
 	topologyapi "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology"
@@ -48,7 +49,7 @@ func init() {
 	utilruntime.Must(topologyv1alpha2.AddToScheme(scheme))
 }
 
-type filterFn func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status
+type filterFn func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo, managers nodeconfig.ResourceManagers, reservation *numaReservation) *framework.Status
 type scoringFn func(logr.Logger, *v1.Pod, topologyv1alpha2.ZoneList) (int64, *framework.Status)
 
 // TopologyMatch plugin which run simplified version of TopologyManager's admit handler
@@ -57,8 +58,11 @@ type TopologyMatch struct {
 	nrtCache            nrtcache.Interface
 	scoreStrategyFunc   scoreStrategyFn
 	scoreStrategyType   apiconfig.ScoringStrategyType
+	// reserveNUMAZones enables reserving the pod resources only on the NUMA zones the filter aligned them to
+	reserveNUMAZones bool
 }
 
+var _ framework.PreFilterPlugin = &TopologyMatch{}
 var _ framework.FilterPlugin = &TopologyMatch{}
 var _ framework.ReservePlugin = &TopologyMatch{}
 var _ framework.ScorePlugin = &TopologyMatch{}
@@ -105,11 +109,20 @@ func New(ctx context.Context, args runtime.Object, handle framework.Handle) (fra
 		return nil, err
 	}
 
+	// only the overreserve cache tracks the assumed resources per NUMA zone
+	_, isOverReserve := nrtCache.(*nrtcache.OverReserve)
+
 	topologyMatch := &TopologyMatch{
 		resourceToWeightMap: resToWeightMap,
 		nrtCache:            nrtCache,
 		scoreStrategyFunc:   strategy,
 		scoreStrategyType:   tcfg.ScoringStrategy.Type,
+		reserveNUMAZones:    isOverReserve && getReserveMode(lh, tcfg.Cache) == apiconfig.CacheReserveNUMAZones,
+	}
+	if topologyMatch.reserveNUMAZones {
+		// the plugin can't tell which extension points are enabled: without PreFilter, the filter has nowhere
+		// to record the NUMA zones and every reservation falls back to the Pessimistic mode
+		lh.Info("the NUMAZones reserve mode needs the PreFilter extension point of the plugin enabled, e.g. by multiPoint")
 	}
 
 	return topologyMatch, nil
@@ -18,6 +18,7 @@ package noderesourcetopology
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -36,6 +37,7 @@ import (
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/podprovider"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
 const (
@@ -65,6 +67,11 @@ func initNodeTopologyInformer(ctx context.Context, lh logr.Logger,
 		return nil, err
 	}
 
+	nrtcache.RegisterMetrics()
+	if err := serveCacheDebug(ctx, lh.WithName(logging.SubsystemNRTCache), tcfg.Cache, handle, nrtCache); err != nil {
+		return nil, err
+	}
+
 	initNodeTopologyForeignPodsDetection(lh, tcfg.Cache, handle, podSharedInformer, nrtCache)
 
 	resyncPeriod := time.Duration(tcfg.CacheResyncPeriodSeconds) * time.Second
@@ -75,6 +82,15 @@ func initNodeTopologyInformer(ctx context.Context, lh logr.Logger,
 	return nrtCache, nil
 }
 
+// serveCacheDebug serves the state of the cache on the debug address, if any, at a path of its own
+// for the profile, as the profiles enabling the plugin each have their cache and may share the address.
+func serveCacheDebug(ctx context.Context, lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache, handle framework.Handle, handler http.Handler) error {
+	if cfg == nil || cfg.DebugAddress == nil || *cfg.DebugAddress == "" {
+		return nil
+	}
+	return util.ServeDebug(ctx, lh, *cfg.DebugAddress, util.ProfileDebugPath(nrtcache.DebugNodesPath, handle), handler)
+}
+
 func initNodeTopologyForeignPodsDetection(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache, handle framework.Handle, podSharedInformer k8scache.SharedInformer, nrtCache *nrtcache.OverReserve) {
 	foreignPodsDetect := getForeignPodsDetectMode(lh, cfg)
 
@@ -101,6 +117,16 @@ func initNodeTopologyForeignPodsDetection(lh logr.Logger, cfg *apiconfig.NodeRes
 }
 
 func createNUMANodeList(lh logr.Logger, zones topologyv1alpha2.ZoneList) NUMANodeList {
+	return newNUMANodeList(lh, zones, extractResources)
+}
+
+// createAllocatableNUMANodeList is like createNUMANodeList, with the allocatable resources of the NUMA nodes
+// instead of the available ones.
+func createAllocatableNUMANodeList(lh logr.Logger, zones topologyv1alpha2.ZoneList) NUMANodeList {
+	return newNUMANodeList(lh, zones, extractAllocatableResources)
+}
+
+func newNUMANodeList(lh logr.Logger, zones topologyv1alpha2.ZoneList, extract func(topologyv1alpha2.Zone) corev1.ResourceList) NUMANodeList {
 	numaIDToZoneIDx := make([]int, maxNUMAId)
 	nodes := NUMANodeList{}
 	// filter non Node zones and create idToIdx lookup array
@@ -117,7 +143,7 @@ func createNUMANodeList(lh logr.Logger, zones topologyv1alpha2.ZoneList) NUMANod
 
 		numaIDToZoneIDx[numaID] = i
 
-		resources := extractResources(zone)
+		resources := extract(zone)
 		numaItems := []interface{}{"numaCell", numaID}
 		lh.V(6).Info("extracted NUMA resources", stringify.ResourceListToLoggableWithValues(numaItems, resources)...)
 		nodes = append(nodes, NUMANode{NUMAID: numaID, Resources: resources})
@@ -158,6 +184,19 @@ func extractResources(zone topologyv1alpha2.Zone) corev1.ResourceList {
 	return res
 }
 
+// extractAllocatableResources falls back to the capacity of the resources the zone doesn't report allocatable for
+func extractAllocatableResources(zone topologyv1alpha2.Zone) corev1.ResourceList {
+	res := make(corev1.ResourceList)
+	for _, resInfo := range zone.Resources {
+		if resInfo.Allocatable.IsZero() {
+			res[corev1.ResourceName(resInfo.Name)] = resInfo.Capacity.DeepCopy()
+			continue
+		}
+		res[corev1.ResourceName(resInfo.Name)] = resInfo.Allocatable.DeepCopy()
+	}
+	return res
+}
+
 func onlyNonNUMAResources(numaNodes NUMANodeList, resources corev1.ResourceList) bool {
 	for resourceName := range resources {
 		for _, node := range numaNodes {
@@ -170,6 +209,16 @@ func onlyNonNUMAResources(numaNodes NUMANodeList, resources corev1.ResourceList)
 	return true
 }
 
+// isLongLivedContainer tells if the container at index idx of the init containers followed by the app containers
+// of the pod, which is the order the Topology Manager admits them, holds its resources while the next containers
+// run: all the app containers and the restartable init containers (sidecars), but not the regular init containers.
+func isLongLivedContainer(pod *corev1.Pod, idx int) bool {
+	if idx >= len(pod.Spec.InitContainers) {
+		return true
+	}
+	return util.IsRestartableInitContainer(&pod.Spec.InitContainers[idx])
+}
+
 func getForeignPodsDetectMode(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache) apiconfig.ForeignPodsDetectMode {
 	var foreignPodsDetect apiconfig.ForeignPodsDetectMode
 	if cfg != nil && cfg.ForeignPodsDetect != nil {
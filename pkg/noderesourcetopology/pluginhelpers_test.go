@@ -17,12 +17,22 @@ limitations under the License.
 package noderesourcetopology
 
 import (
+	"context"
+	"io"
+	"net"
+	"net/http"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	testutil "sigs.k8s.io/scheduler-plugins/test/util"
 )
 
 func TestOnlyNonNUMAResources(t *testing.T) {
@@ -156,3 +166,49 @@ func TestGetForeignPodsDetectMode(t *testing.T) {
 		})
 	}
 }
+
+func TestServeCacheDebugPerProfile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	cfg := &apiconfig.NodeResourceTopologyCache{DebugAddress: &address}
+
+	// the profiles enabling the plugin serve the state of their caches on the same address
+	profiles := []string{"profile-a", "profile-b"}
+	for _, profile := range profiles {
+		fwk, err := testutil.NewFramework(ctx, []tf.RegisterPluginFunc{
+			tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		}, nil, profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(profile))
+		})
+		if err := serveCacheDebug(ctx, klog.Background(), cfg, fwk, handler); err != nil {
+			t.Fatalf("profile %v: cannot serve the cache state: %v", profile, err)
+		}
+	}
+
+	for _, profile := range profiles {
+		resp, err := http.Get("http://" + address + nrtcache.DebugNodesPath + "/" + profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || string(body) != profile {
+			t.Errorf("profile %v: got status %d and body %q", profile, resp.StatusCode, body)
+		}
+	}
+}
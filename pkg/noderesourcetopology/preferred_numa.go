@@ -0,0 +1,335 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"cmp"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/go-logr/logr"
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"gonum.org/v1/gonum/stat/combin"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/resourcerequests"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+func restrictedContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo, conf nodeconfig.TopologyManager, managers nodeconfig.ResourceManagers, reservation *numaReservation) *framework.Status {
+	lh.V(5).Info("container level restricted handler")
+
+	nodes := createNUMANodeList(lh, zones)
+	allocatableNodes := createAllocatableNUMANodeList(lh, zones)
+	qos := v1qos.GetPodQOS(pod)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes(lh, "container handler NUMA resources", nodeInfo.Node().Name, nodes)
+
+	// the init containers are running SERIALLY and BEFORE the normal containers, so their resources
+	// are not accumulated, except for the sidecars, like in singleNUMAContainerLevelHandler
+	for _, initContainer := range pod.Spec.InitContainers {
+		if util.IsRestartableInitContainer(&initContainer) {
+			clh := lh.WithValues(logging.KeyContainer, initContainer.Name, logging.KeyContainerKind, logging.KindContainerSidecar)
+			clh.V(6).Info("container requests", stringify.ResourceListToLoggable(initContainer.Resources.Requests)...)
+
+			requests := resourcerequests.NUMAAligned(qos, initContainer.Resources.Requests, managers)
+			if !resourcesAvailableOnNode(clh, requests, nodeInfo) {
+				return framework.NewStatus(framework.Unschedulable, "cannot align sidecar container")
+			}
+			affinity, match := preferredNUMAAffinity(clh, qos, nodes, allocatableNodes, requests, conf.PreferClosest(false), managers.CPU.DistributeCPUsAcrossNUMA)
+			if !match {
+				clh.V(2).Info("cannot align container")
+				return framework.NewStatus(framework.Unschedulable, "cannot align sidecar container")
+			}
+
+			subtractAffinityFromNUMAs(requests, nodes, affinity)
+			reservation.addAffinity(nodes, requests, affinity)
+			clh.V(4).Info("container aligned", "affinity", affinity)
+			continue
+		}
+
+		clh := lh.WithValues(logging.KeyContainer, initContainer.Name, logging.KeyContainerKind, logging.KindContainerInit)
+		clh.V(6).Info("desired resources", stringify.ResourceListToLoggable(initContainer.Resources.Requests)...)
+
+		requests := resourcerequests.NUMAAligned(qos, initContainer.Resources.Requests, managers)
+		if !resourcesAvailableOnNode(clh, requests, nodeInfo) {
+			return framework.NewStatus(framework.Unschedulable, "cannot align init container")
+		}
+		if _, match := preferredNUMAAffinity(clh, qos, nodes, allocatableNodes, requests, conf.PreferClosest(false), managers.CPU.DistributeCPUsAcrossNUMA); !match {
+			// we can't align init container, so definitely we can't align a pod
+			clh.V(2).Info("cannot align container")
+			return framework.NewStatus(framework.Unschedulable, "cannot align init container")
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		clh := lh.WithValues(logging.KeyContainer, container.Name, logging.KeyContainerKind, logging.KindContainerApp)
+		clh.V(6).Info("container requests", stringify.ResourceListToLoggable(container.Resources.Requests)...)
+
+		requests := resourcerequests.NUMAAligned(qos, container.Resources.Requests, managers)
+		if !resourcesAvailableOnNode(clh, requests, nodeInfo) {
+			return framework.NewStatus(framework.Unschedulable, "cannot align container")
+		}
+		affinity, match := preferredNUMAAffinity(clh, qos, nodes, allocatableNodes, requests, conf.PreferClosest(false), managers.CPU.DistributeCPUsAcrossNUMA)
+		if !match {
+			// we can't align container, so definitely we can't align a pod
+			clh.V(2).Info("cannot align container")
+			return framework.NewStatus(framework.Unschedulable, "cannot align container")
+		}
+
+		// subtract the resources requested by the container from the given NUMA nodes.
+		// this is necessary, so we won't allocate the same resources for the upcoming containers
+		subtractAffinityFromNUMAs(requests, nodes, affinity)
+		reservation.addAffinity(nodes, requests, affinity)
+		clh.V(4).Info("container aligned", "affinity", affinity)
+	}
+	return nil
+}
+
+func restrictedPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo, conf nodeconfig.TopologyManager, managers nodeconfig.ResourceManagers, reservation *numaReservation) *framework.Status {
+	lh.V(5).Info("pod level restricted handler")
+
+	resources := resourcerequests.NUMAAlignedForPod(pod, managers)
+
+	nodes := createNUMANodeList(lh, zones)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes(lh, "pod handler NUMA resources", nodeInfo.Node().Name, nodes)
+	lh.V(6).Info("pod desired resources", stringify.ResourceListToLoggable(resources)...)
+
+	if !resourcesAvailableOnNode(lh, resources, nodeInfo) {
+		return framework.NewStatus(framework.Unschedulable, "cannot align pod")
+	}
+	affinity, match := preferredNUMAAffinity(lh, v1qos.GetPodQOS(pod), nodes, createAllocatableNUMANodeList(lh, zones), resources, conf.PreferClosest(false), managers.CPU.DistributeCPUsAcrossNUMA)
+	if !match {
+		lh.V(2).Info("cannot align pod", "name", pod.Name)
+		return framework.NewStatus(framework.Unschedulable, "cannot align pod")
+	}
+	reservation.addAffinity(nodes, resources, affinity)
+	lh.V(4).Info("all container placed", "affinity", affinity)
+	return nil
+}
+
+// resourcesAvailableOnNode checks all the requested resources are reported at node level, like
+// resourcesAvailableInAnyNUMANodes does.
+func resourcesAvailableOnNode(lh logr.Logger, resources v1.ResourceList, nodeInfo *framework.NodeInfo) bool {
+	nodeResources := util.ResourceList(nodeInfo.Allocatable)
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
+			continue
+		}
+		if _, ok := nodeResources[resource]; !ok {
+			lh.V(2).Info("early verdict: cannot meet request", "resource", resource, "suitable", "false")
+			return false
+		}
+	}
+	return true
+}
+
+// preferredNUMAAffinity returns, for each requested resource aligned by the kubelet, the indexes in numaNodes of the
+// NUMA nodes it would be allocated from, and whether the hint the Topology Manager merges for the resources is preferred,
+// which the restricted policy requires to admit the pod.
+// Like the kubelet hint providers, a hint of a resource is preferred if it spans the minimal number of NUMA nodes whose
+// allocatable resources (allocatableNUMANodes) fit the request, and the available resources (numaNodes) of the NUMA nodes
+// fit the request. The merged hint is preferred if a preferred hint of every resource shares a NUMA node with the others.
+// With preferClosest, reflecting the prefer-closest-numa-nodes Topology Manager option, the hints of a resource spanning
+// the NUMA nodes with the lowest average distance are selected first. With distributeCPUs, reflecting the
+// distribute-cpus-across-numa CPU Manager option, the CPUs must be evenly spread across the NUMA nodes of the hints.
+func preferredNUMAAffinity(lh logr.Logger, qos v1.PodQOSClass, numaNodes, allocatableNUMANodes NUMANodeList, resources v1.ResourceList, preferClosest, distributeCPUs bool) (map[v1.ResourceName][]int, bool) {
+	hints := map[v1.ResourceName][][]int{}
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
+			lh.V(4).Info("ignoring zero-qty resource request", "resource", resource)
+			continue
+		}
+		if qos != v1.PodQOSGuaranteed && isNUMAAffineResource(resource) {
+			// not exclusively allocated, thus no hint for the resource
+			continue
+		}
+		request := v1.ResourceList{resource: quantity}
+		// non-native resources or ephemeral-storage may not expose NUMA affinity,
+		// but since they are available at node level, this is fine
+		if onlyNonNUMAResources(numaNodes, request) && isHostLevelResource(resource) {
+			lh.V(6).Info("resource available at host level (no NUMA affinity)", "resource", resource)
+			continue
+		}
+		resourceHints := preferredHints(lh, qos, numaNodes, allocatableNUMANodes, request, distributeCPUs)
+		if len(resourceHints) == 0 {
+			lh.V(2).Info("early verdict: no preferred hint", "resource", resource, "suitable", "false")
+			return nil, false
+		}
+		if preferClosest {
+			slices.SortStableFunc(resourceHints, func(a, b []int) int {
+				return cmp.Compare(nodesAvgDistance(lh, numaNodes, a...), nodesAvgDistance(lh, numaNodes, b...))
+			})
+		}
+		hints[resource] = resourceHints
+	}
+
+	affinity := make(map[v1.ResourceName][]int, len(hints))
+	if len(hints) == 0 {
+		return affinity, true
+	}
+	// according to TopologyManager, the preferred NUMA affinity is the narrowest one, with the lowest NUMA IDs first,
+	// so the first NUMA node shared by a hint of every resource is the one to be selected by Kubelet.
+	for nodeIdx := range numaNodes {
+		for resource, resourceHints := range hints {
+			idx := slices.IndexFunc(resourceHints, func(hint []int) bool {
+				return slices.Contains(hint, nodeIdx)
+			})
+			if idx == -1 {
+				break
+			}
+			affinity[resource] = resourceHints[idx]
+		}
+		if len(affinity) == len(hints) {
+			lh.V(2).Info("final verdict", "suitable", true, "numaCell", numaNodes[nodeIdx].NUMAID)
+			return affinity, true
+		}
+		clear(affinity)
+	}
+	lh.V(2).Info("final verdict", "suitable", false)
+	return nil, false
+}
+
+// preferredHints returns the combinations of NUMA nodes, as indexes in numaNodes, which can fit the request
+// and are as narrow as the narrowest combination of allocatableNUMANodes which can fit it.
+func preferredHints(lh logr.Logger, qos v1.PodQOSClass, numaNodes, allocatableNUMANodes NUMANodeList, request v1.ResourceList, distributeCPUs bool) [][]int {
+	for bitmaskLen := 1; bitmaskLen <= len(numaNodes); bitmaskLen++ {
+		numaNodesCombination := combin.Combinations(len(numaNodes), bitmaskLen)
+		if !slices.ContainsFunc(numaNodesCombination, func(combination []int) bool {
+			return combinationFits(lh, qos, allocatableNUMANodes, request, combination)
+		}) {
+			continue
+		}
+		var hints [][]int
+		for _, combination := range numaNodesCombination {
+			if combinationFits(lh, qos, numaNodes, request, combination) && (!distributeCPUs || cpusDistributable(numaNodes, request, combination)) {
+				hints = append(hints, combination)
+			}
+		}
+		return hints
+	}
+	return nil
+}
+
+func combinationFits(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList, combination []int) bool {
+	if !isValidCombineResources(numaNodes, resources, combination) {
+		return false
+	}
+	return checkResourcesFit(lh, qos, resources, combineResources(numaNodes, combination))
+}
+
+// cpusDistributable checks every NUMA node of the combination has its share of the requested CPUs available
+func cpusDistributable(numaNodes NUMANodeList, request v1.ResourceList, combination []int) bool {
+	cpus, ok := request[v1.ResourceCPU]
+	if !ok || len(combination) < 2 {
+		return true
+	}
+	share := cpus.Value() / int64(len(combination))
+	for _, nodeIndex := range combination {
+		available := numaNodes[nodeIndex].Resources[v1.ResourceCPU]
+		if available.Value() < share {
+			return false
+		}
+	}
+	return true
+}
+
+// subtractAffinityFromNUMAs subtracts each resource of the affinity from its NUMA nodes
+func subtractAffinityFromNUMAs(resources v1.ResourceList, numaNodes NUMANodeList, affinity map[v1.ResourceName][]int) {
+	for resource, nodes := range affinity {
+		subtractFromNUMAs(v1.ResourceList{resource: resources[resource]}, numaNodes, nodes...)
+	}
+}
+
+// preferredNUMAContainerScopeScore favors the nodes where every container gets a preferred NUMA affinity, which
+// the best-effort policy admits the pod without, and then the nodes requiring the least NUMA nodes.
+func preferredNUMAContainerScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, conf nodeconfig.TopologyManager, managers nodeconfig.ResourceManagers) (int64, *framework.Status) {
+	nodes := createNUMANodeList(lh, zones)
+	allocatableNodes := createAllocatableNUMANodeList(lh, zones)
+	qos := v1qos.GetPodQOS(pod)
+
+	var scores []int64
+	// the order how TopologyManager asks for hint is important so doing it in the same order
+	// https://github.com/kubernetes/kubernetes/blob/master/pkg/kubelet/cm/topologymanager/scope_container.go#L52
+	for idx, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		requests := resourcerequests.NUMAAligned(qos, container.Resources.Requests, managers)
+		// if a container requests only non NUMA just continue
+		if onlyNonNUMAResources(nodes, requests) {
+			continue
+		}
+		// the regular init containers release their resources before the next container starts
+		longLived := isLongLivedContainer(pod, idx)
+		if affinity, ok := preferredNUMAAffinity(lh, qos, nodes, allocatableNodes, requests, conf.PreferClosest(false), managers.CPU.DistributeCPUsAcrossNUMA); ok {
+			scores = append(scores, framework.MaxNodeScore)
+			if longLived {
+				subtractAffinityFromNUMAs(requests, nodes, affinity)
+			}
+			continue
+		}
+		numaNodes, isMinAvgDistance := numaNodesRequired(lh, qos, nodes, requests, leastNUMAPreferClosest(conf))
+		// container's resources can't fit onto node, return MinNodeScore for whole pod
+		if numaNodes == nil {
+			lh.Info("cannot calculate how many NUMA nodes are required", "container", container.Name)
+			return framework.MinNodeScore, nil
+		}
+		scores = append(scores, normalizeScore(numaNodes.Count(), isMinAvgDistance, conf.MaxNUMANodes()))
+		if longLived {
+			subtractFromNUMAs(requests, nodes, numaNodes.GetBits()...)
+		}
+	}
+
+	if len(scores) == 0 {
+		return framework.MaxNodeScore, nil
+	}
+	var sum int64
+	for _, score := range scores {
+		sum += score
+	}
+	return sum / int64(len(scores)), nil
+}
+
+// preferredNUMAPodScopeScore favors the nodes where the pod gets a preferred NUMA affinity, which the best-effort
+// policy admits the pod without, and then the nodes requiring the least NUMA nodes.
+func preferredNUMAPodScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, conf nodeconfig.TopologyManager, managers nodeconfig.ResourceManagers) (int64, *framework.Status) {
+	nodes := createNUMANodeList(lh, zones)
+	qos := v1qos.GetPodQOS(pod)
+
+	resources := resourcerequests.NUMAAlignedForPod(pod, managers)
+	// if a pod requests only non NUMA resources return max score
+	if onlyNonNUMAResources(nodes, resources) {
+		return framework.MaxNodeScore, nil
+	}
+	if _, ok := preferredNUMAAffinity(lh, qos, nodes, createAllocatableNUMANodeList(lh, zones), resources, conf.PreferClosest(false), managers.CPU.DistributeCPUsAcrossNUMA); ok {
+		return framework.MaxNodeScore, nil
+	}
+
+	numaNodes, isMinAvgDistance := numaNodesRequired(lh, qos, nodes, resources, leastNUMAPreferClosest(conf))
+	// pod's resources can't fit onto node, return MinNodeScore
+	if numaNodes == nil {
+		lh.Info("cannot calculate how many NUMA nodes are required")
+		return framework.MinNodeScore, nil
+	}
+	return normalizeScore(numaNodes.Count(), isMinAvgDistance, conf.MaxNUMANodes()), nil
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func makeNUMANodeList(resources ...v1.ResourceList) NUMANodeList {
+	nodes := NUMANodeList{}
+	for numaID, res := range resources {
+		nodes = append(nodes, NUMANode{NUMAID: numaID, Resources: res})
+	}
+	return nodes
+}
+
+func TestPreferredNUMAAffinity(t *testing.T) {
+	allocatable := makeNUMANodeList(
+		v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), gpuResource: resource.MustParse("2")},
+		v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), gpuResource: resource.MustParse("2")},
+	)
+	testCases := []struct {
+		description      string
+		qos              v1.PodQOSClass
+		numaNodes        NUMANodeList
+		resources        v1.ResourceList
+		expectedAffinity map[v1.ResourceName][]int
+		expectedMatch    bool
+	}{
+		{
+			description: "fit on the first NUMA node",
+			qos:         v1.PodQOSGuaranteed,
+			numaNodes:   allocatable,
+			resources:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			expectedAffinity: map[v1.ResourceName][]int{
+				v1.ResourceCPU: {0},
+			},
+			expectedMatch: true,
+		},
+		{
+			description: "fit on the second NUMA node",
+			qos:         v1.PodQOSGuaranteed,
+			numaNodes: makeNUMANodeList(
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), gpuResource: resource.MustParse("2")},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), gpuResource: resource.MustParse("2")},
+			),
+			resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), gpuResource: resource.MustParse("1")},
+			expectedAffinity: map[v1.ResourceName][]int{
+				v1.ResourceCPU: {1},
+				gpuResource:    {1},
+			},
+			expectedMatch: true,
+		},
+		{
+			description: "request spanning the minimal number of NUMA nodes",
+			qos:         v1.PodQOSGuaranteed,
+			numaNodes:   allocatable,
+			resources:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("12")},
+			expectedAffinity: map[v1.ResourceName][]int{
+				v1.ResourceCPU: {0, 1},
+			},
+			expectedMatch: true,
+		},
+		{
+			description: "request fitting only on more NUMA nodes than the minimal number",
+			qos:         v1.PodQOSGuaranteed,
+			numaNodes: makeNUMANodeList(
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), gpuResource: resource.MustParse("2")},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("3"), gpuResource: resource.MustParse("2")},
+			),
+			resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+		},
+		{
+			description: "resources fitting on distinct NUMA nodes",
+			qos:         v1.PodQOSGuaranteed,
+			numaNodes: makeNUMANodeList(
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), gpuResource: resource.MustParse("0")},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), gpuResource: resource.MustParse("2")},
+			),
+			resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), gpuResource: resource.MustParse("1")},
+		},
+		{
+			description: "burstable pod, only the device is aligned",
+			qos:         v1.PodQOSBurstable,
+			numaNodes: makeNUMANodeList(
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), gpuResource: resource.MustParse("0")},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), gpuResource: resource.MustParse("2")},
+			),
+			resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), gpuResource: resource.MustParse("1")},
+			expectedAffinity: map[v1.ResourceName][]int{
+				gpuResource: {1},
+			},
+			expectedMatch: true,
+		},
+		{
+			description:      "host level resource",
+			qos:              v1.PodQOSGuaranteed,
+			numaNodes:        allocatable,
+			resources:        v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+			expectedAffinity: map[v1.ResourceName][]int{},
+			expectedMatch:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			affinity, match := preferredNUMAAffinity(klog.Background(), tc.qos, tc.numaNodes, allocatable, tc.resources, false, false)
+			if match != tc.expectedMatch {
+				t.Errorf("wrong match: got %v, expected %v", match, tc.expectedMatch)
+			}
+			if !reflect.DeepEqual(affinity, tc.expectedAffinity) {
+				t.Errorf("wrong affinity: got %v, expected %v", affinity, tc.expectedAffinity)
+			}
+		})
+	}
+}
+
+func TestPreferredNUMAAffinityPreferClosest(t *testing.T) {
+	costs := map[int]int{0: 10, 1: 32, 2: 12, 3: 32}
+	numaNodes := NUMANodeList{}
+	for numaID := 0; numaID < 4; numaID++ {
+		numaNodes = append(numaNodes, NUMANode{
+			NUMAID:    numaID,
+			Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")},
+			Costs:     costs,
+		})
+	}
+	resources := v1.ResourceList{v1.ResourceCPU: resource.MustParse("12")}
+
+	for _, tc := range []struct {
+		preferClosest    bool
+		expectedAffinity []int
+	}{
+		{preferClosest: false, expectedAffinity: []int{0, 1}},
+		{preferClosest: true, expectedAffinity: []int{0, 2}},
+	} {
+		affinity, match := preferredNUMAAffinity(klog.Background(), v1.PodQOSGuaranteed, numaNodes, numaNodes, resources, tc.preferClosest, false)
+		if !match {
+			t.Fatalf("preferClosest=%v: expected a match", tc.preferClosest)
+		}
+		if !reflect.DeepEqual(affinity[v1.ResourceCPU], tc.expectedAffinity) {
+			t.Errorf("preferClosest=%v: wrong affinity: got %v, expected %v", tc.preferClosest, affinity[v1.ResourceCPU], tc.expectedAffinity)
+		}
+	}
+}
+
+func makeTwoNUMANodeTopology(name string, policy topologyv1alpha2.TopologyManagerPolicy, availableCPUs ...string) *topologyv1alpha2.NodeResourceTopology {
+	nrt := &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: name},
+		TopologyPolicies: []string{string(policy)},
+	}
+	for i, available := range availableCPUs {
+		nrt.Zones = append(nrt.Zones, topologyv1alpha2.Zone{
+			Name: []string{"node-0", "node-1"}[i],
+			Type: "Node",
+			Resources: topologyv1alpha2.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "8", available),
+				MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+			},
+		})
+	}
+	return nrt
+}
+
+func TestRestrictedFilter(t *testing.T) {
+	nrts := []*topologyv1alpha2.NodeResourceTopology{
+		makeTwoNUMANodeTopology("restricted-pod", topologyv1alpha2.RestrictedPodLevel, "2", "3"),
+		makeTwoNUMANodeTopology("restricted-container", topologyv1alpha2.RestrictedContainerLevel, "4", "3"),
+		makeTwoNUMANodeTopology("best-effort", topologyv1alpha2.BestEffortPodLevel, "2", "3"),
+	}
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatalf("failed to create fake client: %v", err)
+	}
+	for _, nrt := range nrts {
+		if err := fakeClient.Create(context.Background(), nrt.DeepCopy()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tm := TopologyMatch{
+		nrtCache: nrtcache.NewPassthrough(klog.Background(), fakeClient),
+	}
+
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		nrt        *topologyv1alpha2.NodeResourceTopology
+		wantStatus *framework.Status
+	}{
+		{
+			name: "pod scope, fit on a NUMA node",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt: nrts[0],
+		},
+		{
+			name: "pod scope, fit only on more NUMA nodes than required",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt:        nrts[0],
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod"),
+		},
+		{
+			name: "pod scope, memory spanning the minimal number of NUMA nodes",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("12Gi"),
+			}),
+			nrt: nrts[0],
+		},
+		{
+			name: "container scope, containers fit on distinct NUMA nodes",
+			pod: makePodByResourceLists(
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("3"), v1.ResourceMemory: resource.MustParse("1Gi")},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("3"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			),
+			nrt: nrts[1],
+		},
+		{
+			name: "container scope, second container fit only on more NUMA nodes than required",
+			pod: makePodByResourceLists(
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("3"), v1.ResourceMemory: resource.MustParse("1Gi")},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			),
+			nrt:        nrts[1],
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align container"),
+		},
+		{
+			name: "init container fit only on more NUMA nodes than required",
+			pod: makePod("init", withMultiInitContainers([]v1.ResourceList{
+				{v1.ResourceCPU: resource.MustParse("6"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			}), withMultiContainers([]v1.ResourceList{
+				{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			})),
+			nrt:        nrts[1],
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align init container"),
+		},
+		{
+			name: "best-effort policy admits misaligned pods",
+			pod: makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}),
+			nrt: nrts[2],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), tt.pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPreferredNUMAScore(t *testing.T) {
+	nrts := []*topologyv1alpha2.NodeResourceTopology{
+		makeTwoNUMANodeTopology("aligned", topologyv1alpha2.BestEffortPodLevel, "4", "3"),
+		makeTwoNUMANodeTopology("misaligned", topologyv1alpha2.BestEffortPodLevel, "2", "3"),
+		makeTwoNUMANodeTopology("aligned-container", topologyv1alpha2.BestEffortContainerLevel, "4", "3"),
+		makeTwoNUMANodeTopology("no-fit", topologyv1alpha2.RestrictedPodLevel, "1", "1"),
+	}
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatalf("failed to create fake client: %v", err)
+	}
+	for _, nrt := range nrts {
+		if err := fakeClient.Create(context.Background(), nrt.DeepCopy()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tm := TopologyMatch{
+		nrtCache:          nrtcache.NewPassthrough(klog.Background(), fakeClient),
+		scoreStrategyType: apiconfig.PreferredNUMANodes,
+	}
+
+	pod := makePodByResourceLists(
+		v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("1Gi")},
+		v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("1Gi")},
+	)
+	wantScores := map[string]int64{
+		"aligned":    framework.MaxNodeScore,
+		"misaligned": normalizeScore(2, true, nodeconfig.DefaultMaxAllowableNUMANodes),
+		// both containers are aligned on the first NUMA node
+		"aligned-container": framework.MaxNodeScore,
+		"no-fit":            framework.MinNodeScore,
+	}
+	for nodeName, wantScore := range wantScores {
+		t.Run(nodeName, func(t *testing.T) {
+			score, status := tm.Score(context.Background(), framework.NewCycleState(), pod, nodeName)
+			if !status.IsSuccess() {
+				t.Fatalf("unexpected status: %v", status)
+			}
+			if score != wantScore {
+				t.Errorf("wrong score: got %v, expected %v", score, wantScore)
+			}
+		})
+	}
+
+	// the other strategies don't favor the nodes by alignment
+	tm.scoreStrategyType = apiconfig.LeastNUMANodes
+	score, status := tm.Score(context.Background(), framework.NewCycleState(), pod, "aligned")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected status: %v", status)
+	}
+	if score == framework.MaxNodeScore {
+		t.Errorf("wrong score with LeastNUMANodes: got %v", score)
+	}
+	tm.scoreStrategyType = apiconfig.LeastAllocated
+	tm.scoreStrategyFunc = leastAllocatedScoreStrategy
+	score, status = tm.Score(context.Background(), framework.NewCycleState(), pod, "aligned")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected status: %v", status)
+	}
+	if score != framework.MinNodeScore {
+		t.Errorf("wrong score with LeastAllocated: got %v, expected %v", score, framework.MinNodeScore)
+	}
+}
@@ -31,6 +31,13 @@ func (tm *TopologyMatch) Reserve(ctx context.Context, state *framework.CycleStat
 	lh.V(4).Info(logging.FlowBegin)
 	defer lh.V(4).Info(logging.FlowEnd)
 
+	if reservations, err := getNUMAReservationState(state); err == nil {
+		if reservation, ok := reservations.get(nodeName); ok {
+			lh.V(4).Info("reserving on NUMA zones", logging.KeyNode, nodeName, "reservation", reservation.String())
+			tm.nrtCache.ReserveNodeNUMAResources(nodeName, pod, reservation)
+			return framework.NewStatus(framework.Success, "")
+		}
+	}
 	tm.nrtCache.ReserveNodeResources(nodeName, pod)
 	// can't fail
 	return framework.NewStatus(framework.Success, "")
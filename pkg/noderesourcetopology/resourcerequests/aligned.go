@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcerequests
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// NUMAAligned returns the requests, omitting the CPUs and the memory the kubelet resource managers configured
+// like managers don't align to NUMA nodes: the CPUs are aligned only if exclusive with the static CPU Manager policy,
+// the memory and the hugepages only for guaranteed pods with the static Memory Manager policy.
+func NUMAAligned(qos corev1.PodQOSClass, requests corev1.ResourceList, managers nodeconfig.ResourceManagers) corev1.ResourceList {
+	aligned := make(corev1.ResourceList, len(requests))
+	for resource, quantity := range requests {
+		if !isAlignedByManagers(qos, resource, quantity, managers) {
+			continue
+		}
+		aligned[resource] = quantity
+	}
+	return aligned
+}
+
+// NUMAAlignedForPod is like NUMAAligned, for the effective requests of the pod, which the pod scope aligns
+func NUMAAlignedForPod(pod *corev1.Pod, managers nodeconfig.ResourceManagers) corev1.ResourceList {
+	qos := v1qos.GetPodQOS(pod)
+	aligned := &corev1.Pod{}
+	for _, initContainer := range pod.Spec.InitContainers {
+		aligned.Spec.InitContainers = append(aligned.Spec.InitContainers, corev1.Container{
+			RestartPolicy: initContainer.RestartPolicy,
+			Resources:     corev1.ResourceRequirements{Requests: NUMAAligned(qos, initContainer.Resources.Requests, managers)},
+		})
+	}
+	for _, container := range pod.Spec.Containers {
+		aligned.Spec.Containers = append(aligned.Spec.Containers, corev1.Container{
+			Resources: corev1.ResourceRequirements{Requests: NUMAAligned(qos, container.Resources.Requests, managers)},
+		})
+	}
+	return util.GetPodEffectiveRequest(aligned)
+}
+
+func isAlignedByManagers(qos corev1.PodQOSClass, resource corev1.ResourceName, quantity resource.Quantity, managers nodeconfig.ResourceManagers) bool {
+	if resource == corev1.ResourceCPU {
+		return managers.CPU.IsStatic() && IsExclusive(qos, resource, quantity)
+	}
+	if resource == corev1.ResourceMemory || v1helper.IsHugePageResourceName(resource) {
+		return managers.Memory.IsStatic() && IsExclusive(qos, resource, quantity)
+	}
+	return true
+}
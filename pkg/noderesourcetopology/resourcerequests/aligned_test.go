@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcerequests
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
+)
+
+func TestNUMAAligned(t *testing.T) {
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("2"),
+		corev1.ResourceMemory:           resource.MustParse("1Gi"),
+		"hugepages-2Mi":                 resource.MustParse("64Mi"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+		"vendor.com/nic":                resource.MustParse("1"),
+	}
+	tests := []struct {
+		name     string
+		qos      corev1.PodQOSClass
+		requests corev1.ResourceList
+		managers nodeconfig.ResourceManagers
+		expected corev1.ResourceList
+	}{
+		{
+			name:     "static policies",
+			qos:      corev1.PodQOSGuaranteed,
+			requests: requests,
+			managers: nodeconfig.ResourceManagersDefaults(),
+			expected: requests,
+		},
+		{
+			name: "shared CPUs",
+			qos:  corev1.PodQOSGuaranteed,
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			managers: nodeconfig.ResourceManagersDefaults(),
+			expected: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		{
+			name:     "none policies",
+			qos:      corev1.PodQOSGuaranteed,
+			requests: requests,
+			managers: nodeconfig.ResourceManagers{
+				CPU:    nodeconfig.CPUManager{Policy: nodeconfig.CPUManagerPolicyNone},
+				Memory: nodeconfig.MemoryManager{Policy: nodeconfig.MemoryManagerPolicyNone},
+			},
+			expected: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+				"vendor.com/nic":                resource.MustParse("1"),
+			},
+		},
+		{
+			name:     "burstable",
+			qos:      corev1.PodQOSBurstable,
+			requests: requests,
+			managers: nodeconfig.ResourceManagersDefaults(),
+			expected: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+				"vendor.com/nic":                resource.MustParse("1"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NUMAAligned(tt.qos, tt.requests, tt.managers)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("aligned requests got=%v expected=%v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNUMAAlignedForPod(t *testing.T) {
+	makeContainer := func(cpu string) corev1.Container {
+		res := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		}
+		return corev1.Container{Resources: corev1.ResourceRequirements{Requests: res, Limits: res}}
+	}
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{makeContainer("4")},
+			Containers:     []corev1.Container{makeContainer("2"), makeContainer("500m")},
+		},
+	}
+
+	got := NUMAAlignedForPod(pod, nodeconfig.ResourceManagersDefaults())
+	expected := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("2Gi"),
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("aligned requests got=%v expected=%v", got, expected)
+	}
+	for name, quantity := range expected {
+		if gotQuantity := got[name]; gotQuantity.Cmp(quantity) != 0 {
+			t.Errorf("aligned %s got=%v expected=%v", name, got[name], quantity)
+		}
+	}
+}
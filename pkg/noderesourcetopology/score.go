@@ -86,7 +86,7 @@ func (tm *TopologyMatch) Score(ctx context.Context, state *framework.CycleState,
 
 	lh.V(6).Info("found object", "noderesourcetopology", stringify.NodeResourceTopologyResources(nodeTopology))
 
-	handler := tm.scoringHandlerFromTopologyManagerConfig(nodeconfig.TopologyManagerFromNodeResourceTopology(lh, nodeTopology))
+	handler := tm.scoringHandlerFromTopologyManagerConfig(nodeconfig.TopologyManagerFromNodeResourceTopology(lh, nodeTopology), nodeconfig.ResourceManagersFromNodeResourceTopology(nodeTopology))
 	if handler == nil {
 		return 0, nil
 	}
@@ -123,7 +123,7 @@ func getScoringStrategyFunction(strategy apiconfig.ScoringStrategyType) (scoreSt
 		return leastAllocatedScoreStrategy, nil
 	case apiconfig.BalancedAllocation:
 		return balancedAllocationScoreStrategy, nil
-	case apiconfig.LeastNUMANodes:
+	case apiconfig.LeastNUMANodes, apiconfig.PreferredNUMANodes:
 		// this is a special case handled down the flow. We just need to NOT error out.
 		return nil, nil
 	default:
@@ -159,13 +159,30 @@ func containerScopeScore(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.Zon
 	return finalScore, nil
 }
 
-func (tm *TopologyMatch) scoringHandlerFromTopologyManagerConfig(conf nodeconfig.TopologyManager) scoringFn {
-	if tm.scoreStrategyType == apiconfig.LeastNUMANodes {
+func (tm *TopologyMatch) scoringHandlerFromTopologyManagerConfig(conf nodeconfig.TopologyManager, managers nodeconfig.ResourceManagers) scoringFn {
+	if tm.scoreStrategyType == apiconfig.PreferredNUMANodes && (conf.Policy == kubeletconfig.RestrictedTopologyManagerPolicy || conf.Policy == kubeletconfig.BestEffortTopologyManagerPolicy) {
 		if conf.Scope == kubeletconfig.PodTopologyManagerScope {
-			return leastNUMAPodScopeScore
+			return func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList) (int64, *framework.Status) {
+				return preferredNUMAPodScopeScore(lh, pod, zones, conf, managers)
+			}
 		}
 		if conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
-			return leastNUMAContainerScopeScore
+			return func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList) (int64, *framework.Status) {
+				return preferredNUMAContainerScopeScore(lh, pod, zones, conf, managers)
+			}
+		}
+		return nil // cannot happen
+	}
+	if tm.scoreStrategyType == apiconfig.LeastNUMANodes || tm.scoreStrategyType == apiconfig.PreferredNUMANodes {
+		if conf.Scope == kubeletconfig.PodTopologyManagerScope {
+			return func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList) (int64, *framework.Status) {
+				return leastNUMAPodScopeScore(lh, pod, zones, conf)
+			}
+		}
+		if conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
+			return func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList) (int64, *framework.Status) {
+				return leastNUMAContainerScopeScore(lh, pod, zones, conf)
+			}
 		}
 		return nil // cannot happen
 	}
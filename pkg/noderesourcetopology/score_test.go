@@ -192,7 +192,10 @@ func TestNodeResourceScorePlugin(t *testing.T) {
 
 func TestNodeResourceScorePluginLeastNUMA(t *testing.T) {
 	testCases := []struct {
-		name        string
+		name            string
+		podInitRequests []v1.ResourceList
+		// sidecars are the indexes of the restartable init containers in podInitRequests
+		sidecars    []int
 		podRequests []v1.ResourceList
 		wantedRes   nodeToScoreMap
 		nodes       []*topologyv1alpha2.NodeResourceTopology
@@ -436,6 +439,49 @@ func TestNodeResourceScorePluginLeastNUMA(t *testing.T) {
 			},
 			nodes: fourNUMANodes(),
 		},
+		{
+			name: "container scope, init container releasing its resources",
+			podInitRequests: []v1.ResourceList{
+				{
+					v1.ResourceCPU:    resource.MustParse("3"),
+					v1.ResourceMemory: resource.MustParse("10Mi"),
+				},
+			},
+			podRequests: []v1.ResourceList{
+				{
+					v1.ResourceCPU:    resource.MustParse("3"),
+					v1.ResourceMemory: resource.MustParse("10Mi"),
+				},
+			},
+			wantedRes: nodeToScoreMap{
+				"Node1": 94,
+				"Node2": 82,
+				"Node3": 94,
+			},
+			nodes: defaultNUMANodes(withPolicy(topologyv1alpha2.BestEffortContainerLevel)),
+		},
+		{
+			name: "container scope, sidecar holding its resources",
+			podInitRequests: []v1.ResourceList{
+				{
+					v1.ResourceCPU:    resource.MustParse("3"),
+					v1.ResourceMemory: resource.MustParse("10Mi"),
+				},
+			},
+			sidecars: []int{0},
+			podRequests: []v1.ResourceList{
+				{
+					v1.ResourceCPU:    resource.MustParse("3"),
+					v1.ResourceMemory: resource.MustParse("10Mi"),
+				},
+			},
+			wantedRes: nodeToScoreMap{
+				"Node1": 94,
+				"Node2": 0,
+				"Node3": 94,
+			},
+			nodes: defaultNUMANodes(withPolicy(topologyv1alpha2.BestEffortContainerLevel)),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -448,6 +494,8 @@ func TestNodeResourceScorePluginLeastNUMA(t *testing.T) {
 			}
 			nodeToScore := make(nodeToScoreMap, len(nodesMap))
 			pod := makePodByResourceLists(tc.podRequests...)
+			withMultiInitContainers(tc.podInitRequests)(pod)
+			withSidecarInitContainers(tc.sidecars...)(pod)
 
 			for _, node := range nodesMap {
 				score, gotStatus := tm.Score(
@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+
+	"k8s.io/utils/clock"
+)
+
+type clockKey struct{}
+
+// WithClock : get a context whose collectors and event handlers, created by the Trimaran plugins from it, use the
+// clock instead of the real one, e.g. to replay recorded metrics at their own pace
+func WithClock(ctx context.Context, clk clock.WithTicker) context.Context {
+	return context.WithValue(ctx, clockKey{}, clk)
+}
+
+// clockFrom : get the clock of the context, the real clock if none
+func clockFrom(ctx context.Context) clock.WithTicker {
+	if clk, ok := ctx.Value(clockKey{}).(clock.WithTicker); ok {
+		return clk
+	}
+	return clock.RealClock{}
+}
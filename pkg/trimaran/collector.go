@@ -17,39 +17,170 @@ limitations under the License.
 package trimaran
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	loadwatcherapi "github.com/paypal/load-watcher/pkg/watcher/api"
 
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	pluginv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
 )
 
 const (
-	metricsUpdateIntervalSeconds = 30
+	// multiplier of the interval between two updates of the metrics after each failure
+	metricsBackoffFactor = 2.0
+	// maximum jitter of the interval between two updates of the metrics after a failure, as a factor of the interval
+	metricsBackoffJitter = 0.1
 )
 
+var (
+	// collectors are the collectors shared by the Trimaran plugins, keyed by TrimaranSpec
+	collectors = map[string]*sharedCollector{}
+	// for safe access to collectors, and to their reference counts
+	collectorsLock sync.Mutex
+)
+
+// sharedCollector : entry of a shared collector in collectors, added before the collector is created, so that the
+// plugins with the same TrimaranSpec wait for the first one to create it, without holding collectorsLock meanwhile
+type sharedCollector struct {
+	// closed once collector or err is set
+	ready chan struct{}
+	// the shared collector, set once ready
+	collector *Collector
+	// error creating the collector, set once ready
+	err error
+	// number of plugins using the shared collector, protected by collectorsLock
+	refs int
+	// stops the periodic updates of the shared collector
+	cancel context.CancelFunc
+}
+
+// contextClient : a load watcher client whose requests can be cancelled, e.g. the PrometheusNative one
+type contextClient interface {
+	GetLatestWatcherMetricsWithContext(ctx context.Context) (*watcher.WatcherMetrics, error)
+}
+
 // Collector : get data from load watcher, encapsulating the load watcher and its operations
 //
-// Trimaran plugins have different, potentially conflicting, objectives. Thus, it is recommended not
-// to enable them concurrently. As such, they are currently designed to each have its own Collector.
-// If a need arises in the future to enable multiple Trimaran plugins, a restructuring to have a single
-// Collector, serving the multiple plugins, may be beneficial for performance reasons.
+// The Trimaran plugins, of one or several profiles, with the same TrimaranSpec share a single
+// Collector obtained with GetCollector, so that they see the same metrics snapshot and the
+// load watcher is polled only once per update interval.
 type Collector struct {
 	// load watcher client
 	client loadwatcherapi.Client
 	// data collected by load watcher
 	metrics watcher.WatcherMetrics
-	// for safe access to metrics
+	// time at which the metrics were collected
+	metricsTime time.Time
+	// time at which the metrics of each node were collected, earlier than metricsTime for the nodes missing from
+	// the latest updates
+	nodeMetricsTimes map[string]time.Time
+	// age after which the metrics are stale, they are never stale if 0
+	stalenessThreshold time.Duration
+	// for safe access to metrics, metricsTime and nodeMetricsTimes
 	mu sync.RWMutex
+	// clock of the metrics time and of the periodic updates
+	clock clock.WithTicker
+
+	// interval between two updates of the metrics
+	updateInterval time.Duration
+	// timeout of an update of the metrics
+	fetchTimeout time.Duration
+	// maximum interval between two updates of the metrics when they fail
+	maxBackoff time.Duration
+	// forecasts the utilization from the history of the metrics, nil if the prediction is disabled
+	predictor *Predictor
+
+	// key of the collector in collectors, empty if the collector isn't shared
+	key string
+}
+
+// GetCollector : get the collector shared by the plugins with the same TrimaranSpec, creating it if needed.
+// The plugin releases the collector when ctx is done, and the collector stops its periodic updates
+// once released by all the plugins using it.
+func GetCollector(ctx context.Context, trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
+	key, err := collectorKey(trimaranSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	collectorsLock.Lock()
+	shared, ok := collectors[key]
+	if !ok {
+		shared = &sharedCollector{ready: make(chan struct{})}
+		collectors[key] = shared
+	}
+	shared.refs++
+	refs := shared.refs
+	collectorsLock.Unlock()
+
+	if !ok {
+		// the collector outlives the context of the plugin creating it, until released by all the plugins
+		collectorCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		shared.collector, shared.err = NewCollector(collectorCtx, trimaranSpec)
+		if shared.err != nil {
+			cancel()
+			// the plugins created afterwards try again
+			collectorsLock.Lock()
+			if collectors[key] == shared {
+				delete(collectors, key)
+			}
+			collectorsLock.Unlock()
+		} else {
+			shared.collector.key = key
+			shared.cancel = cancel
+		}
+		close(shared.ready)
+	}
+	<-shared.ready
+	if shared.err != nil {
+		return nil, shared.err
+	}
+	klog.V(4).InfoS("Using shared collector", "references", refs)
+
+	go func() {
+		<-ctx.Done()
+		shared.release(key)
+	}()
+	return shared.collector, nil
+}
+
+// collectorKey : get the key of the collector shared by the plugins with the TrimaranSpec
+func collectorKey(trimaranSpec *pluginConfig.TrimaranSpec) (string, error) {
+	key, err := json.Marshal(trimaranSpec)
+	if err != nil {
+		return "", fmt.Errorf("unable to get collector key: %v", err)
+	}
+	return string(key), nil
 }
 
-// NewCollector : create an instance of a data collector
-func NewCollector(trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
+// release : release a reference to the shared collector, stopping it once it isn't referenced anymore
+func (shared *sharedCollector) release(key string) {
+	collectorsLock.Lock()
+	defer collectorsLock.Unlock()
+	shared.refs--
+	if shared.refs > 0 {
+		return
+	}
+	if collectors[key] == shared {
+		delete(collectors, key)
+	}
+	shared.cancel()
+}
+
+// NewCollector : create an instance of a data collector, not shared with other plugins,
+// updating the metrics periodically, with the clock of ctx, until ctx is done
+func NewCollector(ctx context.Context, trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
 	if err := checkSpecs(trimaranSpec); err != nil {
 		return nil, err
 	}
@@ -59,6 +190,11 @@ func NewCollector(trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
 	var client loadwatcherapi.Client
 	if trimaranSpec.WatcherAddress != "" {
 		client, _ = loadwatcherapi.NewServiceClient(trimaranSpec.WatcherAddress)
+	} else if trimaranSpec.MetricProvider.Type == pluginConfig.PrometheusNative {
+		var err error
+		if client, err = newPrometheusClient(trimaranSpec.MetricProvider); err != nil {
+			return nil, err
+		}
 	} else {
 		opts := watcher.MetricsProviderOpts{
 			Name:               string(trimaranSpec.MetricProvider.Type),
@@ -70,49 +206,121 @@ func NewCollector(trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
 	}
 
 	collector := &Collector{
-		client: client,
+		client:             client,
+		clock:              clockFrom(ctx),
+		stalenessThreshold: time.Duration(trimaranSpec.MetricsStalenessThresholdSeconds) * time.Second,
+		updateInterval:     secondsOrDefault(trimaranSpec.MetricsUpdateIntervalSeconds, pluginv1.DefaultMetricsUpdateIntervalSeconds),
+		fetchTimeout:       secondsOrDefault(trimaranSpec.MetricsFetchTimeoutSeconds, pluginv1.DefaultMetricsFetchTimeoutSeconds),
+		maxBackoff:         secondsOrDefault(trimaranSpec.MetricsMaxBackoffSeconds, pluginv1.DefaultMetricsMaxBackoffSeconds),
+	}
+
+	if trimaranSpec.Prediction != nil {
+		collector.predictor = NewPredictor(trimaranSpec.Prediction, collector.updateInterval)
 	}
 
 	// populate metrics before returning
-	err := collector.updateMetrics()
+	err := collector.updateMetrics(ctx)
 	if err != nil {
 		klog.ErrorS(err, "Unable to populate metrics initially")
 	}
 	// start periodic updates
-	go func() {
-		metricsUpdaterTicker := time.NewTicker(time.Second * metricsUpdateIntervalSeconds)
-		for range metricsUpdaterTicker.C {
-			err = collector.updateMetrics()
-			if err != nil {
-				klog.ErrorS(err, "Unable to update metrics")
+	go collector.run(ctx, err != nil)
+	return collector, nil
+}
+
+// secondsOrDefault : get the duration of a number of seconds, or of the default number if it isn't positive
+func secondsOrDefault(seconds, defaultSeconds int64) time.Duration {
+	if seconds <= 0 {
+		seconds = defaultSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// run : update the metrics periodically until ctx is done, backing off exponentially with jitter while the
+// updates fail
+func (collector *Collector) run(ctx context.Context, failed bool) {
+	backoff := collector.newBackoff()
+	interval := collector.updateInterval
+	if failed {
+		interval = backoff.Step()
+	}
+	timer := collector.clock.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			if err := collector.updateMetrics(ctx); err != nil {
+				interval = backoff.Step()
+				klog.ErrorS(err, "Unable to update metrics", "retryAfter", interval)
+			} else {
+				backoff = collector.newBackoff()
+				interval = collector.updateInterval
 			}
+			timer.Reset(interval)
 		}
-	}()
-	return collector, nil
+	}
+}
+
+// newBackoff : get the intervals between two updates of the metrics after consecutive failures
+func (collector *Collector) newBackoff() *wait.Backoff {
+	return &wait.Backoff{
+		Duration: collector.updateInterval,
+		Factor:   metricsBackoffFactor,
+		Jitter:   metricsBackoffJitter,
+		Steps:    math.MaxInt32,
+		Cap:      collector.maxBackoff,
+	}
 }
 
-// getAllMetrics : get all metrics from watcher
-func (collector *Collector) getAllMetrics() *watcher.WatcherMetrics {
+// getAllMetrics : get all metrics from watcher, the time at which they were collected, and the time at which the
+// metrics of each node were collected
+func (collector *Collector) getAllMetrics() (*watcher.WatcherMetrics, time.Time, map[string]time.Time) {
 	collector.mu.RLock()
 	metrics := collector.metrics
+	metricsTime := collector.metricsTime
+	nodeMetricsTimes := collector.nodeMetricsTimes
 	collector.mu.RUnlock()
-	return &metrics
+	return &metrics, metricsTime, nodeMetricsTimes
 }
 
-// GetNodeMetrics : get metrics for a node from watcher
+// GetNodeMetrics : get metrics for a node from watcher, nil if they are missing or stale
 func (collector *Collector) GetNodeMetrics(nodeName string) ([]watcher.Metric, *watcher.WatcherMetrics) {
-	allMetrics := collector.getAllMetrics()
+	allMetrics, metricsTime, nodeMetricsTimes := collector.getAllMetrics()
 	// This happens if metrics were never populated since scheduler started
 	if allMetrics.Data.NodeMetricsMap == nil {
 		klog.ErrorS(nil, "Metrics not available from watcher")
 		return nil, nil
 	}
 	// Check if node is new (no metrics yet) or metrics are unavailable due to 404 or 500
-	if _, ok := allMetrics.Data.NodeMetricsMap[nodeName]; !ok {
+	nodeMetrics, ok := allMetrics.Data.NodeMetricsMap[nodeName]
+	if !ok {
 		klog.ErrorS(nil, "Unable to find metrics for node", "nodeName", nodeName)
 		return nil, allMetrics
 	}
-	return allMetrics.Data.NodeMetricsMap[nodeName].Metrics, allMetrics
+	// This happens if the watcher failed to update the metrics of the node for a while
+	nodeMetricsTime := nodeMetricsTimes[nodeName]
+	if age := collector.clock.Since(nodeMetricsTime); collector.stalenessThreshold > 0 && age > collector.stalenessThreshold {
+		klog.ErrorS(nil, "Metrics are stale", "nodeName", nodeName, "age", age, "threshold", collector.stalenessThreshold)
+		return nil, allMetrics
+	}
+	// The metrics of a node missing from the latest updates are those of the window of their own update
+	if age := metricsTime.Sub(nodeMetricsTime); age > 0 {
+		allMetrics.Window.Start -= int64(age.Seconds())
+		allMetrics.Window.End -= int64(age.Seconds())
+	}
+	return nodeMetrics.Metrics, allMetrics
+}
+
+// GetPredictedNodeMetrics : get metrics for a node as GetNodeMetrics, with the utilization forecast from the
+// history of the metrics if the prediction is enabled
+func (collector *Collector) GetPredictedNodeMetrics(nodeName string) ([]watcher.Metric, *watcher.WatcherMetrics) {
+	metrics, allMetrics := collector.GetNodeMetrics(nodeName)
+	if metrics == nil || collector.predictor == nil {
+		return metrics, allMetrics
+	}
+	return collector.predictor.Forecast(nodeName, metrics), allMetrics
 }
 
 // checkSpecs : check trimaran specs
@@ -121,23 +329,108 @@ func checkSpecs(trimaranSpec *pluginConfig.TrimaranSpec) error {
 		metricProviderType := string(trimaranSpec.MetricProvider.Type)
 		validMetricProviderType := metricProviderType == string(pluginConfig.KubernetesMetricsServer) ||
 			metricProviderType == string(pluginConfig.Prometheus) ||
-			metricProviderType == string(pluginConfig.SignalFx)
+			metricProviderType == string(pluginConfig.SignalFx) ||
+			metricProviderType == string(pluginConfig.PrometheusNative)
 		if !validMetricProviderType {
 			return fmt.Errorf("invalid MetricProvider.Type, got %v", trimaranSpec.MetricProvider.Type)
 		}
 	}
+	switch trimaranSpec.StaleMetricsPolicy {
+	case "", pluginConfig.StaleMetricsMinScore, pluginConfig.StaleMetricsFallbackToRequests, pluginConfig.StaleMetricsNeutralScore, pluginConfig.StaleMetricsFilter:
+	default:
+		return fmt.Errorf("invalid StaleMetricsPolicy, got %v", trimaranSpec.StaleMetricsPolicy)
+	}
+	if trimaranSpec.Prediction != nil {
+		if err := checkPredictionSpec(trimaranSpec.Prediction); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// fetchMetrics : request to load watcher all metrics, giving up after the fetch timeout or once ctx is done.
+// The requests of the clients supporting it are cancelled then.
+func (collector *Collector) fetchMetrics(ctx context.Context) (*watcher.WatcherMetrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, collector.fetchTimeout)
+	defer cancel()
+	type result struct {
+		metrics *watcher.WatcherMetrics
+		err     error
+	}
+	// buffered, so that the request completing after the timeout doesn't block
+	resultCh := make(chan result, 1)
+	go func() {
+		var r result
+		if client, ok := collector.client.(contextClient); ok {
+			r.metrics, r.err = client.GetLatestWatcherMetricsWithContext(ctx)
+		} else {
+			r.metrics, r.err = collector.client.GetLatestWatcherMetrics()
+		}
+		resultCh <- r
+	}()
+	select {
+	case r := <-resultCh:
+		return r.metrics, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %v", collector.fetchTimeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// UpdateMetrics : request to load watcher to update all metrics now, besides the periodic updates, e.g. to
+// replay recorded metrics
+func (collector *Collector) UpdateMetrics(ctx context.Context) error {
+	return collector.updateMetrics(ctx)
+}
+
 // updateMetrics : request to load watcher to update all metrics
-func (collector *Collector) updateMetrics() error {
-	metrics, err := collector.client.GetLatestWatcherMetrics()
+func (collector *Collector) updateMetrics(ctx context.Context) error {
+	metrics, err := collector.fetchMetrics(ctx)
 	if err != nil {
 		klog.ErrorS(err, "Load watcher client failed")
 		return err
 	}
+	// The end of the window is the time at which the watcher collected the metrics, which may be
+	// earlier than now if the watcher serves cached metrics.
+	metricsTime := collector.clock.Now()
+	if metrics.Window.End > 0 && metrics.Window.End < metricsTime.Unix() {
+		metricsTime = time.Unix(metrics.Window.End, 0)
+	}
 	collector.mu.Lock()
-	collector.metrics = *metrics
+	collector.metrics, collector.nodeMetricsTimes = collector.mergeMetrics(metrics, metricsTime)
+	collector.metricsTime = metricsTime
 	collector.mu.Unlock()
+	if collector.predictor != nil {
+		collector.predictor.Record(metrics)
+	}
 	return nil
 }
+
+// mergeMetrics : get the metrics of the update collected at metricsTime, and the time at which the metrics of each
+// node were collected. With a staleness threshold, the nodes missing from the update keep their previous metrics
+// until they are stale, so that a node missing from an update is detected as stale rather than dropped at once.
+// The caller holds the lock.
+func (collector *Collector) mergeMetrics(metrics *watcher.WatcherMetrics, metricsTime time.Time) (watcher.WatcherMetrics, map[string]time.Time) {
+	merged := *metrics
+	merged.Data.NodeMetricsMap = make(watcher.NodeMetricsMap, len(metrics.Data.NodeMetricsMap))
+	nodeMetricsTimes := make(map[string]time.Time, len(metrics.Data.NodeMetricsMap))
+	for nodeName, nodeMetrics := range metrics.Data.NodeMetricsMap {
+		merged.Data.NodeMetricsMap[nodeName] = nodeMetrics
+		nodeMetricsTimes[nodeName] = metricsTime
+	}
+	if collector.stalenessThreshold <= 0 {
+		return merged, nodeMetricsTimes
+	}
+	for nodeName, nodeMetrics := range collector.metrics.Data.NodeMetricsMap {
+		if _, ok := merged.Data.NodeMetricsMap[nodeName]; ok {
+			continue
+		}
+		if nodeMetricsTime := collector.nodeMetricsTimes[nodeName]; collector.clock.Since(nodeMetricsTime) <= collector.stalenessThreshold {
+			merged.Data.NodeMetricsMap[nodeName] = nodeMetrics
+			nodeMetricsTimes[nodeName] = nodeMetricsTime
+		}
+	}
+	return merged, nodeMetricsTimes
+}
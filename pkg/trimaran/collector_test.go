@@ -17,14 +17,20 @@ limitations under the License.
 package trimaran
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	"github.com/stretchr/testify/assert"
 
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 )
 
@@ -74,7 +80,7 @@ var (
 )
 
 func TestNewCollector(t *testing.T) {
-	col, err := NewCollector(&args)
+	col, err := NewCollector(context.TODO(), &args)
 	assert.NotNil(t, col)
 	assert.Nil(t, err)
 }
@@ -95,12 +101,168 @@ func TestNewCollectorSpecs(t *testing.T) {
 		MetricProvider: metricProvider,
 	}
 
-	col, err := NewCollector(&trimaranSpec)
+	col, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.Nil(t, col)
 	expectedErr := "invalid MetricProvider.Type, got " + string(metricProvider.Type)
 	assert.EqualError(t, err, expectedErr)
 }
 
+func TestGetCollector(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	trimaranSpec := pluginConfig.TrimaranSpec{
+		WatcherAddress: server.URL,
+	}
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+
+	collector1, err := GetCollector(ctx1, &trimaranSpec)
+	assert.Nil(t, err)
+	collector2, err := GetCollector(ctx2, &pluginConfig.TrimaranSpec{WatcherAddress: server.URL})
+	assert.Nil(t, err)
+	assert.Same(t, collector1, collector2)
+	assert.EqualValues(t, 1, requests.Load())
+
+	otherCollector, err := GetCollector(ctx1, &pluginConfig.TrimaranSpec{WatcherAddress: server.URL + "/other"})
+	assert.Nil(t, err)
+	assert.NotSame(t, collector1, otherCollector)
+
+	isShared := func(collector *Collector) bool {
+		collectorsLock.Lock()
+		defer collectorsLock.Unlock()
+		shared, ok := collectors[collector.key]
+		return ok && shared.collector == collector
+	}
+	// The collector is still used by the plugin of ctx2.
+	cancel1()
+	assert.Eventually(t, func() bool { return !isShared(otherCollector) }, time.Second, 10*time.Millisecond)
+	assert.True(t, isShared(collector1))
+
+	cancel2()
+	assert.Eventually(t, func() bool { return !isShared(collector1) }, time.Second, 10*time.Millisecond)
+
+	collector3, err := GetCollector(context.Background(), &trimaranSpec)
+	assert.Nil(t, err)
+	assert.NotSame(t, collector1, collector3)
+}
+
+func TestGetCollectorCreatedOutsideLock(t *testing.T) {
+	unblock := make(chan struct{})
+	slowServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		<-unblock
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer slowServer.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slowSpec := pluginConfig.TrimaranSpec{WatcherAddress: slowServer.URL}
+	slowCollectors := make(chan *Collector, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			collector, err := GetCollector(ctx, &slowSpec)
+			assert.Nil(t, err)
+			slowCollectors <- collector
+		}()
+	}
+
+	slowKey, err := collectorKey(&slowSpec)
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		collectorsLock.Lock()
+		defer collectorsLock.Unlock()
+		_, ok := collectors[slowKey]
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	// The collector of another spec is created while the slow one is being created.
+	collector, err := GetCollector(ctx, &pluginConfig.TrimaranSpec{WatcherAddress: server.URL})
+	assert.Nil(t, err)
+	assert.NotNil(t, collector)
+	assert.Empty(t, slowCollectors)
+
+	// The plugins with the same spec share the slow collector once created.
+	close(unblock)
+	collector1, collector2 := <-slowCollectors, <-slowCollectors
+	assert.NotNil(t, collector1)
+	assert.Same(t, collector1, collector2)
+}
+
+// blockingClient : a load watcher client answering once unblocked
+type blockingClient struct {
+	unblock chan struct{}
+}
+
+func (c *blockingClient) GetLatestWatcherMetrics() (*watcher.WatcherMetrics, error) {
+	<-c.unblock
+	return &watcherResponse, nil
+}
+
+func TestFetchMetricsTimeout(t *testing.T) {
+	client := &blockingClient{unblock: make(chan struct{})}
+	defer close(client.unblock)
+	collector := &Collector{
+		client:       client,
+		fetchTimeout: 10 * time.Millisecond,
+	}
+	err := collector.updateMetrics(context.TODO())
+	assert.EqualError(t, err, "timed out after 10ms")
+	metrics, _, _ := collector.getAllMetrics()
+	assert.Nil(t, metrics.Data.NodeMetricsMap)
+}
+
+func TestCollectorBackoff(t *testing.T) {
+	collector := &Collector{
+		updateInterval: 30 * time.Second,
+		maxBackoff:     300 * time.Second,
+	}
+	backoff := collector.newBackoff()
+	// The interval doubles after each failure, up to the maximum, with up to 10% of jitter.
+	for _, expected := range []time.Duration{30, 60, 120, 240, 300, 300} {
+		interval := backoff.Step()
+		assert.GreaterOrEqual(t, interval, expected*time.Second)
+		assert.LessOrEqual(t, interval, expected*time.Second*11/10)
+	}
+}
+
+func TestCollectorRunStops(t *testing.T) {
+	collector := &Collector{
+		client:         &blockingClient{},
+		clock:          clock.RealClock{},
+		updateInterval: time.Hour,
+		maxBackoff:     time.Hour,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		collector.run(ctx, false)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("expected the updates to stop with the context")
+	}
+}
+
 func TestGetAllMetrics(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 		bytes, err := json.Marshal(watcherResponse)
@@ -112,11 +274,11 @@ func TestGetAllMetrics(t *testing.T) {
 	trimaranSpec := pluginConfig.TrimaranSpec{
 		WatcherAddress: server.URL,
 	}
-	collector, err := NewCollector(&trimaranSpec)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 
-	metrics := collector.getAllMetrics()
+	metrics, _, _ := collector.getAllMetrics()
 	metricsMap := metrics.Data.NodeMetricsMap
 	expectedMap := watcherResponse.Data.NodeMetricsMap
 	assert.EqualValues(t, expectedMap, metricsMap)
@@ -133,11 +295,11 @@ func TestUpdateMetrics(t *testing.T) {
 	trimaranSpec := pluginConfig.TrimaranSpec{
 		WatcherAddress: server.URL,
 	}
-	collector, err := NewCollector(&trimaranSpec)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 
-	err = collector.updateMetrics()
+	err = collector.updateMetrics(context.TODO())
 	assert.Nil(t, err)
 }
 
@@ -152,7 +314,7 @@ func TestGetNodeMetrics(t *testing.T) {
 	trimaranSpec := pluginConfig.TrimaranSpec{
 		WatcherAddress: server.URL,
 	}
-	collector, err := NewCollector(&trimaranSpec)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 	nodeName := "node-1"
@@ -163,6 +325,153 @@ func TestGetNodeMetrics(t *testing.T) {
 	assert.EqualValues(t, expectedAllMetrics, allMetrics)
 }
 
+func TestGetNodeMetricsStale(t *testing.T) {
+	staleWatcherResponse := watcherResponse
+	staleWatcherResponse.Window = watcher.Window{
+		Duration: "15m",
+		Start:    time.Now().Add(-20 * time.Minute).Unix(),
+		End:      time.Now().Add(-5 * time.Minute).Unix(),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(staleWatcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name               string
+		stalenessThreshold int64
+		expectedMetrics    []watcher.Metric
+	}{
+		{
+			name:               "metrics older than threshold",
+			stalenessThreshold: 60,
+			expectedMetrics:    nil,
+		},
+		{
+			name:               "metrics younger than threshold",
+			stalenessThreshold: 600,
+			expectedMetrics:    watcherResponse.Data.NodeMetricsMap["node-1"].Metrics,
+		},
+		{
+			name:               "metrics never stale",
+			stalenessThreshold: 0,
+			expectedMetrics:    watcherResponse.Data.NodeMetricsMap["node-1"].Metrics,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimaranSpec := pluginConfig.TrimaranSpec{
+				WatcherAddress:                   server.URL,
+				MetricsStalenessThresholdSeconds: tt.stalenessThreshold,
+			}
+			collector, err := NewCollector(context.TODO(), &trimaranSpec)
+			assert.Nil(t, err)
+			metrics, allMetrics := collector.GetNodeMetrics("node-1")
+			assert.EqualValues(t, tt.expectedMetrics, metrics)
+			assert.NotNil(t, allMetrics)
+		})
+	}
+}
+
+func TestGetNodeMetricsStaleWithClock(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Unix(1700000000, 0))
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		response := watcherResponse
+		response.Window = watcher.Window{
+			Duration: "15m",
+			Start:    fakeClock.Now().Add(-15 * time.Minute).Unix(),
+			End:      fakeClock.Now().Unix(),
+		}
+		bytes, err := json.Marshal(response)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	trimaranSpec := pluginConfig.TrimaranSpec{
+		WatcherAddress:                   server.URL,
+		MetricsStalenessThresholdSeconds: 60,
+		// no periodic update while the clock advances
+		MetricsUpdateIntervalSeconds: 3600,
+	}
+	ctx, cancel := context.WithCancel(WithClock(context.Background(), fakeClock))
+	defer cancel()
+	collector, err := NewCollector(ctx, &trimaranSpec)
+	assert.Nil(t, err)
+	metrics, _ := collector.GetNodeMetrics("node-1")
+	assert.EqualValues(t, watcherResponse.Data.NodeMetricsMap["node-1"].Metrics, metrics)
+
+	// the metrics are stale by the clock of the collector, whatever the real time
+	fakeClock.SetTime(fakeClock.Now().Add(2 * time.Minute))
+	metrics, _ = collector.GetNodeMetrics("node-1")
+	assert.Nil(t, metrics)
+}
+
+func TestGetNodeMetricsStalePerNode(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Unix(1700000000, 0))
+	nodeMetrics := watcherResponse.Data.NodeMetricsMap["node-1"]
+	var nodeNames atomic.Value
+	nodeNames.Store([]string{"node-1", "node-2"})
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		response := watcher.WatcherMetrics{
+			Window: watcher.Window{
+				Duration: "15m",
+				Start:    fakeClock.Now().Add(-15 * time.Minute).Unix(),
+				End:      fakeClock.Now().Unix(),
+			},
+			Data: watcher.Data{NodeMetricsMap: watcher.NodeMetricsMap{}},
+		}
+		for _, nodeName := range nodeNames.Load().([]string) {
+			response.Data.NodeMetricsMap[nodeName] = nodeMetrics
+		}
+		bytes, err := json.Marshal(response)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	trimaranSpec := pluginConfig.TrimaranSpec{
+		WatcherAddress:                   server.URL,
+		MetricsStalenessThresholdSeconds: 60,
+		// no periodic update while the clock advances
+		MetricsUpdateIntervalSeconds: 3600,
+	}
+	ctx, cancel := context.WithCancel(WithClock(context.Background(), fakeClock))
+	defer cancel()
+	collector, err := NewCollector(ctx, &trimaranSpec)
+	assert.Nil(t, err)
+
+	// node-2 is missing from the following updates, its metrics are those of the first update until they are stale
+	nodeNames.Store([]string{"node-1"})
+	fakeClock.SetTime(fakeClock.Now().Add(30 * time.Second))
+	assert.Nil(t, collector.UpdateMetrics(context.TODO()))
+	metrics, allMetrics := collector.GetNodeMetrics("node-1")
+	assert.EqualValues(t, nodeMetrics.Metrics, metrics)
+	assert.Equal(t, fakeClock.Now().Unix(), allMetrics.Window.End)
+	metrics, allMetrics = collector.GetNodeMetrics("node-2")
+	assert.EqualValues(t, nodeMetrics.Metrics, metrics)
+	assert.Equal(t, fakeClock.Now().Add(-30*time.Second).Unix(), allMetrics.Window.End)
+
+	fakeClock.SetTime(fakeClock.Now().Add(60 * time.Second))
+	assert.Nil(t, collector.UpdateMetrics(context.TODO()))
+	metrics, _ = collector.GetNodeMetrics("node-1")
+	assert.EqualValues(t, nodeMetrics.Metrics, metrics)
+	metrics, _ = collector.GetNodeMetrics("node-2")
+	assert.Nil(t, metrics)
+}
+
+func TestNewCollectorStaleMetricsPolicy(t *testing.T) {
+	trimaranSpec := pluginConfig.TrimaranSpec{
+		WatcherAddress:     "http://deadbeef:2020",
+		StaleMetricsPolicy: "Ignore",
+	}
+	col, err := NewCollector(context.TODO(), &trimaranSpec)
+	assert.Nil(t, col)
+	assert.EqualError(t, err, "invalid StaleMetricsPolicy, got Ignore")
+}
+
 func TestGetNodeMetricsNilForNode(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 		bytes, err := json.Marshal(noWatcherResponseForNode)
@@ -174,7 +483,7 @@ func TestGetNodeMetricsNilForNode(t *testing.T) {
 	trimaranSpec := pluginConfig.TrimaranSpec{
 		WatcherAddress: server.URL,
 	}
-	collector, err := NewCollector(&trimaranSpec)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 	nodeName := "node-1"
@@ -204,7 +513,7 @@ func TestNewCollectorLoadWatcher(t *testing.T) {
 		MetricProvider: metricProvider,
 	}
 
-	col, err := NewCollector(&trimaranSpec)
+	col, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, col)
 	assert.Nil(t, err)
 }
@@ -21,9 +21,11 @@ Package Trimaran provides common code for plugins developed for real load aware
 package trimaran
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -31,22 +33,33 @@ import (
 	clientcache "k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
-)
+	"k8s.io/utils/clock"
 
-const (
-	// This is the maximum staleness of metrics possible by load watcher
-	cacheCleanupIntervalMinutes = 5
-	// Time interval in seconds for each metrics agent ingestion.
-	metricsAgentReportingIntervalSeconds = 60
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	pluginv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
 )
 
 var _ clientcache.ResourceEventHandler = &PodAssignEventHandler{}
 
+// PodUsagePredictor : predict the usage of the resources by a pod, in millicores for CPU
+type PodUsagePredictor func(pod *v1.Pod) *framework.Resource
+
 // This event handler watches assigned Pod and caches them locally
 type PodAssignEventHandler struct {
 	// Maintains the node-name to podInfo mapping for pods successfully bound to nodes
 	ScheduledPodsCache map[string][]podInfo
+	// Maintains the node-name to the sum of the predicted usage of the pods in ScheduledPodsCache
+	predictedDeltas map[string]*framework.Resource
 	sync.RWMutex
+	// Time interval for each metrics agent ingestion, after which the pods are removed from the cache
+	metricsAgentReportingInterval time.Duration
+	// Predicts the usage of the pods added to the cache, their requests by default
+	predictUsage PodUsagePredictor
+	// Clock of the timestamps of the pods added to the cache, and of the cache cleanup
+	clock clock.WithTicker
+	// Latest end of the metrics window, in unix seconds, passed to GetPredictedDelta, up to which the cache cleanup
+	// expires the pods whose usage the metrics reflect
+	latestWindowEnd atomic.Int64
 }
 
 // Stores Timestamp and Pod spec info object
@@ -54,20 +67,95 @@ type podInfo struct {
 	// This timestamp is initialised when adding it to ScheduledPodsCache after successful binding
 	Timestamp time.Time
 	Pod       *v1.Pod
+	// Predicted usage of the pod, initialised when adding it to ScheduledPodsCache
+	Delta *framework.Resource
 }
 
-// Returns a new instance of PodAssignEventHandler, after starting a background go routine for cache cleanup
-func New() *PodAssignEventHandler {
-	p := PodAssignEventHandler{ScheduledPodsCache: make(map[string][]podInfo)}
+// Returns a new instance of PodAssignEventHandler using the clock of ctx, after starting a background go routine
+// for cache cleanup running until ctx is done
+func New(ctx context.Context, trimaranSpec *pluginConfig.TrimaranSpec) *PodAssignEventHandler {
+	p := PodAssignEventHandler{
+		ScheduledPodsCache: make(map[string][]podInfo),
+		predictedDeltas:    make(map[string]*framework.Resource),
+		predictUsage:       GetResourceRequested,
+		clock:              clockFrom(ctx),
+		metricsAgentReportingInterval: secondsOrDefault(trimaranSpec.MetricsAgentReportingIntervalSeconds,
+			pluginv1.DefaultMetricsAgentReportingIntervalSeconds),
+	}
+	cacheCleanupIntervalMinutes := trimaranSpec.CacheCleanupIntervalMinutes
+	if cacheCleanupIntervalMinutes <= 0 {
+		cacheCleanupIntervalMinutes = pluginv1.DefaultCacheCleanupIntervalMinutes
+	}
 	go func() {
-		cacheCleanerTicker := time.NewTicker(time.Minute * cacheCleanupIntervalMinutes)
-		for range cacheCleanerTicker.C {
-			p.cleanupCache()
+		cacheCleanerTicker := p.clock.NewTicker(time.Minute * time.Duration(cacheCleanupIntervalMinutes))
+		defer cacheCleanerTicker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-cacheCleanerTicker.C():
+				p.cleanupCache()
+			}
 		}
 	}()
 	return &p
 }
 
+// MetricsAgentReportingInterval : time interval for each metrics agent ingestion
+func (p *PodAssignEventHandler) MetricsAgentReportingInterval() time.Duration {
+	return p.metricsAgentReportingInterval
+}
+
+// SetPodUsagePredictor : set the predictor of the usage of the pods added to the cache afterwards
+func (p *PodAssignEventHandler) SetPodUsagePredictor(predictUsage PodUsagePredictor) {
+	p.Lock()
+	defer p.Unlock()
+	p.predictUsage = predictUsage
+}
+
+// GetPredictedDelta : get the predicted usage of the pods bound to the node whose usage is missing from the metrics
+// of the window ending at windowEnd, in unix seconds. The pods whose usage the metrics reflect are left out, and
+// expired by the next cache cleanup.
+func (p *PodAssignEventHandler) GetPredictedDelta(nodeName string, windowEnd int64) *framework.Resource {
+	for latest := p.latestWindowEnd.Load(); windowEnd > latest; latest = p.latestWindowEnd.Load() {
+		if p.latestWindowEnd.CompareAndSwap(latest, windowEnd) {
+			break
+		}
+	}
+
+	p.RLock()
+	defer p.RUnlock()
+	delta, ok := p.predictedDeltas[nodeName]
+	if !ok {
+		return &framework.Resource{}
+	}
+	cache := p.ScheduledPodsCache[nodeName]
+	reflectedBefore := p.reflectedBefore(windowEnd)
+	idx := sort.Search(len(cache), func(i int) bool {
+		return cache[i].Timestamp.After(reflectedBefore)
+	})
+	if idx == 0 {
+		return delta.Clone()
+	}
+	missing := &framework.Resource{}
+	for j := idx; j < len(cache); j++ {
+		if cache[j].Delta != nil {
+			addResource(missing, cache[j].Delta, 1)
+		}
+	}
+	return missing
+}
+
+// reflectedBefore : get the time before which the pods bound are reflected by the metrics of the window ending at
+// windowEnd, in unix seconds. The usage of a pod is missing from the metrics if it was bound after the end of the
+// window, or within the metrics reporting interval before it.
+// Note that the second condition doesn't guarantee metrics for that pod are not reported yet as the 0 <= t <= 2*metricsAgentReportingIntervalSeconds
+// t = metricsAgentReportingIntervalSeconds is taken as average case and it doesn't hurt us much if we are
+// counting metrics twice in case actual t is less than metricsAgentReportingIntervalSeconds
+func (p *PodAssignEventHandler) reflectedBefore(windowEnd int64) time.Time {
+	return time.Unix(windowEnd, 0).Add(-p.metricsAgentReportingInterval)
+}
+
 // AddToHandle : add event handler to framework handle
 func (p *PodAssignEventHandler) AddToHandle(handle framework.Handle) {
 	handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(
@@ -102,12 +190,42 @@ func (p *PodAssignEventHandler) OnUpdate(oldObj, newObj interface{}) {
 	newPod := newObj.(*v1.Pod)
 
 	if oldPod.Spec.NodeName != newPod.Spec.NodeName {
+		p.deleteFromCache(oldPod)
 		p.updateCache(newPod)
+	} else if isTerminated(newPod) && !isTerminated(oldPod) {
+		// a terminated pod doesn't use resources anymore
+		p.deleteFromCache(newPod)
 	}
 }
 
 func (p *PodAssignEventHandler) OnDelete(obj interface{}) {
-	pod := obj.(*v1.Pod)
+	switch t := obj.(type) {
+	case *v1.Pod:
+		p.deleteFromCache(t)
+	case clientcache.DeletedFinalStateUnknown:
+		if pod, ok := t.Obj.(*v1.Pod); ok {
+			p.deleteFromCache(pod)
+		}
+	}
+}
+
+func (p *PodAssignEventHandler) updateCache(pod *v1.Pod) {
+	if pod.Spec.NodeName == "" || isTerminated(pod) {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	info := podInfo{Timestamp: p.clock.Now(), Pod: pod, Delta: p.predictUsage(pod)}
+	p.ScheduledPodsCache[pod.Spec.NodeName] = append(p.ScheduledPodsCache[pod.Spec.NodeName], info)
+	delta, ok := p.predictedDeltas[pod.Spec.NodeName]
+	if !ok {
+		delta = &framework.Resource{}
+		p.predictedDeltas[pod.Spec.NodeName] = delta
+	}
+	addResource(delta, info.Delta, 1)
+}
+
+func (p *PodAssignEventHandler) deleteFromCache(pod *v1.Pod) {
 	nodeName := pod.Spec.NodeName
 	p.Lock()
 	defer p.Unlock()
@@ -118,49 +236,85 @@ func (p *PodAssignEventHandler) OnDelete(obj interface{}) {
 		n := len(p.ScheduledPodsCache[nodeName])
 		if pod.ObjectMeta.UID == v.Pod.ObjectMeta.UID {
 			klog.V(10).InfoS("Deleting pod", "pod", klog.KObj(v.Pod))
+			p.subtractDelta(nodeName, v.Delta)
 			copy(p.ScheduledPodsCache[nodeName][i:], p.ScheduledPodsCache[nodeName][i+1:])
 			p.ScheduledPodsCache[nodeName][n-1] = podInfo{}
 			p.ScheduledPodsCache[nodeName] = p.ScheduledPodsCache[nodeName][:n-1]
 			break
 		}
 	}
-}
-
-func (p *PodAssignEventHandler) updateCache(pod *v1.Pod) {
-	if pod.Spec.NodeName == "" {
-		return
+	if len(p.ScheduledPodsCache[nodeName]) == 0 {
+		delete(p.ScheduledPodsCache, nodeName)
+		delete(p.predictedDeltas, nodeName)
 	}
-	p.Lock()
-	p.ScheduledPodsCache[pod.Spec.NodeName] = append(p.ScheduledPodsCache[pod.Spec.NodeName],
-		podInfo{Timestamp: time.Now(), Pod: pod})
-	p.Unlock()
 }
 
-// Deletes podInfo entries that are older than metricsAgentReportingIntervalSeconds. Also deletes node entry if empty
+// Deletes podInfo entries that are older than metricsAgentReportingInterval, or reflected by the latest metrics
+// window, if it ends later. Also deletes node entry if empty
 func (p *PodAssignEventHandler) cleanupCache() {
+	reflectedBefore := p.clock.Now().Add(-p.metricsAgentReportingInterval)
+	if windowReflectedBefore := p.reflectedBefore(p.latestWindowEnd.Load()); windowReflectedBefore.After(reflectedBefore) {
+		reflectedBefore = windowReflectedBefore
+	}
 	p.Lock()
 	defer p.Unlock()
 	for nodeName := range p.ScheduledPodsCache {
-		cache := p.ScheduledPodsCache[nodeName]
-		curTime := time.Now()
-		idx := sort.Search(len(cache), func(i int) bool {
-			return cache[i].Timestamp.Add(metricsAgentReportingIntervalSeconds * time.Second).After(curTime)
+		p.expire(nodeName, func(info *podInfo) bool {
+			return !info.Timestamp.After(reflectedBefore)
 		})
-		if idx == len(cache) {
-			continue
-		}
-		n := copy(cache, cache[idx:])
-		for j := n; j < len(cache); j++ {
-			cache[j] = podInfo{}
-		}
-		cache = cache[:n]
+	}
+}
 
-		if len(cache) == 0 {
-			delete(p.ScheduledPodsCache, nodeName)
-		} else {
-			p.ScheduledPodsCache[nodeName] = cache
-		}
+// expire : delete the podInfo entries of the node up to the last one that is expired, the entries being sorted by
+// timestamp, and their predicted usage. Also deletes node entry if empty. The caller holds the lock.
+func (p *PodAssignEventHandler) expire(nodeName string, expired func(info *podInfo) bool) {
+	cache, ok := p.ScheduledPodsCache[nodeName]
+	if !ok {
+		return
+	}
+	idx := sort.Search(len(cache), func(i int) bool {
+		return !expired(&cache[i])
+	})
+	if idx == 0 {
+		return
+	}
+	for j := 0; j < idx; j++ {
+		p.subtractDelta(nodeName, cache[j].Delta)
 	}
+	n := copy(cache, cache[idx:])
+	for j := n; j < len(cache); j++ {
+		cache[j] = podInfo{}
+	}
+	cache = cache[:n]
+
+	if len(cache) == 0 {
+		delete(p.ScheduledPodsCache, nodeName)
+		delete(p.predictedDeltas, nodeName)
+	} else {
+		p.ScheduledPodsCache[nodeName] = cache
+	}
+}
+
+// subtractDelta : subtract the predicted usage of a pod from the predicted usage of the pods of the node
+func (p *PodAssignEventHandler) subtractDelta(nodeName string, podDelta *framework.Resource) {
+	if delta, ok := p.predictedDeltas[nodeName]; ok && podDelta != nil {
+		addResource(delta, podDelta, -1)
+	}
+}
+
+// addResource : add the resource r, multiplied by sign, to the resource sum
+func addResource(sum *framework.Resource, r *framework.Resource, sign int64) {
+	sum.MilliCPU += sign * r.MilliCPU
+	sum.Memory += sign * r.Memory
+	sum.EphemeralStorage += sign * r.EphemeralStorage
+	for name, value := range r.ScalarResources {
+		sum.AddScalar(name, sign*value)
+	}
+}
+
+// Checks and returns true if the pod is terminated
+func isTerminated(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
 }
 
 // Checks and returns true if the pod is assigned to a node
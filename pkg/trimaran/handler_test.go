@@ -1,12 +1,17 @@
 package trimaran
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 )
 
 func TestHandlerCacheCleanup(t *testing.T) {
@@ -17,11 +22,12 @@ func TestHandlerCacheCleanup(t *testing.T) {
 	pod4 := st.MakePod().Name("Pod-4").Obj()
 
 	tests := []struct {
-		name              string
-		podInfoList       []podInfo
-		podToUpdate       string
-		expectedCacheSize int
-		expectedCachePods []string
+		name                                 string
+		podInfoList                          []podInfo
+		podToUpdate                          string
+		metricsAgentReportingIntervalSeconds int64
+		expectedCacheSize                    int
+		expectedCachePods                    []string
 	}{
 		{
 			name: "OnUpdate doesn't add unassigned pods",
@@ -54,11 +60,24 @@ func TestHandlerCacheCleanup(t *testing.T) {
 			expectedCacheSize: 2,
 			expectedCachePods: []string{pod2.Name, pod3.Name},
 		},
+		{
+			name: "cleanupCache deletes pods older than the configured reporting interval",
+			podInfoList: []podInfo{
+				{Timestamp: time.Now().Add(-5 * time.Minute), Pod: pod1},
+				{Timestamp: time.Now().Add(-10 * time.Second), Pod: pod2},
+				{Timestamp: time.Now().Add(-5 * time.Second), Pod: pod3},
+			},
+			metricsAgentReportingIntervalSeconds: 8,
+			expectedCacheSize:                    1,
+			expectedCachePods:                    []string{pod3.Name},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := New()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			p := New(ctx, &pluginConfig.TrimaranSpec{MetricsAgentReportingIntervalSeconds: tt.metricsAgentReportingIntervalSeconds})
 			p.ScheduledPodsCache[testNode] = append(p.ScheduledPodsCache[testNode], tt.podInfoList...)
 			if tt.podToUpdate != "" {
 				pod := st.MakePod().Name(tt.podToUpdate).Obj()
@@ -75,3 +94,52 @@ func TestHandlerCacheCleanup(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPredictedDelta(t *testing.T) {
+	testNode := "node-1"
+	pod1 := st.MakePod().Name("Pod-1").UID("Pod-1").Node(testNode).
+		Req(map[v1.ResourceName]string{v1.ResourceCPU: "100m", v1.ResourceMemory: "1Gi"}).Obj()
+	pod2 := st.MakePod().Name("Pod-2").UID("Pod-2").Node(testNode).
+		Req(map[v1.ResourceName]string{v1.ResourceCPU: "200m"}).Obj()
+	pod3 := st.MakePod().Name("Pod-3").UID("Pod-3").Node("node-2").
+		Req(map[v1.ResourceName]string{v1.ResourceCPU: "400m"}).Obj()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := New(ctx, &pluginConfig.TrimaranSpec{MetricsAgentReportingIntervalSeconds: 60})
+	p.OnAdd(pod1, false)
+	p.OnAdd(pod2, false)
+	p.OnAdd(pod3, false)
+
+	// the pods were bound within the metrics reporting interval before the end of the window
+	windowEnd := time.Now().Unix()
+	assert.Equal(t, &framework.Resource{MilliCPU: 300, Memory: 1024 * 1024 * 1024}, p.GetPredictedDelta(testNode, windowEnd))
+	assert.Equal(t, &framework.Resource{MilliCPU: 400}, p.GetPredictedDelta("node-2", windowEnd))
+	assert.Equal(t, &framework.Resource{}, p.GetPredictedDelta("node-3", windowEnd))
+
+	p.OnDelete(pod2)
+	assert.Equal(t, &framework.Resource{MilliCPU: 100, Memory: 1024 * 1024 * 1024}, p.GetPredictedDelta(testNode, windowEnd))
+
+	// a terminated pod doesn't use resources anymore
+	terminatedPod1 := pod1.DeepCopy()
+	terminatedPod1.Status.Phase = v1.PodSucceeded
+	p.OnUpdate(pod1, terminatedPod1)
+	assert.Equal(t, &framework.Resource{}, p.GetPredictedDelta(testNode, windowEnd))
+	assert.NotContains(t, p.ScheduledPodsCache, testNode)
+
+	// the pods are left out once the end of the window is a reporting interval after their binding, and expired
+	// by the next cleanup
+	p.OnAdd(pod2, false)
+	assert.Equal(t, &framework.Resource{MilliCPU: 200}, p.GetPredictedDelta(testNode, windowEnd))
+	assert.Equal(t, &framework.Resource{}, p.GetPredictedDelta(testNode, time.Now().Add(2*time.Minute).Unix()))
+	assert.Contains(t, p.ScheduledPodsCache, testNode)
+	p.cleanupCache()
+	assert.NotContains(t, p.ScheduledPodsCache, testNode)
+
+	// the usage of the pods is predicted by the predictor set
+	p.SetPodUsagePredictor(func(pod *v1.Pod) *framework.Resource {
+		return &framework.Resource{MilliCPU: 2 * GetResourceRequested(pod).MilliCPU}
+	})
+	p.OnAdd(pod2, false)
+	assert.Equal(t, &framework.Resource{MilliCPU: 400}, p.GetPredictedDelta(testNode, windowEnd))
+}
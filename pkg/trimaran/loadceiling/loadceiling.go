@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadceiling plugin filters out the nodes whose predicted utilization exceeds a hard ceiling.
+// The predicted utilization of a resource on a node is its measured utilization, plus the requests of
+// the pods recently bound to the node and not yet accounted for in the metrics, plus the requests of
+// the incoming pod.
+
+package loadceiling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
+)
+
+const (
+	// Name : name of plugin
+	Name = "LoadCeiling"
+
+	// ErrReasonUtilizationCeiling : reason of the nodes filtered out, formatted with the name of the resource
+	ErrReasonUtilizationCeiling = "node(s) with predicted %v utilization above ceiling"
+)
+
+// LoadCeiling : scheduler plugin
+type LoadCeiling struct {
+	handle       framework.Handle
+	eventHandler *trimaran.PodAssignEventHandler
+	collector    *trimaran.Collector
+	args         *pluginConfig.LoadCeilingArgs
+	// utilization ceilings, sorted by resource name
+	ceilings []resourceCeiling
+}
+
+// resourceCeiling : hard ceiling of the predicted utilization of a resource
+type resourceCeiling struct {
+	name v1.ResourceName
+	// type of the metrics of the utilization of the resource
+	metricType string
+	// ceiling of the predicted utilization, in percent
+	ceiling int64
+}
+
+var _ framework.FilterPlugin = &LoadCeiling{}
+
+// New : create an instance of a LoadCeiling plugin
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	klog.V(4).InfoS("Creating new instance of the LoadCeiling plugin")
+	// cast object into plugin arguments object
+	args, ok := obj.(*pluginConfig.LoadCeilingArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type LoadCeilingArgs, got %T", obj)
+	}
+	ceilings, err := newResourceCeilings(args.UtilizationCeilings)
+	if err != nil {
+		return nil, err
+	}
+	collector, err := trimaran.GetCollector(ctx, &args.TrimaranSpec)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(4).InfoS("Using LoadCeilingArgs", "utilizationCeilings", args.UtilizationCeilings)
+
+	podAssignEventHandler := trimaran.New(ctx, &args.TrimaranSpec)
+	podAssignEventHandler.AddToHandle(handle)
+
+	pl := &LoadCeiling{
+		handle:       handle,
+		eventHandler: podAssignEventHandler,
+		collector:    collector,
+		args:         args,
+		ceilings:     ceilings,
+	}
+	return pl, nil
+}
+
+// newResourceCeilings : validate the utilization ceilings and sort them by resource name
+func newResourceCeilings(utilizationCeilings map[v1.ResourceName]int64) ([]resourceCeiling, error) {
+	if len(utilizationCeilings) == 0 {
+		return nil, fmt.Errorf("no utilization ceilings")
+	}
+	ceilings := make([]resourceCeiling, 0, len(utilizationCeilings))
+	for r, c := range utilizationCeilings {
+		if c <= 0 || c > 100 {
+			return nil, fmt.Errorf("invalid utilization ceiling of resource %v, got %v", r, c)
+		}
+		ceilings = append(ceilings, resourceCeiling{
+			name:       r,
+			metricType: trimaran.MetricType(r),
+			ceiling:    c,
+		})
+	}
+	sort.Slice(ceilings, func(i, j int) bool {
+		return ceilings[i].name < ceilings[j].name
+	})
+	return ceilings, nil
+}
+
+// Name : name of plugin
+func (pl *LoadCeiling) Name() string {
+	return Name
+}
+
+// Filter : filter out the node if the predicted utilization of any resource exceeds its ceiling. The node is unresolvable,
+// as preempting pods doesn't lower its measured utilization before the next metrics.
+func (pl *LoadCeiling) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	nodeName := node.Name
+
+	// get node metrics
+	metrics, allMetrics := pl.collector.GetNodeMetrics(nodeName)
+	fallbackToRequests := false
+	if metrics == nil {
+		switch pl.args.StaleMetricsPolicy {
+		case pluginConfig.StaleMetricsFilter:
+			klog.V(6).InfoS("Failed to get fresh metrics for node; filtering out", "nodeName", nodeName)
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, trimaran.ErrReasonStaleMetrics)
+		case pluginConfig.StaleMetricsFallbackToRequests:
+			klog.V(6).InfoS("Failed to get fresh metrics for node; using requests", "nodeName", nodeName)
+			fallbackToRequests = true
+		default:
+			klog.V(6).InfoS("Failed to get fresh metrics for node; skipping", "nodeName", nodeName)
+			return nil
+		}
+	}
+
+	podRequests := trimaran.GetResourceRequested(pod)
+	// requests of the pods bound to the node recently, whose utilization is likely missing from the metrics
+	missingRequests := &framework.Resource{}
+	if !fallbackToRequests {
+		missingRequests = pl.eventHandler.GetPredictedDelta(nodeName, allMetrics.Window.End)
+	}
+	nodeCapacity := node.Status.Capacity
+	for _, c := range pl.ceilings {
+		capacity := float64(trimaran.QuantityValue(c.name, nodeCapacity[c.name]))
+		if capacity == 0 {
+			continue
+		}
+		var util float64
+		if fallbackToRequests {
+			// The requests of the pods on the node, including the pods scheduled recently, stand for its utilization.
+			util = float64(trimaran.RequestedValue(nodeInfo.Requested, c.name))
+		} else {
+			utilPercent, _, found := trimaran.GetResourceData(metrics, c.metricType)
+			if !found {
+				klog.V(6).InfoS("Resource metric not found in node metrics", "nodeName", nodeName, "resource", c.name, "nodeMetrics", metrics)
+				continue
+			}
+			util = (utilPercent/100)*capacity + float64(trimaran.RequestedValue(missingRequests, c.name))
+		}
+		predictedUtil := 100 * (util + float64(trimaran.RequestedValue(podRequests, c.name))) / capacity
+		klog.V(6).InfoS("Predicted utilization", "pod", klog.KObj(pod), "nodeName", nodeName, "resource", c.name,
+			"predictedUtil", predictedUtil, "ceiling", c.ceiling)
+		if predictedUtil > float64(c.ceiling) {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf(ErrReasonUtilizationCeiling, c.name))
+		}
+	}
+	return nil
+}
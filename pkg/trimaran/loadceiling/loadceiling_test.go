@@ -0,0 +1,313 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadceiling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"github.com/stretchr/testify/assert"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	testClientSet "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
+	testutil "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func newTestPlugin(ctx context.Context, t *testing.T, args *pluginConfig.LoadCeilingArgs) (*LoadCeiling, error) {
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	cs := testClientSet.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{{Name: Name, Args: args}},
+		"default-scheduler", runtime.WithClientSet(cs), runtime.WithInformerFactory(informerFactory))
+	assert.Nil(t, err)
+	p, err := New(ctx, args, fh)
+	if err != nil {
+		return nil, err
+	}
+	return p.(*LoadCeiling), nil
+}
+
+func newWatcherServer(t *testing.T, watcherResponse watcher.WatcherMetrics) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+}
+
+func TestNew(t *testing.T) {
+	server := newWatcherServer(t, watcher.WatcherMetrics{})
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		ceilings    map[v1.ResourceName]int64
+		expectedErr bool
+	}{
+		{
+			name: "valid ceilings",
+			ceilings: map[v1.ResourceName]int64{
+				v1.ResourceCPU:    80,
+				v1.ResourceMemory: 100,
+			},
+		},
+		{
+			name:        "no ceilings",
+			expectedErr: true,
+		},
+		{
+			name: "ceiling above 100",
+			ceilings: map[v1.ResourceName]int64{
+				v1.ResourceCPU: 120,
+			},
+			expectedErr: true,
+		},
+		{
+			name: "zero ceiling",
+			ceilings: map[v1.ResourceName]int64{
+				v1.ResourceMemory: 0,
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			args := &pluginConfig.LoadCeilingArgs{
+				TrimaranSpec:        pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+				UtilizationCeilings: tt.ceilings,
+			}
+			p, err := newTestPlugin(ctx, t, args)
+			if tt.expectedErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, Name, p.Name())
+			assert.Equal(t, []resourceCeiling{
+				{name: v1.ResourceCPU, metricType: watcher.CPU, ceiling: 80},
+				{name: v1.ResourceMemory, metricType: watcher.Memory, ceiling: 100},
+			}, p.ceilings)
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "4000m",
+		v1.ResourceMemory: "8Gi",
+	}
+	node := st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()
+	watcherResponse := watcher.WatcherMetrics{
+		Window: watcher.Window{
+			End: time.Now().Unix(),
+		},
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Operator: watcher.Average,
+							Value:    70,
+						},
+						{
+							Type:     watcher.Memory,
+							Operator: watcher.Average,
+							Value:    40,
+						},
+					},
+				},
+			},
+		},
+	}
+	server := newWatcherServer(t, watcherResponse)
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		pod          *v1.Pod
+		boundPods    []*v1.Pod
+		expectedCode framework.Code
+		expectedMsg  string
+	}{
+		{
+			name:         "predicted utilization below ceilings",
+			pod:          st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "200m"}).Obj(),
+			expectedCode: framework.Success,
+		},
+		{
+			name:         "predicted CPU utilization above ceiling",
+			pod:          st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "600m"}).Obj(),
+			expectedCode: framework.UnschedulableAndUnresolvable,
+			expectedMsg:  fmt.Sprintf(ErrReasonUtilizationCeiling, v1.ResourceCPU),
+		},
+		{
+			name: "recently bound pod pushes predicted CPU utilization above ceiling",
+			pod:  st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "200m"}).Obj(),
+			boundPods: []*v1.Pod{
+				st.MakePod().Name("bound").Node("node-1").Req(map[v1.ResourceName]string{v1.ResourceCPU: "400m"}).Obj(),
+			},
+			expectedCode: framework.UnschedulableAndUnresolvable,
+			expectedMsg:  fmt.Sprintf(ErrReasonUtilizationCeiling, v1.ResourceCPU),
+		},
+		{
+			name: "pod recently bound to another node",
+			pod:  st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "200m"}).Obj(),
+			boundPods: []*v1.Pod{
+				st.MakePod().Name("bound").Node("node-2").Req(map[v1.ResourceName]string{v1.ResourceCPU: "400m"}).Obj(),
+			},
+			expectedCode: framework.Success,
+		},
+		{
+			name:         "predicted memory utilization above ceiling",
+			pod:          st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceMemory: "5Gi"}).Obj(),
+			expectedCode: framework.UnschedulableAndUnresolvable,
+			expectedMsg:  fmt.Sprintf(ErrReasonUtilizationCeiling, v1.ResourceMemory),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			args := &pluginConfig.LoadCeilingArgs{
+				TrimaranSpec: pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+				UtilizationCeilings: map[v1.ResourceName]int64{
+					v1.ResourceCPU:    80,
+					v1.ResourceMemory: 90,
+				},
+			}
+			p, err := newTestPlugin(ctx, t, args)
+			assert.Nil(t, err)
+			for _, boundPod := range tt.boundPods {
+				p.eventHandler.OnAdd(boundPod, false)
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(ctx, framework.NewCycleState(), tt.pod, nodeInfo)
+			assert.Equal(t, tt.expectedCode, status.Code())
+			assert.Equal(t, tt.expectedMsg, status.Message())
+		})
+	}
+}
+
+func TestFilterStaleMetrics(t *testing.T) {
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "4000m",
+		v1.ResourceMemory: "8Gi",
+	}
+	node := st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()
+	nodePod := st.MakePod().Name("node-pod").Node("node-1").Req(map[v1.ResourceName]string{v1.ResourceCPU: "3000m"}).Obj()
+
+	// The metrics were collected 10 minutes ago, and are stale after 1 minute.
+	watcherResponse := watcher.WatcherMetrics{
+		Window: watcher.Window{
+			End: time.Now().Add(-10 * time.Minute).Unix(),
+		},
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Operator: watcher.Average,
+							Value:    0,
+						},
+					},
+				},
+			},
+		},
+	}
+	server := newWatcherServer(t, watcherResponse)
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		policy       pluginConfig.StaleMetricsPolicyType
+		expectedCode framework.Code
+		expectedMsg  string
+	}{
+		{
+			// Three quarters of the CPU are requested on the node, and the pod requests a tenth of it.
+			name:         "fallback to requests",
+			policy:       pluginConfig.StaleMetricsFallbackToRequests,
+			expectedCode: framework.UnschedulableAndUnresolvable,
+			expectedMsg:  fmt.Sprintf(ErrReasonUtilizationCeiling, v1.ResourceCPU),
+		},
+		{
+			name:         "neutral score",
+			policy:       pluginConfig.StaleMetricsNeutralScore,
+			expectedCode: framework.Success,
+		},
+		{
+			name:         "filter",
+			policy:       pluginConfig.StaleMetricsFilter,
+			expectedCode: framework.UnschedulableAndUnresolvable,
+			expectedMsg:  trimaran.ErrReasonStaleMetrics,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			args := &pluginConfig.LoadCeilingArgs{
+				TrimaranSpec: pluginConfig.TrimaranSpec{
+					WatcherAddress:                   server.URL,
+					MetricsStalenessThresholdSeconds: 60,
+					StaleMetricsPolicy:               tt.policy,
+				},
+				UtilizationCeilings: map[v1.ResourceName]int64{
+					v1.ResourceCPU: 80,
+				},
+			}
+			p, err := newTestPlugin(ctx, t, args)
+			assert.Nil(t, err)
+
+			nodeInfo := framework.NewNodeInfo(nodePod)
+			nodeInfo.SetNode(node)
+			pod := st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "400m"}).Obj()
+			status := p.Filter(ctx, framework.NewCycleState(), pod, nodeInfo)
+			assert.Equal(t, tt.expectedCode, status.Code())
+			assert.Equal(t, tt.expectedMsg, status.Message())
+		})
+	}
+}
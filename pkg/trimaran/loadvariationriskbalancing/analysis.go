@@ -32,9 +32,19 @@ Calculation of risk score for resources given measured data
 // - risk = [ average + margin * stDev^{1/sensitivity} ] / 2
 // - score = ( 1 - risk ) * maxScore
 func computeScore(rs *trimaran.ResourceStats, margin float64, sensitivity float64) float64 {
+	return computeRisk(rs, margin, sensitivity).Score
+}
+
+// computeRisk : compute the breakdown of the risk and the score given usage statistics
+func computeRisk(rs *trimaran.ResourceStats, margin float64, sensitivity float64) *ResourceRisk {
+	resourceRisk := &ResourceRisk{
+		Margin:      margin,
+		Sensitivity: sensitivity,
+	}
 	if rs.Capacity <= 0 {
 		klog.ErrorS(nil, "Invalid resource capacity", "capacity", rs.Capacity)
-		return 0
+		resourceRisk.Risk = 1
+		return resourceRisk
 	}
 
 	// make sure values are within bounds
@@ -44,6 +54,8 @@ func computeScore(rs *trimaran.ResourceStats, margin float64, sensitivity float6
 
 	// calculate average and deviation factors
 	mu, sigma := trimaran.GetMuSigma(rs)
+	resourceRisk.Mu = mu
+	resourceRisk.Sigma = sigma
 
 	// apply root power
 	if sensitivity >= 0 {
@@ -52,9 +64,12 @@ func computeScore(rs *trimaran.ResourceStats, margin float64, sensitivity float6
 	// apply multiplier
 	sigma *= margin
 	sigma = math.Max(math.Min(sigma, 1), 0)
+	resourceRisk.AdjustedSigma = sigma
 
 	// evaluate overall risk factor
 	risk := (mu + sigma) / 2
 	klog.V(6).InfoS("Evaluating risk factor", "mu", mu, "sigma", sigma, "margin", margin, "sensitivity", sensitivity, "risk", risk)
-	return (1. - risk) * float64(framework.MaxNodeScore)
+	resourceRisk.Risk = risk
+	resourceRisk.Score = (1. - risk) * float64(framework.MaxNodeScore)
+	return resourceRisk
 }
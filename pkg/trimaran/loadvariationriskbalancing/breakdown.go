@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadvariationriskbalancing
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	// ScoreBreakdownKey : state key of the score breakdowns in CycleState
+	ScoreBreakdownKey = Name + ".ScoreBreakdown"
+
+	// DebugScoresPath : path of the debug endpoints serving the score breakdowns of the latest scored pods,
+	// followed by the name of the scheduler profile
+	DebugScoresPath = "/debug/loadvariationriskbalancing/scores"
+
+	// maxRecordedPods : number of latest scored pods whose score breakdowns are kept for the debug endpoint
+	maxRecordedPods = 20
+)
+
+// ResourceRisk : breakdown of the risk of a resource on a node
+type ResourceRisk struct {
+	// average utilization, including the pod requests, as a fraction of the capacity
+	Mu float64 `json:"mu"`
+	// standard deviation of the utilization, as a fraction of the capacity
+	Sigma float64 `json:"sigma"`
+	// standard deviation after applying the sensitivity and margin
+	AdjustedSigma float64 `json:"adjustedSigma"`
+	Margin        float64 `json:"margin"`
+	Sensitivity   float64 `json:"sensitivity"`
+	// risk of the resource, (mu + adjustedSigma) / 2
+	Risk float64 `json:"risk"`
+	// score of the resource, (1 - risk) * maxScore
+	Score float64 `json:"score"`
+}
+
+// NodeScoreBreakdown : breakdown of the score of a node
+type NodeScoreBreakdown struct {
+	// risks of the resources with metrics
+	Resources map[v1.ResourceName]ResourceRisk `json:"resources,omitempty"`
+	// resource whose risk determined the score
+	DominantResource v1.ResourceName `json:"dominantResource,omitempty"`
+	// reason of a score not based on the risks, e.g. stale metrics
+	Reason string `json:"reason,omitempty"`
+	Score  int64  `json:"score"`
+}
+
+// setReason : set the reason of a score not based on the risks
+func (b *NodeScoreBreakdown) setReason(reason string) {
+	if b == nil {
+		return
+	}
+	b.Reason = reason
+}
+
+// addResource : add the risk of a resource
+func (b *NodeScoreBreakdown) addResource(resource v1.ResourceName, risk *ResourceRisk) {
+	if b == nil {
+		return
+	}
+	b.Resources[resource] = *risk
+}
+
+// setDominantResource : set the resource whose risk determined the score
+func (b *NodeScoreBreakdown) setDominantResource(resource v1.ResourceName) {
+	if b == nil {
+		return
+	}
+	b.DominantResource = resource
+}
+
+// ScoreBreakdownStateData : score breakdowns of the nodes, created at PreScore, recorded at Score and reported
+// at NormalizeScore
+type ScoreBreakdownStateData struct {
+	// score breakdowns, by node name
+	nodes map[string]*NodeScoreBreakdown
+	// for safe access to nodes, as the nodes are scored in parallel
+	mu sync.Mutex
+}
+
+// Clone : clone the score breakdown state data
+func (s *ScoreBreakdownStateData) Clone() framework.StateData {
+	return s
+}
+
+// record : record the score breakdown of a node
+func (s *ScoreBreakdownStateData) record(nodeName string, breakdown *NodeScoreBreakdown) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes[nodeName] = breakdown
+}
+
+// snapshot : get a copy of the score breakdowns, by node name
+func (s *ScoreBreakdownStateData) snapshot() map[string]*NodeScoreBreakdown {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	nodes := make(map[string]*NodeScoreBreakdown, len(s.nodes))
+	for nodeName, breakdown := range s.nodes {
+		nodes[nodeName] = breakdown
+	}
+	return nodes
+}
+
+// PodScoreBreakdown : score breakdowns of the nodes scored for a pod
+type PodScoreBreakdown struct {
+	Namespace string                         `json:"namespace"`
+	Name      string                         `json:"name"`
+	UID       types.UID                      `json:"uid"`
+	Time      time.Time                      `json:"time"`
+	Nodes     map[string]*NodeScoreBreakdown `json:"nodes"`
+}
+
+// scoreBreakdownRecorder : score breakdowns of the latest scored pods, served by the debug endpoint
+type scoreBreakdownRecorder struct {
+	// latest scored pods, from the oldest to the newest
+	pods []*PodScoreBreakdown
+	// for safe access to pods
+	mu sync.RWMutex
+}
+
+// record : record the score breakdowns of a pod, forgetting the oldest pod beyond maxRecordedPods
+func (r *scoreBreakdownRecorder) record(breakdown *PodScoreBreakdown) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pods) >= maxRecordedPods {
+		r.pods = r.pods[len(r.pods)-maxRecordedPods+1:]
+	}
+	r.pods = append(r.pods, breakdown)
+}
+
+// ServeHTTP : serve the score breakdowns of the latest scored pods as JSON, from the newest to the oldest,
+// optionally restricted to the pods of the namespace and name given as query parameters
+func (r *scoreBreakdownRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	namespace := req.URL.Query().Get("namespace")
+	name := req.URL.Query().Get("name")
+	r.mu.RLock()
+	pods := make([]*PodScoreBreakdown, 0, len(r.pods))
+	for i := len(r.pods) - 1; i >= 0; i-- {
+		p := r.pods[i]
+		if (namespace == "" || p.Namespace == namespace) && (name == "" || p.Name == name) {
+			pods = append(pods, p)
+		}
+	}
+	r.mu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(pods); err != nil {
+		klog.ErrorS(err, "Unable to encode score breakdowns")
+	}
+}
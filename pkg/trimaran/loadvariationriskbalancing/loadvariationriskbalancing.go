@@ -24,6 +24,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 
@@ -34,6 +35,7 @@ import (
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
 const (
@@ -47,25 +49,35 @@ type LoadVariationRiskBalancing struct {
 	eventHandler *trimaran.PodAssignEventHandler
 	collector    *trimaran.Collector
 	args         *pluginConfig.LoadVariationRiskBalancingArgs
+	// score breakdowns of the latest scored pods, nil if the debug endpoint is disabled
+	recorder *scoreBreakdownRecorder
 }
 
+var _ framework.PreScorePlugin = &LoadVariationRiskBalancing{}
 var _ framework.ScorePlugin = &LoadVariationRiskBalancing{}
+var _ framework.FilterPlugin = &LoadVariationRiskBalancing{}
 
 // New : create an instance of a LoadVariationRiskBalancing plugin
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the LoadVariationRiskBalancing plugin")
 	// cast object into plugin arguments object
 	args, ok := obj.(*pluginConfig.LoadVariationRiskBalancingArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type LoadVariationRiskBalancingArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(&args.TrimaranSpec)
+	if args.MaxSafeVarianceMargin != 0 && (args.MinSafeVarianceMargin < 0 ||
+		args.MinSafeVarianceMargin > args.MaxSafeVarianceMargin) {
+		return nil, fmt.Errorf("invalid safe variance margin bounds, got %v and %v",
+			args.MinSafeVarianceMargin, args.MaxSafeVarianceMargin)
+	}
+	collector, err := trimaran.GetCollector(ctx, &args.TrimaranSpec)
 	if err != nil {
 		return nil, err
 	}
-	klog.V(4).InfoS("Using LoadVariationRiskBalancingArgs", "margin", args.SafeVarianceMargin, "sensitivity", args.SafeVarianceSensitivity)
+	klog.V(4).InfoS("Using LoadVariationRiskBalancingArgs", "margin", args.SafeVarianceMargin, "sensitivity", args.SafeVarianceSensitivity,
+		"debugAddress", args.DebugAddress, "minMargin", args.MinSafeVarianceMargin, "maxMargin", args.MaxSafeVarianceMargin)
 
-	podAssignEventHandler := trimaran.New()
+	podAssignEventHandler := trimaran.New(ctx, &args.TrimaranSpec)
 	podAssignEventHandler.AddToHandle(handle)
 
 	pl := &LoadVariationRiskBalancing{
@@ -74,38 +86,86 @@ func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framew
 		collector:    collector,
 		args:         args,
 	}
+	if args.DebugAddress != "" {
+		pl.recorder = &scoreBreakdownRecorder{}
+		if err := util.ServeDebug(ctx, klog.FromContext(ctx), args.DebugAddress, util.ProfileDebugPath(DebugScoresPath, handle), pl.recorder); err != nil {
+			return nil, err
+		}
+	}
 	return pl, nil
 }
 
+// PreScore : create the score breakdowns in CycleState, if they are recorded or logged
+func (pl *LoadVariationRiskBalancing) PreScore(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodes []*framework.NodeInfo) *framework.Status {
+	if pl.recorder != nil || klog.V(5).Enabled() {
+		cycleState.Write(ScoreBreakdownKey, &ScoreBreakdownStateData{nodes: make(map[string]*NodeScoreBreakdown, len(nodes))})
+	}
+	return nil
+}
+
 // Score : evaluate score for a node
-func (pl *LoadVariationRiskBalancing) Score(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+func (pl *LoadVariationRiskBalancing) Score(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) (score int64, status *framework.Status) {
 	klog.V(6).InfoS("Calculating score", "pod", klog.KObj(pod), "nodeName", nodeName)
-	score := framework.MinNodeScore
+	score = framework.MinNodeScore
+	// the breakdown is nil, and collects nothing, if the score breakdowns are not kept
+	var breakdown *NodeScoreBreakdown
+	if breakdowns, err := getScoreBreakdownState(cycleState); err == nil {
+		breakdown = &NodeScoreBreakdown{Resources: make(map[v1.ResourceName]ResourceRisk)}
+		defer func() {
+			breakdown.Score = score
+			breakdowns.record(nodeName, breakdown)
+		}()
+	}
+
 	nodeInfo, err := pl.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
 	if err != nil {
+		breakdown.setReason("node not found")
 		return score, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
 	// get node metrics
-	metrics, _ := pl.collector.GetNodeMetrics(nodeName)
+	metrics, _ := pl.collector.GetPredictedNodeMetrics(nodeName)
 	if metrics == nil {
-		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
-		return score, nil
+		switch pl.args.StaleMetricsPolicy {
+		case pluginConfig.StaleMetricsNeutralScore:
+			klog.InfoS("Failed to get fresh metrics for node; using neutral score", "nodeName", nodeName)
+			breakdown.setReason("stale metrics; neutral score")
+			score = trimaran.NeutralScore
+			return score, nil
+		case pluginConfig.StaleMetricsFallbackToRequests:
+			klog.InfoS("Failed to get fresh metrics for node; using requests", "nodeName", nodeName)
+			breakdown.setReason("stale metrics; requests used as utilization")
+			metrics = trimaran.GetRequestedMetrics(nodeInfo)
+		default:
+			klog.InfoS("Failed to get fresh metrics for node; using minimum score", "nodeName", nodeName)
+			breakdown.setReason("stale metrics; minimum score")
+			return score, nil
+		}
 	}
 	podRequest := trimaran.GetResourceRequested(pod)
 	node := nodeInfo.Node()
+	// the pod may override the margin, within the bounds of the args
+	margin := pl.args.SafeVarianceMargin
+	if marginOverride, ok := trimaran.GetArgOverride(pod, trimaran.AnnotationKeySafeVarianceMargin,
+		pl.args.MinSafeVarianceMargin, pl.args.MaxSafeVarianceMargin); ok {
+		margin = marginOverride
+	}
 
 	// calculate CPU score
 	var cpuScore float64 = 0
 	cpuStats, cpuOK := trimaran.CreateResourceStats(metrics, node, podRequest, v1.ResourceCPU, watcher.CPU)
 	if cpuOK {
-		cpuScore = computeScore(cpuStats, pl.args.SafeVarianceMargin, pl.args.SafeVarianceSensitivity)
+		cpuRisk := computeRisk(cpuStats, margin, pl.args.SafeVarianceSensitivity)
+		breakdown.addResource(v1.ResourceCPU, cpuRisk)
+		cpuScore = cpuRisk.Score
 	}
 	klog.V(6).InfoS("Calculating CPUScore", "pod", klog.KObj(pod), "nodeName", nodeName, "cpuScore", cpuScore)
 	// calculate Memory score
 	var memoryScore float64 = 0
 	memoryStats, memoryOK := trimaran.CreateResourceStats(metrics, node, podRequest, v1.ResourceMemory, watcher.Memory)
 	if memoryOK {
-		memoryScore = computeScore(memoryStats, pl.args.SafeVarianceMargin, pl.args.SafeVarianceSensitivity)
+		memoryRisk := computeRisk(memoryStats, margin, pl.args.SafeVarianceSensitivity)
+		breakdown.addResource(v1.ResourceMemory, memoryRisk)
+		memoryScore = memoryRisk.Score
 	}
 	klog.V(6).InfoS("Calculating MemoryScore", "pod", klog.KObj(pod), "nodeName", nodeName, "memoryScore", memoryScore)
 	// calculate total score
@@ -115,11 +175,23 @@ func (pl *LoadVariationRiskBalancing) Score(ctx context.Context, cycleState *fra
 	} else {
 		totalScore = math.Max(memoryScore, cpuScore)
 	}
+	// the resource with the highest risk determines the score
+	switch {
+	case cpuOK && (!memoryOK || cpuScore <= memoryScore):
+		breakdown.setDominantResource(v1.ResourceCPU)
+	case memoryOK:
+		breakdown.setDominantResource(v1.ResourceMemory)
+	}
 	score = int64(math.Round(totalScore))
 	klog.V(6).InfoS("Calculating totalScore", "pod", klog.KObj(pod), "nodeName", nodeName, "totalScore", score)
 	return score, framework.NewStatus(framework.Success, "")
 }
 
+// Filter : filter out the node if its metrics are missing or stale and the StaleMetricsPolicy is Filter
+func (pl *LoadVariationRiskBalancing) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	return trimaran.FilterStaleNode(pl.collector, pl.args.StaleMetricsPolicy, nodeInfo.Node().Name)
+}
+
 // Name : name of plugin
 func (pl *LoadVariationRiskBalancing) Name() string {
 	return Name
@@ -130,7 +202,45 @@ func (pl *LoadVariationRiskBalancing) ScoreExtensions() framework.ScoreExtension
 	return pl
 }
 
-// NormalizeScore : normalize scores
-func (pl *LoadVariationRiskBalancing) NormalizeScore(context.Context, *framework.CycleState, *v1.Pod, framework.NodeScoreList) *framework.Status {
+// NormalizeScore : normalize scores; the scores are not changed, but their breakdowns are logged and recorded
+func (pl *LoadVariationRiskBalancing) NormalizeScore(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	breakdowns, err := getScoreBreakdownState(cycleState)
+	if err != nil {
+		klog.V(6).InfoS(err.Error()+"; skipping score breakdowns", "pod", klog.KObj(pod))
+		return nil
+	}
+	nodes := breakdowns.snapshot()
+	if loggerV := klog.V(5); loggerV.Enabled() {
+		for _, nodeScore := range scores {
+			breakdown, ok := nodes[nodeScore.Name]
+			if !ok {
+				continue
+			}
+			loggerV.InfoS("Score breakdown", "pod", klog.KObj(pod), "nodeName", nodeScore.Name, "score", breakdown.Score,
+				"dominantResource", breakdown.DominantResource, "reason", breakdown.Reason, "resources", breakdown.Resources)
+		}
+	}
+	if pl.recorder != nil {
+		pl.recorder.record(&PodScoreBreakdown{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+			Time:      time.Now(),
+			Nodes:     nodes,
+		})
+	}
 	return nil
 }
+
+// getScoreBreakdownState : retrieve the score breakdowns from CycleState
+func getScoreBreakdownState(cycleState *framework.CycleState) (*ScoreBreakdownStateData, error) {
+	stateData, err := cycleState.Read(ScoreBreakdownKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q from cycleState: %w", ScoreBreakdownKey, err)
+	}
+	breakdowns, ok := stateData.(*ScoreBreakdownStateData)
+	if !ok {
+		return nil, fmt.Errorf("invalid score breakdown state, got type %T", stateData)
+	}
+	return breakdowns, nil
+}
@@ -19,10 +19,12 @@ package loadvariationriskbalancing
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	"github.com/stretchr/testify/assert"
@@ -41,6 +43,7 @@ import (
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	cfgv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
 	testutil "sigs.k8s.io/scheduler-plugins/test/util"
 )
 
@@ -127,6 +130,58 @@ func TestNew(t *testing.T) {
 	badp, err = New(ctx, &badArgs, fh)
 	assert.NotNil(t, badp)
 	assert.Nil(t, err)
+
+	// invalid bounds of the margin overridden by the pods
+	badArgs.MinSafeVarianceMargin, badArgs.MaxSafeVarianceMargin = 3, 2
+	_, err = New(ctx, &badArgs, fh)
+	assert.Error(t, err)
+}
+
+func TestNewDebugAddressPerProfile(t *testing.T) {
+	watcherResponse := watcher.WatcherMetrics{}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.Nil(t, err)
+	debugAddress := listener.Addr().String()
+	listener.Close()
+
+	// the profiles enabling the plugin serve their score breakdowns on the same address
+	profiles := []string{"profile-a", "profile-b"}
+	for _, profile := range profiles {
+		loadVariationRiskBalancingArgs := pluginConfig.LoadVariationRiskBalancingArgs{
+			TrimaranSpec:            pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+			SafeVarianceMargin:      cfgv1.DefaultSafeVarianceMargin,
+			SafeVarianceSensitivity: cfgv1.DefaultSafeVarianceSensitivity,
+			DebugAddress:            debugAddress,
+		}
+		registeredPlugins := []tf.RegisterPluginFunc{
+			tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		}
+		cs := testClientSet.NewSimpleClientset()
+		informerFactory := informers.NewSharedInformerFactory(cs, 0)
+		fh, err := testutil.NewFramework(ctx, registeredPlugins, nil, profile,
+			runtime.WithClientSet(cs), runtime.WithInformerFactory(informerFactory))
+		assert.Nil(t, err)
+		_, err = New(ctx, &loadVariationRiskBalancingArgs, fh)
+		assert.Nil(t, err, "profile %v", profile)
+	}
+
+	for _, profile := range profiles {
+		resp, err := http.Get("http://" + debugAddress + DebugScoresPath + "/" + profile)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "profile %v", profile)
+	}
 }
 
 func TestScore(t *testing.T) {
@@ -377,6 +432,363 @@ func TestScore(t *testing.T) {
 	}
 }
 
+func TestScoreStaleMetrics(t *testing.T) {
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	var mega int64 = 1024 * 1024
+	nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+	nodePod := getPodWithContainersAndOverhead(0, 0, 0, []int64{500}, []int64{256 * mega})
+	nodePod.Spec.NodeName = "node-1"
+
+	// The metrics were collected 10 minutes ago, and are stale after 1 minute.
+	watcherResponse := watcher.WatcherMetrics{
+		Window: watcher.Window{
+			End: time.Now().Add(-10 * time.Minute).Unix(),
+		},
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Operator: watcher.Average,
+							Value:    0,
+						},
+					},
+				},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+
+	tests := []struct {
+		test           string
+		policy         pluginConfig.StaleMetricsPolicyType
+		expectedScore  int64
+		expectedFilter framework.Code
+	}{
+		{
+			test:   "fallback to requests",
+			policy: pluginConfig.StaleMetricsFallbackToRequests,
+			// Half of the CPU and a quarter of the memory are requested on the node: the CPU risk is the highest.
+			expectedScore:  75,
+			expectedFilter: framework.Success,
+		},
+		{
+			test:           "neutral score",
+			policy:         pluginConfig.StaleMetricsNeutralScore,
+			expectedScore:  50,
+			expectedFilter: framework.Success,
+		},
+		{
+			test:           "filter",
+			policy:         pluginConfig.StaleMetricsFilter,
+			expectedScore:  framework.MinNodeScore,
+			expectedFilter: framework.UnschedulableAndUnresolvable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			loadVariationRiskBalancingArgs := pluginConfig.LoadVariationRiskBalancingArgs{
+				TrimaranSpec: pluginConfig.TrimaranSpec{
+					WatcherAddress:                   server.URL,
+					MetricsStalenessThresholdSeconds: 60,
+					StaleMetricsPolicy:               tt.policy,
+				},
+				SafeVarianceMargin:      cfgv1.DefaultSafeVarianceMargin,
+				SafeVarianceSensitivity: cfgv1.DefaultSafeVarianceSensitivity,
+			}
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister([]*v1.Pod{nodePod}, nodes)
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{{Name: Name, Args: &loadVariationRiskBalancingArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &loadVariationRiskBalancingArgs, fh)
+			assert.Nil(t, err)
+
+			pod := st.MakePod().Name("p").Obj()
+			score, status := p.(framework.ScorePlugin).Score(ctx, framework.NewCycleState(), pod, "node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expectedScore, score)
+
+			nodeInfo, err := snapshot.Get("node-1")
+			assert.Nil(t, err)
+			status = p.(framework.FilterPlugin).Filter(ctx, framework.NewCycleState(), pod, nodeInfo)
+			assert.Equal(t, tt.expectedFilter, status.Code())
+		})
+	}
+}
+
+func TestScoreSafeVarianceMarginOverride(t *testing.T) {
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+	// risk = (0.4 + margin * 0.2) / 2
+	watcherResponse := watcher.WatcherMetrics{
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Operator: watcher.Average,
+							Value:    40,
+						},
+						{
+							Type:     watcher.CPU,
+							Operator: watcher.Std,
+							Value:    20,
+						},
+					},
+				},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+
+	tests := []struct {
+		test        string
+		annotations map[string]string
+		maxMargin   float64
+		expected    int64
+	}{
+		{
+			test:        "margin overridden",
+			annotations: map[string]string{trimaran.AnnotationKeySafeVarianceMargin: "0"},
+			maxMargin:   2,
+			expected:    80,
+		},
+		{
+			test:        "margin overridden up to the max",
+			annotations: map[string]string{trimaran.AnnotationKeySafeVarianceMargin: "3"},
+			maxMargin:   2,
+			expected:    60,
+		},
+		{
+			test:        "invalid annotation",
+			annotations: map[string]string{trimaran.AnnotationKeySafeVarianceMargin: "low"},
+			maxMargin:   2,
+			expected:    70,
+		},
+		{
+			test:        "overrides disabled",
+			annotations: map[string]string{trimaran.AnnotationKeySafeVarianceMargin: "0"},
+			expected:    70,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			loadVariationRiskBalancingArgs := pluginConfig.LoadVariationRiskBalancingArgs{
+				TrimaranSpec:            pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+				SafeVarianceMargin:      cfgv1.DefaultSafeVarianceMargin,
+				SafeVarianceSensitivity: cfgv1.DefaultSafeVarianceSensitivity,
+				MaxSafeVarianceMargin:   tt.maxMargin,
+			}
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister(nil, nodes)
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{{Name: Name, Args: &loadVariationRiskBalancingArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &loadVariationRiskBalancingArgs, fh)
+			assert.Nil(t, err)
+
+			pod := st.MakePod().Name("p").Annotations(tt.annotations).Obj()
+			score, status := p.(framework.ScorePlugin).Score(ctx, framework.NewCycleState(), pod, "node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expected, score)
+		})
+	}
+}
+
+func TestScoreBreakdown(t *testing.T) {
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-1").Capacity(nodeResources).Obj(),
+		st.MakeNode().Name("node-2").Capacity(nodeResources).Obj(),
+	}
+	watcherResponse := watcher.WatcherMetrics{
+		Window: watcher.Window{
+			End: time.Now().Unix(),
+		},
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{Type: watcher.CPU, Operator: watcher.Average, Value: 60},
+						{Type: watcher.Memory, Operator: watcher.Average, Value: 10},
+					},
+				},
+				"node-2": {
+					Metrics: []watcher.Metric{
+						{Type: watcher.CPU, Operator: watcher.Average, Value: 10},
+						{Type: watcher.Memory, Operator: watcher.Average, Value: 80},
+						{Type: watcher.Memory, Operator: watcher.Std, Value: 20},
+					},
+				},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	loadVariationRiskBalancingArgs := pluginConfig.LoadVariationRiskBalancingArgs{
+		TrimaranSpec:            pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+		SafeVarianceMargin:      1,
+		SafeVarianceSensitivity: 1,
+	}
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	cs := testClientSet.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	snapshot := newTestSharedLister(nil, nodes)
+	fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{{Name: Name, Args: &loadVariationRiskBalancingArgs}},
+		"default-scheduler", runtime.WithClientSet(cs),
+		runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+	assert.Nil(t, err)
+	p, err := New(ctx, &loadVariationRiskBalancingArgs, fh)
+	assert.Nil(t, err)
+	pl := p.(*LoadVariationRiskBalancing)
+	pod := st.MakePod().Namespace("default").Name("p").Obj()
+
+	// the breakdowns are not kept if they are neither recorded nor logged
+	state := framework.NewCycleState()
+	assert.Nil(t, pl.PreScore(ctx, state, pod, nil))
+	for _, node := range nodes {
+		_, status := pl.Score(ctx, state, pod, node.Name)
+		assert.True(t, status.IsSuccess())
+	}
+	_, err = getScoreBreakdownState(state)
+	assert.NotNil(t, err)
+
+	pl.recorder = &scoreBreakdownRecorder{}
+	state = framework.NewCycleState()
+	assert.Nil(t, pl.PreScore(ctx, state, pod, nil))
+	var scores framework.NodeScoreList
+	for _, node := range nodes {
+		score, status := pl.Score(ctx, state, pod, node.Name)
+		assert.True(t, status.IsSuccess())
+		scores = append(scores, framework.NodeScore{Name: node.Name, Score: score})
+	}
+	assert.Equal(t, framework.NodeScoreList{{Name: "node-1", Score: 70}, {Name: "node-2", Score: 50}}, scores)
+
+	breakdowns, err := getScoreBreakdownState(state)
+	assert.Nil(t, err)
+	expected := map[string]*NodeScoreBreakdown{
+		"node-1": {
+			Resources: map[v1.ResourceName]ResourceRisk{
+				v1.ResourceCPU:    {Mu: 0.6, Margin: 1, Sensitivity: 1, Risk: 0.3, Score: 70},
+				v1.ResourceMemory: {Mu: 0.1, Margin: 1, Sensitivity: 1, Risk: 0.05, Score: 95},
+			},
+			DominantResource: v1.ResourceCPU,
+			Score:            70,
+		},
+		"node-2": {
+			Resources: map[v1.ResourceName]ResourceRisk{
+				v1.ResourceCPU:    {Mu: 0.1, Margin: 1, Sensitivity: 1, Risk: 0.05, Score: 95},
+				v1.ResourceMemory: {Mu: 0.8, Sigma: 0.2, AdjustedSigma: 0.2, Margin: 1, Sensitivity: 1, Risk: 0.5, Score: 50},
+			},
+			DominantResource: v1.ResourceMemory,
+			Score:            50,
+		},
+	}
+	nodeBreakdowns := breakdowns.snapshot()
+	assert.Equal(t, len(expected), len(nodeBreakdowns))
+	for nodeName, e := range expected {
+		b := nodeBreakdowns[nodeName]
+		assert.NotNil(t, b, nodeName)
+		assert.Equal(t, e.DominantResource, b.DominantResource, nodeName)
+		assert.Equal(t, e.Score, b.Score, nodeName)
+		for r, risk := range e.Resources {
+			assert.InDelta(t, risk.Mu, b.Resources[r].Mu, 1e-9, nodeName)
+			assert.InDelta(t, risk.Sigma, b.Resources[r].Sigma, 1e-9, nodeName)
+			assert.InDelta(t, risk.AdjustedSigma, b.Resources[r].AdjustedSigma, 1e-9, nodeName)
+			assert.InDelta(t, risk.Risk, b.Resources[r].Risk, 1e-9, nodeName)
+			assert.InDelta(t, risk.Score, b.Resources[r].Score, 1e-9, nodeName)
+		}
+	}
+
+	// the breakdowns are recorded at NormalizeScore and served by the debug endpoint
+	status := pl.NormalizeScore(ctx, state, pod, scores)
+	assert.True(t, status.IsSuccess())
+	for _, tt := range []struct {
+		query    string
+		expected int
+	}{
+		{query: "", expected: 1},
+		{query: "?namespace=default&name=p", expected: 1},
+		{query: "?namespace=other", expected: 0},
+	} {
+		resp := httptest.NewRecorder()
+		pl.recorder.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, DebugScoresPath+tt.query, nil))
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var served []PodScoreBreakdown
+		assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &served))
+		assert.Len(t, served, tt.expected, tt.query)
+		if len(served) > 0 {
+			assert.Equal(t, "p", served[0].Name)
+			assert.Equal(t, v1.ResourceMemory, served[0].Nodes["node-2"].DominantResource)
+		}
+	}
+}
+
+func TestScoreBreakdownRecorder(t *testing.T) {
+	recorder := &scoreBreakdownRecorder{}
+	for i := 0; i < maxRecordedPods+5; i++ {
+		recorder.record(&PodScoreBreakdown{Name: "p" + strconv.Itoa(i)})
+	}
+	assert.Len(t, recorder.pods, maxRecordedPods)
+	assert.Equal(t, "p5", recorder.pods[0].Name)
+	assert.Equal(t, "p"+strconv.Itoa(maxRecordedPods+4), recorder.pods[maxRecordedPods-1].Name)
+}
+
 func newTestSharedLister(pods []*v1.Pod, nodes []*v1.Node) *testSharedLister {
 	nodeInfoMap := make(map[string]*framework.NodeInfo)
 	nodeInfos := make([]*framework.NodeInfo, 0)
@@ -25,6 +25,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 
@@ -47,6 +48,9 @@ const (
 
 	// State key used in CycleState
 	PodResourcesKey = Name + ".PodResources"
+
+	// ErrReasonMaxOvercommitRatio : reason of the nodes filtered out, formatted with the name of the resource
+	ErrReasonMaxOvercommitRatio = "node(s) with %v limits overcommitted beyond the maximum ratio"
 )
 
 // LowRiskOverCommitment : scheduler plugin
@@ -55,17 +59,24 @@ type LowRiskOverCommitment struct {
 	collector           *trimaran.Collector
 	args                *pluginConfig.LowRiskOverCommitmentArgs
 	riskLimitWeightsMap map[v1.ResourceName]float64
+	// resources whose risk is evaluated, sorted by name
+	resources []v1.ResourceName
+	// maximum ratio of the limits to the allocatable, by resource
+	maxOvercommitRatios map[v1.ResourceName]float64
 }
 
+var _ framework.ScorePlugin = &LowRiskOverCommitment{}
+var _ framework.FilterPlugin = &LowRiskOverCommitment{}
+
 // New : create an instance of a LowRiskOverCommitment plugin
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the LowRiskOverCommitment plugin")
 	// cast object into plugin arguments object
 	args, ok := obj.(*pluginConfig.LowRiskOverCommitmentArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type LowRiskOverCommitmentArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(&args.TrimaranSpec)
+	collector, err := trimaran.GetCollector(ctx, &args.TrimaranSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -76,14 +87,28 @@ func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framew
 	for r, w := range args.RiskLimitWeights {
 		m[r] = w
 	}
+	resources := make([]v1.ResourceName, 0, len(m))
+	for r := range m {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i] < resources[j]
+	})
+	for r, ratio := range args.MaxOvercommitRatios {
+		if ratio <= 0 {
+			return nil, fmt.Errorf("invalid maximum overcommit ratio of resource %v, got %v", r, ratio)
+		}
+	}
 	klog.V(4).InfoS("Using LowRiskOverCommitmentArgs", "smoothingWindowSize", args.SmoothingWindowSize,
-		"riskLimitWeights", m)
+		"riskLimitWeights", m, "maxOvercommitRatios", args.MaxOvercommitRatios)
 
 	pl := &LowRiskOverCommitment{
 		handle:              handle,
 		collector:           collector,
 		args:                args,
 		riskLimitWeightsMap: m,
+		resources:           resources,
+		maxOvercommitRatios: args.MaxOvercommitRatios,
 	}
 	return pl, nil
 }
@@ -115,8 +140,7 @@ func (pl *LowRiskOverCommitment) Score(ctx context.Context, cycleState *framewor
 	// exclude scoring for best effort pods; this plugin is not concerned about best effort pods
 	podRequests := &podResources.podRequests
 	podLimits := &podResources.podLimits
-	if podRequests.MilliCPU == 0 && podRequests.Memory == 0 &&
-		podLimits.MilliCPU == 0 && podLimits.Memory == 0 {
+	if pl.isBestEffort(podRequests, podLimits) {
 		klog.V(6).InfoS("Skipping scoring best effort pod; using minimum score", "nodeName", nodeName, "pod", klog.KObj(pod))
 		return score, nil
 	}
@@ -128,8 +152,18 @@ func (pl *LowRiskOverCommitment) Score(ctx context.Context, cycleState *framewor
 	// get node metrics
 	metrics, _ := pl.collector.GetNodeMetrics(nodeName)
 	if metrics == nil {
-		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
-		return score, nil
+		switch pl.args.StaleMetricsPolicy {
+		case pluginConfig.StaleMetricsNeutralScore:
+			klog.InfoS("Failed to get fresh metrics for node; using neutral score", "nodeName", nodeName)
+			score = trimaran.NeutralScore
+			return score, nil
+		case pluginConfig.StaleMetricsFallbackToRequests:
+			klog.InfoS("Failed to get fresh metrics for node; using requests", "nodeName", nodeName)
+			metrics = trimaran.GetRequestedMetrics(nodeInfo)
+		default:
+			klog.InfoS("Failed to get fresh metrics for node; using minimum score", "nodeName", nodeName)
+			return score, nil
+		}
 	}
 	// calculate score
 	totalScore := pl.computeRank(metrics, nodeInfo, pod, podRequests, podLimits) * float64(framework.MaxNodeScore)
@@ -137,6 +171,52 @@ func (pl *LowRiskOverCommitment) Score(ctx context.Context, cycleState *framewor
 	return score, framework.NewStatus(framework.Success, "")
 }
 
+// Filter : filter out the node if its metrics are missing or stale and the StaleMetricsPolicy is Filter,
+// or if the pod would overcommit the limits of a resource on the node beyond its maximum ratio
+func (pl *LowRiskOverCommitment) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	if status := trimaran.FilterStaleNode(pl.collector, pl.args.StaleMetricsPolicy, node.Name); !status.IsSuccess() {
+		return status
+	}
+	if len(pl.maxOvercommitRatios) == 0 {
+		return nil
+	}
+	podResources := CreatePodResourcesStateData(pod)
+	nodeRequestsAndLimits := trimaran.GetNodeRequestsAndLimits(nodeInfo.Pods, node, pod,
+		&podResources.podRequests, &podResources.podLimits)
+	for r, maxRatio := range pl.maxOvercommitRatios {
+		// a pod without limits on the resource does not add to its overcommitment
+		if trimaran.RequestedValue(&podResources.podLimits, r) == 0 {
+			continue
+		}
+		capacity := trimaran.RequestedValue(nodeRequestsAndLimits.Nodecapacity, r)
+		limit := trimaran.RequestedValue(nodeRequestsAndLimits.NodeLimit, r)
+		if capacity <= 0 {
+			continue
+		}
+		ratio := float64(limit) / float64(capacity)
+		klog.V(6).InfoS("Overcommit ratio", "pod", klog.KObj(pod), "nodeName", node.Name, "resource", r,
+			"ratio", ratio, "maxRatio", maxRatio)
+		if ratio > maxRatio {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonMaxOvercommitRatio, r))
+		}
+	}
+	return nil
+}
+
+// isBestEffort : whether the pod neither requests nor limits any of the resources whose risk is evaluated
+func (pl *LowRiskOverCommitment) isBestEffort(podRequests *framework.Resource, podLimits *framework.Resource) bool {
+	for _, r := range pl.resources {
+		if trimaran.RequestedValue(podRequests, r) != 0 || trimaran.RequestedValue(podLimits, r) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Name : name of plugin
 func (pl *LowRiskOverCommitment) Name() string {
 	return Name
@@ -158,11 +238,15 @@ func (pl *LowRiskOverCommitment) computeRank(metrics []watcher.Metric, nodeInfo
 	node := nodeInfo.Node()
 	// calculate risk based on requests and limits
 	nodeRequestsAndLimits := trimaran.GetNodeRequestsAndLimits(nodeInfo.Pods, node, pod, podRequests, podLimits)
-	riskCPU := pl.computeRisk(metrics, v1.ResourceCPU, watcher.CPU, node, nodeRequestsAndLimits)
-	riskMemory := pl.computeRisk(metrics, v1.ResourceMemory, watcher.Memory, node, nodeRequestsAndLimits)
-	rank := 1 - math.Max(riskCPU, riskMemory)
+	risks := make(map[v1.ResourceName]float64, len(pl.resources))
+	var maxRisk float64
+	for _, r := range pl.resources {
+		risks[r] = pl.computeRisk(metrics, r, trimaran.MetricType(r), node, nodeRequestsAndLimits)
+		maxRisk = math.Max(maxRisk, risks[r])
+	}
+	rank := 1 - maxRisk
 
-	klog.V(6).InfoS("Node rank", "nodeName", node.GetName(), "riskCPU", riskCPU, "riskMemory", riskMemory, "rank", rank)
+	klog.V(6).InfoS("Node rank", "nodeName", node.GetName(), "risks", risks, "rank", rank)
 
 	return rank
 }
@@ -183,22 +267,14 @@ func (pl *LowRiskOverCommitment) computeRisk(metrics []watcher.Metric, resourceN
 	nodeLimitMinusPod := nodeRequestsAndLimits.NodeLimitMinusPod
 	nodeCapacity := nodeRequestsAndLimits.Nodecapacity
 
-	var request, limit, capacity, requestMinusPod, limitMinusPod int64
-	if resourceName == v1.ResourceCPU {
-		request = nodeRequest.MilliCPU
-		limit = nodeLimit.MilliCPU
-		requestMinusPod = nodeRequestMinusPod.MilliCPU
-		limitMinusPod = nodeLimitMinusPod.MilliCPU
-		capacity = nodeCapacity.MilliCPU
-	} else if resourceName == v1.ResourceMemory {
-		request = nodeRequest.Memory
-		limit = nodeLimit.Memory
-		requestMinusPod = nodeRequestMinusPod.Memory
-		limitMinusPod = nodeLimitMinusPod.Memory
-		capacity = nodeCapacity.Memory
-	} else {
-		// invalid resource
-		klog.V(6).InfoS("Unexpected resource", "resourceName", resourceName)
+	request := trimaran.RequestedValue(nodeRequest, resourceName)
+	limit := trimaran.RequestedValue(nodeLimit, resourceName)
+	requestMinusPod := trimaran.RequestedValue(nodeRequestMinusPod, resourceName)
+	limitMinusPod := trimaran.RequestedValue(nodeLimitMinusPod, resourceName)
+	capacity := trimaran.RequestedValue(nodeCapacity, resourceName)
+	if capacity <= 0 {
+		// resource not available on node
+		klog.V(6).InfoS("Resource not allocatable on node", "node", klog.KObj(node), "resourceName", resourceName)
 		return 0
 	}
 
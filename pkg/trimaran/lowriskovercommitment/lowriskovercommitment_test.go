@@ -18,9 +18,11 @@ package lowriskovercommitment
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	"github.com/stretchr/testify/assert"
@@ -129,6 +131,14 @@ func TestLowRiskOverCommitment_New(t *testing.T) {
 	badp, err = New(ctx, &badArgs, fh)
 	assert.NotNil(t, badp)
 	assert.Nil(t, err)
+
+	// invalid maximum overcommit ratios are rejected
+	badArgs.MaxOvercommitRatios = map[v1.ResourceName]float64{
+		v1.ResourceCPU: 0,
+	}
+	badp, err = New(ctx, &badArgs, fh)
+	assert.Nil(t, badp)
+	assert.NotNil(t, err)
 }
 
 func TestLowRiskOverCommitment_Score(t *testing.T) {
@@ -307,6 +317,24 @@ var nrla_A1 *trimaran.NodeRequestsAndLimits = &trimaran.NodeRequestsAndLimits{
 	},
 }
 
+var nrla_A3 *trimaran.NodeRequestsAndLimits = &trimaran.NodeRequestsAndLimits{
+	NodeRequest: &framework.Resource{
+		ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 2},
+	},
+	NodeLimit: &framework.Resource{
+		ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 6},
+	},
+	NodeRequestMinusPod: &framework.Resource{
+		ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 1},
+	},
+	NodeLimitMinusPod: &framework.Resource{
+		ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 4},
+	},
+	Nodecapacity: &framework.Resource{
+		ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 4},
+	},
+}
+
 var nrla_A2 *trimaran.NodeRequestsAndLimits = &trimaran.NodeRequestsAndLimits{
 	NodeRequest: &framework.Resource{
 		MilliCPU: 4000,
@@ -330,6 +358,209 @@ var nrla_A2 *trimaran.NodeRequestsAndLimits = &trimaran.NodeRequestsAndLimits{
 	},
 }
 
+func TestLowRiskOverCommitment_ScoreStaleMetrics(t *testing.T) {
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+
+	// The metrics were collected 10 minutes ago, and are stale after 1 minute.
+	watcherResponse := watcher.WatcherMetrics{
+		Window: watcher.Window{
+			End: time.Now().Add(-10 * time.Minute).Unix(),
+		},
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Operator: watcher.Average,
+							Value:    20,
+						},
+					},
+				},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+
+	tests := []struct {
+		test           string
+		policy         pluginConfig.StaleMetricsPolicyType
+		expectedScore  int64
+		expectedFilter framework.Code
+	}{
+		{
+			test:           "minimum score",
+			policy:         "",
+			expectedScore:  framework.MinNodeScore,
+			expectedFilter: framework.Success,
+		},
+		{
+			test:           "neutral score",
+			policy:         pluginConfig.StaleMetricsNeutralScore,
+			expectedScore:  50,
+			expectedFilter: framework.Success,
+		},
+		{
+			test:           "filter",
+			policy:         pluginConfig.StaleMetricsFilter,
+			expectedScore:  framework.MinNodeScore,
+			expectedFilter: framework.UnschedulableAndUnresolvable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			lowRiskOverCommitmentArgs := pluginConfig.LowRiskOverCommitmentArgs{
+				TrimaranSpec: pluginConfig.TrimaranSpec{
+					WatcherAddress:                   server.URL,
+					MetricsStalenessThresholdSeconds: 60,
+					StaleMetricsPolicy:               tt.policy,
+				},
+				SmoothingWindowSize: 5,
+				RiskLimitWeights: map[v1.ResourceName]float64{
+					"cpu":    0.5,
+					"memory": 0.5,
+				},
+			}
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister(nil, nodes)
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, []schedConfig.PluginConfig{{Name: Name, Args: &lowRiskOverCommitmentArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &lowRiskOverCommitmentArgs, fh)
+			assert.Nil(t, err)
+
+			pod := st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "100m"}).Obj()
+			score, status := p.(framework.ScorePlugin).Score(ctx, framework.NewCycleState(), pod, "node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expectedScore, score)
+
+			nodeInfo, err := snapshot.Get("node-1")
+			assert.Nil(t, err)
+			status = p.(framework.FilterPlugin).Filter(ctx, framework.NewCycleState(), pod, nodeInfo)
+			assert.Equal(t, tt.expectedFilter, status.Code())
+		})
+	}
+}
+
+func TestLowRiskOverCommitment_FilterMaxOvercommitRatio(t *testing.T) {
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+		"example.com/gpu": "4",
+	}
+	nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+	nodePod := st.MakePod().Name("node-pod").Node("node-1").
+		Req(map[v1.ResourceName]string{v1.ResourceCPU: "500m", "example.com/gpu": "3"}).
+		Lim(map[v1.ResourceName]string{v1.ResourceCPU: "1500m", "example.com/gpu": "3"}).Obj()
+
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcher.WatcherMetrics{})
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+
+	tests := []struct {
+		test         string
+		pod          *v1.Pod
+		expectedCode framework.Code
+		expectedMsg  string
+	}{
+		{
+			test: "CPU limits below maximum ratio",
+			pod: st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "100m"}).
+				Lim(map[v1.ResourceName]string{v1.ResourceCPU: "400m"}).Obj(),
+			expectedCode: framework.Success,
+		},
+		{
+			test: "CPU limits beyond maximum ratio",
+			pod: st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "100m"}).
+				Lim(map[v1.ResourceName]string{v1.ResourceCPU: "600m"}).Obj(),
+			expectedCode: framework.Unschedulable,
+			expectedMsg:  fmt.Sprintf(ErrReasonMaxOvercommitRatio, v1.ResourceCPU),
+		},
+		{
+			test:         "CPU limits defaulting to requests beyond maximum ratio",
+			pod:          st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "600m"}).Obj(),
+			expectedCode: framework.Unschedulable,
+			expectedMsg:  fmt.Sprintf(ErrReasonMaxOvercommitRatio, v1.ResourceCPU),
+		},
+		{
+			test: "extended resource limits beyond maximum ratio",
+			pod: st.MakePod().Name("p").Req(map[v1.ResourceName]string{"example.com/gpu": "2"}).
+				Lim(map[v1.ResourceName]string{"example.com/gpu": "2"}).Obj(),
+			expectedCode: framework.Unschedulable,
+			expectedMsg:  fmt.Sprintf(ErrReasonMaxOvercommitRatio, "example.com/gpu"),
+		},
+		{
+			test:         "best effort pod",
+			pod:          st.MakePod().Name("p").Obj(),
+			expectedCode: framework.Success,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			lowRiskOverCommitmentArgs := pluginConfig.LowRiskOverCommitmentArgs{
+				TrimaranSpec:        pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+				SmoothingWindowSize: 5,
+				RiskLimitWeights: map[v1.ResourceName]float64{
+					"cpu":             0.5,
+					"memory":          0.5,
+					"example.com/gpu": 1,
+				},
+				MaxOvercommitRatios: map[v1.ResourceName]float64{
+					v1.ResourceCPU:    2,
+					"example.com/gpu": 1,
+				},
+			}
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister([]*v1.Pod{nodePod}, nodes)
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, []schedConfig.PluginConfig{{Name: Name, Args: &lowRiskOverCommitmentArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &lowRiskOverCommitmentArgs, fh)
+			assert.Nil(t, err)
+
+			nodeInfo, err := snapshot.Get("node-1")
+			assert.Nil(t, err)
+			status := p.(framework.FilterPlugin).Filter(ctx, framework.NewCycleState(), tt.pod, nodeInfo)
+			assert.Equal(t, tt.expectedCode, status.Code())
+			assert.Equal(t, tt.expectedMsg, status.Message())
+		})
+	}
+}
+
 func TestLowRiskOverCommitment_computeRisk(t *testing.T) {
 	tests := []struct {
 		name                  string
@@ -366,12 +597,24 @@ func TestLowRiskOverCommitment_computeRisk(t *testing.T) {
 			nodeRequestsAndLimits: nrla_A2,
 			want:                  0.75,
 		},
+		{
+			// no load metrics for the extended resource, only the risk due to limits
+			name:                  "test-extended-3",
+			resourceName:          "example.com/gpu",
+			resourceType:          "example.com/gpu",
+			nodeRequestsAndLimits: nrla_A3,
+			want:                  0.5,
+		},
+	}
+	riskLimitWeightsMap := map[v1.ResourceName]float64{"example.com/gpu": 1}
+	for r, w := range plugin_A.riskLimitWeightsMap {
+		riskLimitWeightsMap[r] = w
 	}
 	pl := &LowRiskOverCommitment{
 		handle:              plugin_A.handle,
 		collector:           plugin_A.collector,
 		args:                plugin_A.args,
-		riskLimitWeightsMap: plugin_A.riskLimitWeightsMap,
+		riskLimitWeightsMap: riskLimitWeightsMap,
 	}
 	metrics := watcherData_A.NodeMetricsMap[node_A.Name].Metrics
 	for _, tt := range tests {
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	AnnotationKeyPrefix = "trimaran.scheduling.x-k8s.io/"
+	// AnnotationKeyTargetUtilization : target utilization of the nodes, in percent, for the pod with TargetLoadPacking
+	AnnotationKeyTargetUtilization = AnnotationKeyPrefix + "target-utilization"
+	// AnnotationKeySafeVarianceMargin : safe variance margin for the pod with LoadVariationRiskBalancing
+	AnnotationKeySafeVarianceMargin = AnnotationKeyPrefix + "safe-variance-margin"
+)
+
+// GetArgOverride : get the value of a plugin arg the pod overrides with the annotation, bounded by min and max.
+// Returns false if the overrides are disabled, i.e. max isn't positive, or if the pod doesn't have a valid
+// annotation, the plugin arg being used then.
+// Example, the TargetLoadPacking args of a profile enabling the overrides and a pod overriding the target utilization,
+// which TargetLoadPacking scales the target of every resource by, i.e. 20 for CPU and 10 for memory:
+//
+//	pluginConfig:
+//	- name: TargetLoadPacking
+//	  args:
+//	    targetUtilization: 70
+//	    resources:
+//	    - name: cpu
+//	      targetUtilization: 70
+//	    - name: memory
+//	      targetUtilization: 35
+//	    minTargetUtilization: 20
+//	    maxTargetUtilization: 80
+//
+//	apiVersion: v1
+//	kind: Pod
+//	metadata:
+//	  name: latency-critical
+//	  annotations:
+//	    trimaran.scheduling.x-k8s.io/target-utilization: "20"
+func GetArgOverride(pod *v1.Pod, key string, min, max float64) (float64, bool) {
+	if max <= 0 {
+		return 0, false
+	}
+	valueStr, ok := pod.Annotations[key]
+	if !ok {
+		return 0, false
+	}
+	value, err := parseArgOverride(valueStr)
+	if err != nil {
+		klog.V(4).InfoS("Ignoring invalid annotation", "pod", klog.KObj(pod), "annotation", key, "err", err)
+		return 0, false
+	}
+	return math.Max(min, math.Min(value, max)), true
+}
+
+// parseArgOverride : parse the value of an annotation overriding a plugin arg
+func parseArgOverride(valueStr string) (float64, error) {
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("invalid value %v", valueStr)
+	}
+	return value, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+func TestGetArgOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		min         float64
+		max         float64
+		expected    float64
+		overridden  bool
+	}{
+		{
+			name:        "within bounds",
+			annotations: map[string]string{AnnotationKeyTargetUtilization: "20"},
+			min:         10,
+			max:         60,
+			expected:    20,
+			overridden:  true,
+		},
+		{
+			name:        "below min",
+			annotations: map[string]string{AnnotationKeyTargetUtilization: "5"},
+			min:         10,
+			max:         60,
+			expected:    10,
+			overridden:  true,
+		},
+		{
+			name:        "above max",
+			annotations: map[string]string{AnnotationKeyTargetUtilization: "80.5"},
+			min:         10,
+			max:         60,
+			expected:    60,
+			overridden:  true,
+		},
+		{
+			name:        "overrides disabled",
+			annotations: map[string]string{AnnotationKeyTargetUtilization: "20"},
+		},
+		{
+			name: "missing annotation",
+			annotations: map[string]string{
+				AnnotationKeySafeVarianceMargin: "20",
+			},
+			min: 10,
+			max: 60,
+		},
+		{
+			name:        "invalid annotation",
+			annotations: map[string]string{AnnotationKeyTargetUtilization: "high"},
+			min:         10,
+			max:         60,
+		},
+		{
+			name:        "not a number",
+			annotations: map[string]string{AnnotationKeyTargetUtilization: "NaN"},
+			min:         10,
+			max:         60,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := st.MakePod().Name("p").Annotations(tt.annotations).Obj()
+			value, overridden := GetArgOverride(pod, AnnotationKeyTargetUtilization, tt.min, tt.max)
+			assert.Equal(t, tt.overridden, overridden)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
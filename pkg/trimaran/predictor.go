@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+
+	"k8s.io/klog/v2"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// Predictor : forecast the utilization of the nodes from the history of their metrics
+//
+// The predictor keeps the average utilization of each node, by metric type, in a ring buffer holding the
+// last metrics updates of the collector, one per update interval. The utilization is forecast by Holt-Winters exponential smoothing,
+// additive and seasonal once the history holds two seasons, and by Holt's linear trend otherwise. The forecast is computed once per
+// metrics update, when it is recorded, as the history may span several seasons.
+type Predictor struct {
+	// history of the utilization, by node name and metric type
+	history map[string]map[string]*ringBuffer
+	// forecast of the utilization over the horizon, by node name and metric type with enough history
+	forecasts map[string]map[string]float64
+	// end of the window of the last metrics update recorded, 0 if unknown
+	lastWindowEnd int64
+	// for safe access to history, forecasts and lastWindowEnd
+	mu sync.RWMutex
+
+	// interval between two metrics updates, in seconds
+	updateIntervalSeconds int64
+	// number of metrics updates kept in the history
+	historySize int
+	// number of metrics updates in a season, no seasonality if 0
+	seasonLength int
+	// number of metrics updates over which the utilization is forecast
+	horizon int
+	// smoothing factors of the level, trend and seasonality
+	alpha, beta, gamma float64
+}
+
+// NewPredictor : create a predictor of the utilization, the metrics being updated every updateInterval
+func NewPredictor(predictionSpec *pluginConfig.PredictionSpec, updateInterval time.Duration) *Predictor {
+	updateIntervalSeconds := max(1, int64(updateInterval.Seconds()))
+	return &Predictor{
+		history:               make(map[string]map[string]*ringBuffer),
+		forecasts:             make(map[string]map[string]float64),
+		updateIntervalSeconds: updateIntervalSeconds,
+		historySize:           int(predictionSpec.HistorySize),
+		seasonLength:          int(predictionSpec.SeasonSeconds / updateIntervalSeconds),
+		horizon:               int(max(1, predictionSpec.HorizonSeconds/updateIntervalSeconds)),
+		alpha:                 predictionSpec.LevelSmoothing,
+		beta:                  predictionSpec.TrendSmoothing,
+		gamma:                 predictionSpec.SeasonalSmoothing,
+	}
+}
+
+// checkPredictionSpec : check the parameters of the prediction
+func checkPredictionSpec(predictionSpec *pluginConfig.PredictionSpec) error {
+	if predictionSpec.HistorySize < 2 {
+		return fmt.Errorf("invalid Prediction.HistorySize, got %v", predictionSpec.HistorySize)
+	}
+	if predictionSpec.SeasonSeconds < 0 {
+		return fmt.Errorf("invalid Prediction.SeasonSeconds, got %v", predictionSpec.SeasonSeconds)
+	}
+	if predictionSpec.HorizonSeconds < 0 {
+		return fmt.Errorf("invalid Prediction.HorizonSeconds, got %v", predictionSpec.HorizonSeconds)
+	}
+	if predictionSpec.LevelSmoothing <= 0 || predictionSpec.LevelSmoothing > 1 {
+		return fmt.Errorf("invalid Prediction.LevelSmoothing, got %v", predictionSpec.LevelSmoothing)
+	}
+	if predictionSpec.TrendSmoothing < 0 || predictionSpec.TrendSmoothing > 1 {
+		return fmt.Errorf("invalid Prediction.TrendSmoothing, got %v", predictionSpec.TrendSmoothing)
+	}
+	if predictionSpec.SeasonalSmoothing < 0 || predictionSpec.SeasonalSmoothing > 1 {
+		return fmt.Errorf("invalid Prediction.SeasonalSmoothing, got %v", predictionSpec.SeasonalSmoothing)
+	}
+	return nil
+}
+
+// Record : add the average utilization of the nodes in a metrics update to their history, forgetting the nodes
+// missing from the update. The updates are sampled by the end of their window: an update whose window didn't
+// advance is ignored, and the updates missed since the previous one are filled by linear interpolation, so that
+// the history stays spaced by the update interval. The updates without a window are all recorded. The forecasts
+// are computed from the updated history.
+func (p *Predictor) Record(metrics *watcher.WatcherMetrics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	steps := 1
+	if windowEnd := metrics.Window.End; windowEnd > 0 {
+		if windowEnd <= p.lastWindowEnd {
+			klog.V(6).InfoS("Metrics window didn't advance, skipping", "windowEnd", windowEnd)
+			return
+		}
+		if p.lastWindowEnd > 0 {
+			elapsed := float64(windowEnd-p.lastWindowEnd) / float64(p.updateIntervalSeconds)
+			steps = min(max(1, int(math.Round(elapsed))), p.historySize)
+		}
+		p.lastWindowEnd = windowEnd
+	}
+	for nodeName := range p.history {
+		if _, ok := metrics.Data.NodeMetricsMap[nodeName]; !ok {
+			delete(p.history, nodeName)
+			delete(p.forecasts, nodeName)
+		}
+	}
+	for nodeName, nodeMetrics := range metrics.Data.NodeMetricsMap {
+		nodeHistory, ok := p.history[nodeName]
+		if !ok {
+			nodeHistory = make(map[string]*ringBuffer)
+			p.history[nodeName] = nodeHistory
+		}
+		recorded := make(map[string]bool)
+		for _, metric := range nodeMetrics.Metrics {
+			// a single utilization per metric type and update
+			if !isUtilization(metric) || recorded[metric.Type] {
+				continue
+			}
+			recorded[metric.Type] = true
+			buffer, ok := nodeHistory[metric.Type]
+			if !ok {
+				buffer = newRingBuffer(p.historySize)
+				nodeHistory[metric.Type] = buffer
+			}
+			buffer.fill(metric.Value, steps)
+		}
+		nodeForecasts := make(map[string]float64)
+		for metricType, buffer := range nodeHistory {
+			if forecast, ok := p.forecast(buffer.values()); ok {
+				nodeForecasts[metricType] = forecast
+			}
+		}
+		p.forecasts[nodeName] = nodeForecasts
+	}
+}
+
+// Forecast : get the metrics of a node with the average utilization replaced by its forecast over the horizon,
+// as computed when the last metrics update was recorded, for the metric types with enough history
+func (p *Predictor) Forecast(nodeName string, metrics []watcher.Metric) []watcher.Metric {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	nodeForecasts, ok := p.forecasts[nodeName]
+	if !ok {
+		return metrics
+	}
+	forecastMetrics := make([]watcher.Metric, len(metrics))
+	copy(forecastMetrics, metrics)
+	for i, metric := range forecastMetrics {
+		if !isUtilization(metric) {
+			continue
+		}
+		forecast, ok := nodeForecasts[metric.Type]
+		if !ok {
+			continue
+		}
+		klog.V(6).InfoS("Forecast utilization", "nodeName", nodeName, "type", metric.Type,
+			"utilization", metric.Value, "forecast", forecast)
+		forecastMetrics[i].Value = forecast
+	}
+	return forecastMetrics
+}
+
+// forecast : get the mean of the utilization forecast over the horizon, false if the history is too short
+func (p *Predictor) forecast(series []float64) (float64, bool) {
+	forecasts := holtWinters(series, p.seasonLength, p.alpha, p.beta, p.gamma, p.horizon)
+	if len(forecasts) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, f := range forecasts {
+		sum += f
+	}
+	return math.Min(math.Max(sum/float64(len(forecasts)), 0), 100), true
+}
+
+// isUtilization : whether the metric is the average utilization of a resource
+func isUtilization(metric watcher.Metric) bool {
+	return metric.Operator == watcher.Average || metric.Operator == watcher.Latest || metric.Operator == ""
+}
+
+// holtWinters : forecast the next horizon values of a series by additive Holt-Winters exponential smoothing;
+// without seasonality if seasonLength is less than 2 or the series holds less than two seasons, and nil if
+// the series holds less than two values
+func holtWinters(series []float64, seasonLength int, alpha, beta, gamma float64, horizon int) []float64 {
+	n := len(series)
+	if n < 2 {
+		return nil
+	}
+	var level, trend float64
+	var seasonal []float64
+	start := 1
+	if seasonLength >= 2 && n >= 2*seasonLength {
+		// initialize the level and trend from the first two seasons, and the seasonality from the first one
+		var firstSeason, secondSeason float64
+		for i := 0; i < seasonLength; i++ {
+			firstSeason += series[i]
+			secondSeason += series[seasonLength+i]
+		}
+		firstSeason /= float64(seasonLength)
+		secondSeason /= float64(seasonLength)
+		level = firstSeason
+		trend = (secondSeason - firstSeason) / float64(seasonLength)
+		seasonal = make([]float64, seasonLength)
+		for i := 0; i < seasonLength; i++ {
+			seasonal[i] = series[i] - firstSeason
+		}
+		start = seasonLength
+	} else {
+		level = series[0]
+		trend = series[1] - series[0]
+	}
+
+	for t := start; t < n; t++ {
+		var s float64
+		if seasonal != nil {
+			s = seasonal[t%seasonLength]
+		}
+		prevLevel := level
+		level = alpha*(series[t]-s) + (1-alpha)*(level+trend)
+		trend = beta*(level-prevLevel) + (1-beta)*trend
+		if seasonal != nil {
+			seasonal[t%seasonLength] = gamma*(series[t]-level) + (1-gamma)*s
+		}
+	}
+
+	forecasts := make([]float64, horizon)
+	for h := 1; h <= horizon; h++ {
+		forecasts[h-1] = level + float64(h)*trend
+		if seasonal != nil {
+			forecasts[h-1] += seasonal[(n-1+h)%seasonLength]
+		}
+	}
+	return forecasts
+}
+
+// ringBuffer : the last values of a series, up to its capacity
+type ringBuffer struct {
+	buffer []float64
+	// index of the oldest value
+	start int
+	// number of values
+	size int
+}
+
+// newRingBuffer : create a ring buffer holding up to capacity values
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{buffer: make([]float64, capacity)}
+}
+
+// add : add a value, overwriting the oldest one if the buffer is full
+func (r *ringBuffer) add(value float64) {
+	if r.size < len(r.buffer) {
+		r.buffer[(r.start+r.size)%len(r.buffer)] = value
+		r.size++
+		return
+	}
+	r.buffer[r.start] = value
+	r.start = (r.start + 1) % len(r.buffer)
+}
+
+// fill : add steps values, linearly interpolated from the newest value to the given one
+func (r *ringBuffer) fill(value float64, steps int) {
+	if r.size == 0 {
+		r.add(value)
+		return
+	}
+	last := r.buffer[(r.start+r.size-1)%len(r.buffer)]
+	for i := 1; i <= steps; i++ {
+		r.add(last + (value-last)*float64(i)/float64(steps))
+	}
+}
+
+// values : get the values, from the oldest to the newest
+func (r *ringBuffer) values() []float64 {
+	values := make([]float64, r.size)
+	for i := 0; i < r.size; i++ {
+		values[i] = r.buffer[(r.start+i)%len(r.buffer)]
+	}
+	return values
+}
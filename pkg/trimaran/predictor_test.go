@@ -0,0 +1,231 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"github.com/stretchr/testify/assert"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+func TestRingBuffer(t *testing.T) {
+	r := newRingBuffer(3)
+	assert.Empty(t, r.values())
+	r.add(1)
+	r.add(2)
+	assert.Equal(t, []float64{1, 2}, r.values())
+	r.add(3)
+	r.add(4)
+	r.add(5)
+	assert.Equal(t, []float64{3, 4, 5}, r.values())
+}
+
+func TestHoltWinters(t *testing.T) {
+	tests := []struct {
+		name         string
+		series       []float64
+		seasonLength int
+		horizon      int
+		expected     []float64
+	}{
+		{
+			name:     "too short series",
+			series:   []float64{10},
+			horizon:  3,
+			expected: nil,
+		},
+		{
+			name:     "linear trend",
+			series:   []float64{10, 12, 14, 16, 18, 20, 22, 24, 26, 28},
+			horizon:  3,
+			expected: []float64{30, 32, 34},
+		},
+		{
+			name:         "seasonality",
+			series:       []float64{10, 20, 30, 20, 10, 20, 30, 20, 10, 20, 30, 20},
+			seasonLength: 4,
+			horizon:      4,
+			expected:     []float64{10, 20, 30, 20},
+		},
+		{
+			name:         "less than two seasons",
+			series:       []float64{10, 10, 10, 10, 10, 10},
+			seasonLength: 4,
+			horizon:      2,
+			expected:     []float64{10, 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecasts := holtWinters(tt.series, tt.seasonLength, 0.5, 0.1, 0.1, tt.horizon)
+			assert.Equal(t, len(tt.expected), len(forecasts))
+			for i := range tt.expected {
+				assert.InDelta(t, tt.expected[i], forecasts[i], 1e-9)
+			}
+		})
+	}
+}
+
+func newNodeMetrics(cpu float64) watcher.NodeMetrics {
+	return watcher.NodeMetrics{
+		Metrics: []watcher.Metric{
+			{
+				Type:     watcher.CPU,
+				Operator: watcher.Average,
+				Value:    cpu,
+			},
+			{
+				Type:     watcher.CPU,
+				Operator: watcher.Std,
+				Value:    5,
+			},
+		},
+	}
+}
+
+func TestPredictorForecast(t *testing.T) {
+	predictionSpec := pluginConfig.PredictionSpec{
+		HistorySize:    5,
+		HorizonSeconds: 90,
+		LevelSmoothing: 0.5,
+		TrendSmoothing: 0.1,
+	}
+	p := NewPredictor(&predictionSpec, 30*time.Second)
+
+	// the utilization of node-1 grows by 10 every update, node-2 disappears after the first update
+	for i := 1; i <= 7; i++ {
+		metrics := &watcher.WatcherMetrics{
+			Data: watcher.Data{
+				NodeMetricsMap: map[string]watcher.NodeMetrics{
+					"node-1": newNodeMetrics(float64(10 * i)),
+				},
+			},
+		}
+		if i == 1 {
+			metrics.Data.NodeMetricsMap["node-2"] = newNodeMetrics(50)
+		}
+		p.Record(metrics)
+	}
+
+	// the history holds 30 to 70, the forecasts over the horizon of three updates are 80, 90 and 100
+	nodeMetrics := newNodeMetrics(70).Metrics
+	forecast := p.Forecast("node-1", nodeMetrics)
+	assert.InDelta(t, 90, forecast[0].Value, 1e-9)
+	assert.Equal(t, float64(5), forecast[1].Value)
+	// the metrics of the collector are left untouched
+	assert.Equal(t, float64(70), nodeMetrics[0].Value)
+
+	assert.Equal(t, newNodeMetrics(60).Metrics, p.Forecast("node-2", newNodeMetrics(60).Metrics))
+	assert.Equal(t, newNodeMetrics(60).Metrics, p.Forecast("node-3", newNodeMetrics(60).Metrics))
+}
+
+func TestPredictorRecordWindows(t *testing.T) {
+	predictionSpec := pluginConfig.PredictionSpec{
+		HistorySize:    10,
+		LevelSmoothing: 0.5,
+	}
+	p := NewPredictor(&predictionSpec, 30*time.Second)
+
+	record := func(windowEnd int64, cpu float64) {
+		p.Record(&watcher.WatcherMetrics{
+			Window: watcher.Window{End: windowEnd},
+			Data: watcher.Data{
+				NodeMetricsMap: map[string]watcher.NodeMetrics{
+					"node-1": newNodeMetrics(cpu),
+				},
+			},
+		})
+	}
+	record(1000, 10)
+	// polled again before the watcher updated the metrics
+	record(1000, 10)
+	record(1030, 20)
+	// two updates missed
+	record(1120, 50)
+	// an older window
+	record(1090, 90)
+
+	assert.Equal(t, []float64{10, 20, 30, 40, 50}, p.history["node-1"][watcher.CPU].values())
+	// the forecast is computed once per recorded update, not at every read
+	forecast, ok := p.forecast(p.history["node-1"][watcher.CPU].values())
+	assert.True(t, ok)
+	assert.Equal(t, forecast, p.forecasts["node-1"][watcher.CPU])
+	p.history["node-1"][watcher.CPU].add(100)
+	assert.Equal(t, forecast, p.Forecast("node-1", newNodeMetrics(50).Metrics)[0].Value)
+}
+
+func TestGetPredictedNodeMetrics(t *testing.T) {
+	var cpu atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		metrics := watcher.WatcherMetrics{
+			Data: watcher.Data{
+				NodeMetricsMap: map[string]watcher.NodeMetrics{
+					"node-1": newNodeMetrics(float64(cpu.Load())),
+				},
+			},
+		}
+		bytes, err := json.Marshal(metrics)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	trimaranSpec := pluginConfig.TrimaranSpec{
+		WatcherAddress: server.URL,
+		Prediction: &pluginConfig.PredictionSpec{
+			HistorySize:    10,
+			HorizonSeconds: 30,
+			LevelSmoothing: 0.5,
+			TrendSmoothing: 0.1,
+		},
+	}
+	// the utilization grows by 10 every update
+	cpu.Store(10)
+	col, err := NewCollector(context.TODO(), &trimaranSpec)
+	assert.Nil(t, err)
+	cpu.Store(20)
+	assert.Nil(t, col.updateMetrics(context.TODO()))
+	cpu.Store(30)
+	assert.Nil(t, col.updateMetrics(context.TODO()))
+
+	metrics, _ := col.GetNodeMetrics("node-1")
+	assert.Equal(t, float64(30), metrics[0].Value)
+	metrics, _ = col.GetPredictedNodeMetrics("node-1")
+	assert.InDelta(t, 40, metrics[0].Value, 1e-9)
+}
+
+func TestNewCollectorPredictionSpec(t *testing.T) {
+	trimaranSpec := pluginConfig.TrimaranSpec{
+		WatcherAddress: "http://deadbeef:2020",
+		Prediction: &pluginConfig.PredictionSpec{
+			HistorySize:    10,
+			LevelSmoothing: 1.5,
+		},
+	}
+	col, err := NewCollector(context.TODO(), &trimaranSpec)
+	assert.Nil(t, col)
+	assert.EqualError(t, err, "invalid Prediction.LevelSmoothing, got 1.5")
+}
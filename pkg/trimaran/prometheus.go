@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	loadwatcherapi "github.com/paypal/load-watcher/pkg/watcher/api"
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	promconfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+
+	"k8s.io/klog/v2"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// prometheusQuery : a query of the node utilization, and the metric it populates
+type prometheusQuery struct {
+	query      string
+	metricType string
+	operator   string
+}
+
+// prometheusClient : get the node utilization from Prometheus directly, without load watcher
+type prometheusClient struct {
+	api       promv1.API
+	window    time.Duration
+	nodeLabel model.LabelName
+	queries   []prometheusQuery
+}
+
+var _ loadwatcherapi.Client = &prometheusClient{}
+var _ contextClient = &prometheusClient{}
+
+// newPrometheusClient : create a client of the PrometheusNative metric provider
+func newPrometheusClient(metricProvider pluginConfig.MetricProviderSpec) (loadwatcherapi.Client, error) {
+	queries := metricProvider.PrometheusQueries
+	if queries == nil {
+		return nil, fmt.Errorf("missing PrometheusQueries for MetricProvider.Type %v", pluginConfig.PrometheusNative)
+	}
+	window, err := model.ParseDuration(queries.Window)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PrometheusQueries.Window %q: %v", queries.Window, err)
+	}
+	if queries.NodeLabel == "" {
+		return nil, fmt.Errorf("missing PrometheusQueries.NodeLabel")
+	}
+
+	client := &prometheusClient{
+		window:    time.Duration(window),
+		nodeLabel: model.LabelName(queries.NodeLabel),
+	}
+	for _, q := range []struct {
+		name       string
+		template   string
+		metricType string
+		operator   string
+	}{
+		{"CPUAverage", queries.CPUAverage, watcher.CPU, watcher.Average},
+		{"CPUStd", queries.CPUStd, watcher.CPU, watcher.Std},
+		{"MemoryAverage", queries.MemoryAverage, watcher.Memory, watcher.Average},
+		{"MemoryStd", queries.MemoryStd, watcher.Memory, watcher.Std},
+	} {
+		query, err := renderPrometheusQuery(q.template, queries.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PrometheusQueries.%v: %v", q.name, err)
+		}
+		client.queries = append(client.queries, prometheusQuery{query: query, metricType: q.metricType, operator: q.operator})
+	}
+
+	var roundTripper http.RoundTripper = api.DefaultRoundTripper
+	if metricProvider.InsecureSkipVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		roundTripper = transport
+	}
+	if metricProvider.Token != "" {
+		roundTripper = promconfig.NewAuthorizationCredentialsRoundTripper("Bearer", promconfig.Secret(metricProvider.Token), roundTripper)
+	}
+	apiClient, err := api.NewClient(api.Config{
+		Address:      metricProvider.Address,
+		RoundTripper: roundTripper,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Prometheus client: %v", err)
+	}
+	client.api = promv1.NewAPI(apiClient)
+	return client, nil
+}
+
+// renderPrometheusQuery : render the query template for the window
+func renderPrometheusQuery(queryTemplate, window string) (string, error) {
+	if queryTemplate == "" {
+		return "", fmt.Errorf("empty query")
+	}
+	tmpl, err := template.New("query").Option("missingkey=error").Parse(queryTemplate)
+	if err != nil {
+		return "", err
+	}
+	var query strings.Builder
+	if err := tmpl.Execute(&query, struct{ Window string }{Window: window}); err != nil {
+		return "", err
+	}
+	return query.String(), nil
+}
+
+// GetLatestWatcherMetrics : query the utilization of all the nodes, in the format of load watcher
+func (c *prometheusClient) GetLatestWatcherMetrics() (*watcher.WatcherMetrics, error) {
+	return c.GetLatestWatcherMetricsWithContext(context.Background())
+}
+
+// GetLatestWatcherMetricsWithContext : query the utilization of all the nodes, in the format of load watcher,
+// cancelling the queries once ctx is done
+func (c *prometheusClient) GetLatestWatcherMetricsWithContext(ctx context.Context) (*watcher.WatcherMetrics, error) {
+	now := time.Now()
+	nodeMetricsMap := make(watcher.NodeMetricsMap)
+	for _, q := range c.queries {
+		vector, err := c.query(ctx, q.query, now)
+		if err != nil {
+			return nil, fmt.Errorf("unable to query Prometheus %q: %v", q.query, err)
+		}
+		for _, sample := range vector {
+			nodeName := string(sample.Metric[c.nodeLabel])
+			if nodeName == "" {
+				continue
+			}
+			nodeMetrics := nodeMetricsMap[nodeName]
+			nodeMetrics.Metrics = append(nodeMetrics.Metrics, watcher.Metric{
+				Name:     q.query,
+				Type:     q.metricType,
+				Operator: q.operator,
+				Rollup:   model.Duration(c.window).String(),
+				// The queries return ratios, the metrics are percentages.
+				Value: float64(sample.Value) * 100,
+			})
+			nodeMetricsMap[nodeName] = nodeMetrics
+		}
+	}
+
+	return &watcher.WatcherMetrics{
+		Timestamp: now.Unix(),
+		Window: watcher.Window{
+			Duration: model.Duration(c.window).String(),
+			Start:    now.Add(-c.window).Unix(),
+			End:      now.Unix(),
+		},
+		Source: string(pluginConfig.PrometheusNative),
+		Data: watcher.Data{
+			NodeMetricsMap: nodeMetricsMap,
+		},
+	}, nil
+}
+
+// query : run an instant query, which must return a vector
+func (c *prometheusClient) query(ctx context.Context, query string, now time.Time) (model.Vector, error) {
+	result, warnings, err := c.api.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(warnings) > 0 {
+		klog.V(4).InfoS("Prometheus query returned warnings", "query", query, "warnings", warnings)
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %v", result.Type())
+	}
+	return vector, nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// fakePrometheus : an in-process Prometheus HTTP API answering instant queries
+// with the node utilization ratios of the first metric name found in the query
+type fakePrometheus struct {
+	// node utilization ratios by metric name and node
+	ratios map[string]map[string]string
+	// label holding the node name
+	nodeLabel string
+
+	mu      sync.Mutex
+	queries []string
+}
+
+func (p *fakePrometheus) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/api/v1/query" {
+		http.NotFound(resp, req)
+		return
+	}
+	query := req.FormValue("query")
+	p.mu.Lock()
+	p.queries = append(p.queries, query)
+	p.mu.Unlock()
+
+	for metric, nodes := range p.ratios {
+		if !strings.Contains(query, metric) {
+			continue
+		}
+		var results []string
+		for node, ratio := range nodes {
+			results = append(results, fmt.Sprintf(`{"metric":{%q:%q},"value":[1700000000,%q]}`, p.nodeLabel, node, ratio))
+		}
+		fmt.Fprintf(resp, `{"status":"success","data":{"resultType":"vector","result":[%s]}}`, strings.Join(results, ","))
+		return
+	}
+	resp.WriteHeader(http.StatusBadRequest)
+	fmt.Fprintf(resp, `{"status":"error","errorType":"bad_data","error":"unknown metric in %s"}`, query)
+}
+
+func newPrometheusTestSpec(address string) pluginConfig.TrimaranSpec {
+	return pluginConfig.TrimaranSpec{
+		MetricProvider: pluginConfig.MetricProviderSpec{
+			Type:    pluginConfig.PrometheusNative,
+			Address: address,
+			PrometheusQueries: &pluginConfig.PrometheusQueries{
+				Window:        "15m",
+				NodeLabel:     "instance",
+				CPUAverage:    "avg_over_time(cpu_ratio[{{.Window}}])",
+				CPUStd:        "stddev_over_time(cpu_std_ratio[{{.Window}}])",
+				MemoryAverage: "avg_over_time(memory_ratio[{{.Window}}])",
+				MemoryStd:     "stddev_over_time(memory_std_ratio[{{.Window}}])",
+			},
+		},
+	}
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	prometheus := &fakePrometheus{
+		nodeLabel: "instance",
+		ratios: map[string]map[string]string{
+			"cpu_ratio":        {"node-1": "0.8", "node-2": "0.1"},
+			"cpu_std_ratio":    {"node-1": "0.16"},
+			"memory_ratio":     {"node-1": "0.25"},
+			"memory_std_ratio": {"node-1": "0.0625"},
+		},
+	}
+	server := httptest.NewServer(prometheus)
+	defer server.Close()
+
+	trimaranSpec := newPrometheusTestSpec(server.URL)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
+	assert.Nil(t, err)
+	assert.NotNil(t, collector)
+
+	metrics, allMetrics := collector.GetNodeMetrics("node-1")
+	assert.NotNil(t, allMetrics)
+	assert.Equal(t, "15m", allMetrics.Window.Duration)
+	assert.Equal(t, int64(15*60), allMetrics.Window.End-allMetrics.Window.Start)
+
+	got := map[string]float64{}
+	for _, metric := range metrics {
+		got[metric.Type+"/"+metric.Operator] = metric.Value
+	}
+	expected := map[string]float64{
+		watcher.CPU + "/" + watcher.Average:    80,
+		watcher.CPU + "/" + watcher.Std:        16,
+		watcher.Memory + "/" + watcher.Average: 25,
+		watcher.Memory + "/" + watcher.Std:     6.25,
+	}
+	assert.InDeltaMapValues(t, expected, got, 1e-9)
+
+	metrics, _ = collector.GetNodeMetrics("node-2")
+	assert.Len(t, metrics, 1)
+
+	prometheus.mu.Lock()
+	defer prometheus.mu.Unlock()
+	assert.Contains(t, prometheus.queries, "avg_over_time(cpu_ratio[15m])")
+	assert.Contains(t, prometheus.queries, "stddev_over_time(memory_std_ratio[15m])")
+}
+
+func TestPrometheusCollectorNodeLabel(t *testing.T) {
+	prometheus := &fakePrometheus{
+		nodeLabel: "node",
+		ratios: map[string]map[string]string{
+			"cpu_ratio":        {"node-1": "0.5"},
+			"cpu_std_ratio":    {},
+			"memory_ratio":     {},
+			"memory_std_ratio": {},
+		},
+	}
+	server := httptest.NewServer(prometheus)
+	defer server.Close()
+
+	trimaranSpec := newPrometheusTestSpec(server.URL)
+	trimaranSpec.MetricProvider.PrometheusQueries.NodeLabel = "node"
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
+	assert.Nil(t, err)
+
+	metrics, _ := collector.GetNodeMetrics("node-1")
+	assert.Equal(t, []watcher.Metric{{
+		Name:     "avg_over_time(cpu_ratio[15m])",
+		Type:     watcher.CPU,
+		Operator: watcher.Average,
+		Rollup:   "15m",
+		Value:    50,
+	}}, metrics)
+}
+
+func TestPrometheusCollectorQueryError(t *testing.T) {
+	prometheus := &fakePrometheus{
+		nodeLabel: "instance",
+		ratios: map[string]map[string]string{
+			"cpu_ratio": {"node-1": "0.5"},
+		},
+	}
+	server := httptest.NewServer(prometheus)
+	defer server.Close()
+
+	trimaranSpec := newPrometheusTestSpec(server.URL)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
+	assert.Nil(t, err)
+
+	// No metrics are populated if one of the queries fails.
+	metrics, allMetrics := collector.GetNodeMetrics("node-1")
+	assert.Nil(t, metrics)
+	assert.Nil(t, allMetrics)
+	assert.NotNil(t, collector.updateMetrics(context.TODO()))
+}
+
+func TestPrometheusCollectorFetchTimeout(t *testing.T) {
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The connection closed by the client is only noticed once the body is read.
+		_ = r.ParseForm()
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	defer server.Close()
+	defer server.CloseClientConnections()
+
+	trimaranSpec := newPrometheusTestSpec(server.URL)
+	client, err := newPrometheusClient(trimaranSpec.MetricProvider)
+	assert.Nil(t, err)
+	collector := &Collector{
+		client:       client,
+		fetchTimeout: 10 * time.Millisecond,
+	}
+	assert.EqualError(t, collector.updateMetrics(context.TODO()), "timed out after 10ms")
+
+	// The query in flight is cancelled with the fetch.
+	select {
+	case <-cancelled:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("expected the query to be cancelled")
+	}
+}
+
+func TestNewPrometheusClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		update      func(queries *pluginConfig.PrometheusQueries)
+		expectedErr string
+	}{
+		{
+			name:   "valid queries",
+			update: func(queries *pluginConfig.PrometheusQueries) {},
+		},
+		{
+			name:        "invalid window",
+			update:      func(queries *pluginConfig.PrometheusQueries) { queries.Window = "15 minutes" },
+			expectedErr: "invalid PrometheusQueries.Window",
+		},
+		{
+			name:        "missing node label",
+			update:      func(queries *pluginConfig.PrometheusQueries) { queries.NodeLabel = "" },
+			expectedErr: "missing PrometheusQueries.NodeLabel",
+		},
+		{
+			name:        "empty query",
+			update:      func(queries *pluginConfig.PrometheusQueries) { queries.CPUStd = "" },
+			expectedErr: "invalid PrometheusQueries.CPUStd",
+		},
+		{
+			name: "invalid template",
+			update: func(queries *pluginConfig.PrometheusQueries) {
+				queries.MemoryAverage = "avg_over_time(memory_ratio[{{.Window]})"
+			},
+			expectedErr: "invalid PrometheusQueries.MemoryAverage",
+		},
+		{
+			name: "unknown template field",
+			update: func(queries *pluginConfig.PrometheusQueries) {
+				queries.MemoryStd = "stddev_over_time(memory_ratio[{{.Range}}])"
+			},
+			expectedErr: "invalid PrometheusQueries.MemoryStd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimaranSpec := newPrometheusTestSpec("http://prometheus:9090")
+			tt.update(trimaranSpec.MetricProvider.PrometheusQueries)
+			_, err := newPrometheusClient(trimaranSpec.MetricProvider)
+			if tt.expectedErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+
+	_, err := newPrometheusClient(pluginConfig.MetricProviderSpec{Type: pluginConfig.PrometheusNative})
+	assert.ErrorContains(t, err, "missing PrometheusQueries")
+}
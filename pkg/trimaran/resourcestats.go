@@ -21,6 +21,7 @@ import (
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 )
@@ -56,13 +57,17 @@ func CreateResourceStats(metrics []watcher.Metric, node *v1.Node, podRequest *fr
 	allocatableResources := node.Status.Allocatable
 	am := allocatableResources[resourceName]
 
-	if resourceName == v1.ResourceCPU {
+	switch resourceName {
+	case v1.ResourceCPU:
 		rs.Capacity = float64(am.MilliValue())
 		rs.Req = float64(podRequest.MilliCPU)
-	} else {
+	case v1.ResourceMemory:
 		rs.Capacity = float64(am.Value())
 		rs.Capacity *= MegaFactor
 		rs.Req = float64(podRequest.Memory) * MegaFactor
+	default:
+		rs.Capacity = float64(am.Value())
+		rs.Req = float64(RequestedValue(podRequest, resourceName))
 	}
 
 	// calculate absolute usage statistics
@@ -107,21 +112,69 @@ func GetResourceData(metrics []watcher.Metric, resourceType string) (avg float64
 	return avg, stDev, isValid
 }
 
-// GetResourceRequested : calculate the resource requests of a pod (CPU and Memory)
+// MetricType : get the type of the metrics of the utilization of the resource
+func MetricType(resourceName v1.ResourceName) string {
+	switch resourceName {
+	case v1.ResourceCPU:
+		return watcher.CPU
+	case v1.ResourceMemory:
+		return watcher.Memory
+	default:
+		return string(resourceName)
+	}
+}
+
+// QuantityValue : get the value of a quantity of the resource, in millicores for CPU
+func QuantityValue(resourceName v1.ResourceName, quantity resource.Quantity) int64 {
+	if resourceName == v1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+// RequestedValue : get the value of the resource in a framework resource, in millicores for CPU
+func RequestedValue(requested *framework.Resource, resourceName v1.ResourceName) int64 {
+	switch resourceName {
+	case v1.ResourceCPU:
+		return requested.MilliCPU
+	case v1.ResourceMemory:
+		return requested.Memory
+	case v1.ResourceEphemeralStorage:
+		return requested.EphemeralStorage
+	default:
+		return requested.ScalarResources[resourceName]
+	}
+}
+
+// SetResourceValue : set the value of the resource in a framework resource, in millicores for CPU
+func SetResourceValue(r *framework.Resource, resourceName v1.ResourceName, value int64) {
+	switch resourceName {
+	case v1.ResourceCPU:
+		r.MilliCPU = value
+	case v1.ResourceMemory:
+		r.Memory = value
+	case v1.ResourceEphemeralStorage:
+		r.EphemeralStorage = value
+	default:
+		r.SetScalar(resourceName, value)
+	}
+}
+
+// GetResourceRequested : calculate the resource requests of a pod
 func GetResourceRequested(pod *v1.Pod) *framework.Resource {
 	return GetEffectiveResource(pod, func(container *v1.Container) v1.ResourceList {
 		return container.Resources.Requests
 	})
 }
 
-// GetResourceLimits : calculate the resource limits of a pod (CPU and Memory)
+// GetResourceLimits : calculate the resource limits of a pod
 func GetResourceLimits(pod *v1.Pod) *framework.Resource {
 	return GetEffectiveResource(pod, func(container *v1.Container) v1.ResourceList {
 		return container.Resources.Limits
 	})
 }
 
-// GetEffectiveResource: calculate effective resources of a pod (CPU and Memory)
+// GetEffectiveResource: calculate effective resources of a pod
 func GetEffectiveResource(pod *v1.Pod, fn func(container *v1.Container) v1.ResourceList) *framework.Resource {
 	result := &framework.Resource{}
 	// add up resources of all containers
@@ -130,12 +183,12 @@ func GetEffectiveResource(pod *v1.Pod, fn func(container *v1.Container) v1.Resou
 	}
 	// take max(sum_pod, any_init_container)
 	for _, container := range pod.Spec.InitContainers {
-		for rName, rQuantity := range fn(&container) {
-			switch rName {
-			case v1.ResourceCPU:
-				setMax(&result.MilliCPU, rQuantity.MilliValue())
-			case v1.ResourceMemory:
-				setMax(&result.Memory, rQuantity.Value())
+		initResource := framework.NewResource(fn(&container))
+		setMax(&result.MilliCPU, initResource.MilliCPU)
+		setMax(&result.Memory, initResource.Memory)
+		for rName, rValue := range initResource.ScalarResources {
+			if result.ScalarResources[rName] < rValue {
+				result.SetScalar(rName, rValue)
 			}
 		}
 	}
@@ -170,13 +223,12 @@ func GetNodeRequestsAndLimits(podInfosOnNode []*framework.PodInfo, node *v1.Node
 	nodeLimitMinusPod := &framework.Resource{}
 	// set capacities
 	nodeCapacity := &framework.Resource{}
-	allocatableResources := node.Status.Allocatable
-	amCpu := allocatableResources[v1.ResourceCPU]
-	capCpu := amCpu.MilliValue()
-	amMem := allocatableResources[v1.ResourceMemory]
-	capMem := amMem.Value()
-	nodeCapacity.MilliCPU = capCpu
-	nodeCapacity.Memory = capMem
+	for resourceName, quantity := range node.Status.Allocatable {
+		if resourceName == v1.ResourcePods {
+			continue
+		}
+		SetResourceValue(nodeCapacity, resourceName, QuantityValue(resourceName, quantity))
+	}
 	// get requests and limits for all pods
 	podsOnNode := make([]*v1.Pod, len(podInfosOnNode))
 	for i, pf := range podInfosOnNode {
@@ -187,8 +239,8 @@ func GetNodeRequestsAndLimits(podInfosOnNode []*framework.PodInfo, node *v1.Node
 		var limits *framework.Resource
 		// pending pod is last in sequence
 		if p == pod {
-			*nodeRequestMinusPod = *nodeRequest
-			*nodeLimitMinusPod = *nodeLimit
+			nodeRequestMinusPod = nodeRequest.Clone()
+			nodeLimitMinusPod = nodeLimit.Clone()
 			requested = podRequests
 			limits = podLimits
 		} else {
@@ -200,16 +252,12 @@ func GetNodeRequestsAndLimits(podInfosOnNode []*framework.PodInfo, node *v1.Node
 		}
 
 		// accumulate
-		nodeRequest.MilliCPU += requested.MilliCPU
-		nodeRequest.Memory += requested.Memory
-		nodeLimit.MilliCPU += limits.MilliCPU
-		nodeLimit.Memory += limits.Memory
+		addResource(nodeRequest, requested, 1)
+		addResource(nodeLimit, limits, 1)
 	}
 	// cap requests by node capacity
-	setMin(&nodeRequest.MilliCPU, capCpu)
-	setMin(&nodeRequest.Memory, capMem)
-	setMin(&nodeRequestMinusPod.MilliCPU, capCpu)
-	setMin(&nodeRequestMinusPod.Memory, capMem)
+	capRequests(nodeRequest, nodeCapacity)
+	capRequests(nodeRequestMinusPod, nodeCapacity)
 
 	klog.V(6).InfoS("Total node resources:", "node", klog.KObj(node),
 		"CPU-req", nodeRequest.MilliCPU, "Memory-req", nodeRequest.Memory,
@@ -236,7 +284,19 @@ func SetMaxLimits(requests *framework.Resource, limits *framework.Resource) {
 	}
 	for k, v := range requests.ScalarResources {
 		if limits.ScalarResources[k] < v {
-			limits.ScalarResources[k] = v
+			limits.SetScalar(k, v)
+		}
+	}
+}
+
+// capRequests : cap the requests of the resources by the node capacity
+func capRequests(requests *framework.Resource, capacity *framework.Resource) {
+	setMin(&requests.MilliCPU, capacity.MilliCPU)
+	setMin(&requests.Memory, capacity.Memory)
+	setMin(&requests.EphemeralStorage, capacity.EphemeralStorage)
+	for k, v := range requests.ScalarResources {
+		if v > capacity.ScalarResources[k] {
+			requests.SetScalar(k, capacity.ScalarResources[k])
 		}
 	}
 }
@@ -428,6 +428,15 @@ func TestGetNodeRequestsAndLimits(t *testing.T) {
 	pod4Requests := GetResourceRequested(pod4)
 	pod4Limits := GetResourceLimits(pod4)
 
+	testNodeWithGPU := st.MakeNode().Name("test-node").Capacity(map[v1.ResourceName]string{
+		v1.ResourceCPU:    "8000m",
+		v1.ResourceMemory: "6Ki",
+		"example.com/gpu": "2",
+	}).Obj()
+	gpuPod := st.MakePod().Name("gpu-pod").Req(map[v1.ResourceName]string{"example.com/gpu": "1"}).Obj()
+	gpuPodInfo, _ := framework.NewPodInfo(gpuPod)
+	pod5 := st.MakePod().Name("pod5").Req(map[v1.ResourceName]string{"example.com/gpu": "2"}).Obj()
+
 	type args struct {
 		podsOnNode  []*framework.PodInfo
 		node        *v1.Node
@@ -577,6 +586,36 @@ func TestGetNodeRequestsAndLimits(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "test-4",
+			// Test case for extended resources
+			args: args{
+				podsOnNode:  []*framework.PodInfo{gpuPodInfo},
+				node:        testNodeWithGPU,
+				pod:         pod5,
+				podRequests: GetResourceRequested(pod5),
+				podLimits:   GetResourceLimits(pod5),
+			},
+			want: &NodeRequestsAndLimits{
+				NodeRequest: &framework.Resource{
+					ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 2},
+				},
+				NodeLimit: &framework.Resource{
+					ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 3},
+				},
+				NodeRequestMinusPod: &framework.Resource{
+					ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 1},
+				},
+				NodeLimitMinusPod: &framework.Resource{
+					ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 1},
+				},
+				Nodecapacity: &framework.Resource{
+					MilliCPU:        8000,
+					Memory:          6 * 1024,
+					ScalarResources: map[v1.ResourceName]int64{"example.com/gpu": 2},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
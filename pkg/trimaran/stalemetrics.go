@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"github.com/paypal/load-watcher/pkg/watcher"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+const (
+	// NeutralScore : score of the nodes without fresh metrics with the NeutralScore policy
+	NeutralScore = (framework.MinNodeScore + framework.MaxNodeScore) / 2
+	// ErrReasonStaleMetrics : reason of the nodes filtered out with the Filter policy
+	ErrReasonStaleMetrics = "node(s) without fresh utilization metrics"
+)
+
+// GetRequestedMetrics : get metrics for a node from the requests of its pods rather than from its measured
+// utilization, as percentages of its allocatable resources; used with the FallbackToRequests policy
+func GetRequestedMetrics(nodeInfo *framework.NodeInfo) []watcher.Metric {
+	var metrics []watcher.Metric
+	if nodeInfo.Allocatable.MilliCPU > 0 {
+		metrics = append(metrics, watcher.Metric{
+			Type:     watcher.CPU,
+			Operator: watcher.Average,
+			Value:    100 * float64(nodeInfo.Requested.MilliCPU) / float64(nodeInfo.Allocatable.MilliCPU),
+		})
+	}
+	if nodeInfo.Allocatable.Memory > 0 {
+		metrics = append(metrics, watcher.Metric{
+			Type:     watcher.Memory,
+			Operator: watcher.Average,
+			Value:    100 * float64(nodeInfo.Requested.Memory) / float64(nodeInfo.Allocatable.Memory),
+		})
+	}
+	return metrics
+}
+
+// FilterStaleNode : filter out the node if its metrics are missing or stale and the policy is Filter
+func FilterStaleNode(collector *Collector, policy pluginConfig.StaleMetricsPolicyType, nodeName string) *framework.Status {
+	if policy != pluginConfig.StaleMetricsFilter {
+		return nil
+	}
+	if metrics, _ := collector.GetNodeMetrics(nodeName); metrics == nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonStaleMetrics)
+	}
+	return nil
+}
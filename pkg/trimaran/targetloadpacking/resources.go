@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetloadpacking
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
+)
+
+// packedResource : a resource bin packed by the plugin
+type packedResource struct {
+	name v1.ResourceName
+	// type of the metrics of the utilization of the resource
+	metricType string
+	// node target utilization of the resource, in percent
+	targetUtilization int64
+	weight            int64
+}
+
+// newPackedResources : get the resources bin packed by the plugin, CPU with the target utilization if none
+func newPackedResources(args *pluginConfig.TargetLoadPackingArgs) ([]packedResource, error) {
+	if len(args.Resources) == 0 {
+		return []packedResource{{
+			name:              v1.ResourceCPU,
+			metricType:        watcher.CPU,
+			targetUtilization: args.TargetUtilization,
+			weight:            1,
+		}}, nil
+	}
+	resources := make([]packedResource, 0, len(args.Resources))
+	for _, r := range args.Resources {
+		targetUtilization := r.TargetUtilization
+		if targetUtilization == 0 {
+			targetUtilization = args.TargetUtilization
+		}
+		if targetUtilization <= 0 || targetUtilization >= 100 {
+			return nil, fmt.Errorf("invalid target utilization of resource %v, got %v", r.Name, targetUtilization)
+		}
+		weight := r.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("invalid weight of resource %v, got %v", r.Name, weight)
+		}
+		resources = append(resources, packedResource{
+			name:              r.Name,
+			metricType:        trimaran.MetricType(r.Name),
+			targetUtilization: targetUtilization,
+			weight:            weight,
+		})
+	}
+	return resources, nil
+}
+
+// checkTargetUtilizationBounds : check the bounds of the target utilization overridden by the pods, if enabled
+func checkTargetUtilizationBounds(args *pluginConfig.TargetLoadPackingArgs) error {
+	if args.MaxTargetUtilization == 0 {
+		return nil
+	}
+	if args.MinTargetUtilization <= 0 || args.MinTargetUtilization > args.MaxTargetUtilization ||
+		args.MaxTargetUtilization >= 100 {
+		return fmt.Errorf("invalid target utilization bounds, got %v and %v",
+			args.MinTargetUtilization, args.MaxTargetUtilization)
+	}
+	return nil
+}
+
+// overriddenTargetUtilization : get the target utilization of a resource for a pod overriding the target utilization
+// of the args, the target of the resource being scaled by the ratio of the override to the target utilization of the
+// args, so that the targets of the resources keep their proportions; within 1 and 99, as the targets of the args
+func overriddenTargetUtilization(targetUtilization, argsTargetUtilization int64, override float64) int64 {
+	scaled := override
+	if argsTargetUtilization > 0 {
+		scaled = float64(targetUtilization) * override / float64(argsTargetUtilization)
+	}
+	return min(max(int64(math.Round(scaled)), 1), 99)
+}
+
+// getUtilisationPercent : get the utilization of a resource from the node metrics
+func getUtilisationPercent(metrics []watcher.Metric, metricType string) (float64, bool) {
+	var utilPercent float64
+	var found bool
+	for _, metric := range metrics {
+		if metric.Type == metricType {
+			if metric.Operator == watcher.Average || metric.Operator == watcher.Latest {
+				utilPercent = metric.Value
+				found = true
+			}
+		}
+	}
+	return utilPercent, found
+}
+
+// predictResourceUtilisation predict utilization of a resource for a container based on its requests/limits
+func predictResourceUtilisation(container *v1.Container, resourceName v1.ResourceName, defaultRequests v1.ResourceList) int64 {
+	if resourceName == v1.ResourceCPU {
+		return PredictUtilisation(container)
+	}
+	if limit, ok := container.Resources.Limits[resourceName]; ok {
+		return limit.Value()
+	} else if request, ok := container.Resources.Requests[resourceName]; ok {
+		return int64(math.Round(float64(request.Value()) * requestsMultiplier))
+	}
+	defaultRequest := defaultRequests[resourceName]
+	return defaultRequest.Value()
+}
+
+// predictPodUtilisation predict utilization of a resource for a pod, including its overhead, from the usage profile
+// of its workload if known, and from its requests/limits otherwise
+func (pl *TargetLoadPacking) predictPodUtilisation(pod *v1.Pod, resourceName v1.ResourceName) int64 {
+	usage, ok := int64(0), false
+	if pl.usageProfiles != nil {
+		usage, ok = pl.usageProfiles.GetPodUsage(pod, resourceName)
+	}
+	if !ok {
+		for _, container := range pod.Spec.Containers {
+			usage += predictResourceUtilisation(&container, resourceName, pl.args.DefaultRequests)
+		}
+	}
+	if overhead, ok := pod.Spec.Overhead[resourceName]; ok {
+		usage += trimaran.QuantityValue(resourceName, overhead)
+	}
+	return usage
+}
+
+// predictPodUsage predict utilization of the bin packed resources for a pod
+func (pl *TargetLoadPacking) predictPodUsage(pod *v1.Pod) *framework.Resource {
+	usage := &framework.Resource{}
+	for _, r := range pl.resources {
+		trimaran.SetResourceValue(usage, r.name, pl.predictPodUtilisation(pod, r.name))
+	}
+	return usage
+}
+
+// resourceScore : score a node from the predicted utilization of a resource, highest at the target utilization
+func resourceScore(predictedUsage float64, targetUtilization int64) int64 {
+	if predictedUsage > float64(targetUtilization) {
+		if predictedUsage > 100 {
+			return framework.MinNodeScore
+		}
+		return int64(math.Round(float64(targetUtilization) * (100 - predictedUsage) / (100 - float64(targetUtilization))))
+	}
+	return int64(math.Round((100-float64(targetUtilization))*
+		predictedUsage/float64(targetUtilization) + float64(targetUtilization)))
+}
+
+// resourceScoreInfo : the score of a node for a resource
+type resourceScoreInfo struct {
+	score  int64
+	weight int64
+	// whether the predicted utilization is above the target utilization
+	overTarget bool
+}
+
+// combineScores : combine the scores of the resources respecting the tightest resource, i.e. the lowest score of the
+// resources above their target utilization if any, and the weighted average of the scores otherwise
+func combineScores(scores []resourceScoreInfo) int64 {
+	overTarget := false
+	minScore := framework.MaxNodeScore
+	var weightedScores, weights int64
+	for _, s := range scores {
+		if s.overTarget {
+			overTarget = true
+			minScore = min(minScore, s.score)
+		}
+		weightedScores += s.score * s.weight
+		weights += s.weight
+	}
+	if overTarget {
+		return minScore
+	}
+	if weights == 0 {
+		return framework.MinNodeScore
+	}
+	return int64(math.Round(float64(weightedScores) / float64(weights)))
+}
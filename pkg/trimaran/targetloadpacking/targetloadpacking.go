@@ -15,8 +15,8 @@ limitations under the License.
 */
 
 /*
-targetloadpacking package provides K8s scheduler plugin for best-fit variant of bin packing based on CPU utilization around a target load
-It contains plugin for Score extension point.
+targetloadpacking package provides K8s scheduler plugin for best-fit variant of bin packing based on CPU, and optionally memory and extended resources, utilization around a target load
+It contains plugin for Score and Filter extension points.
 */
 
 package targetloadpacking
@@ -28,8 +28,6 @@ import (
 	"math"
 	"strconv"
 
-	"github.com/paypal/load-watcher/pkg/watcher"
-
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
@@ -42,14 +40,11 @@ import (
 
 const (
 	Name = "TargetLoadPacking"
-	// Time interval in seconds for each metrics agent ingestion.
-	metricsAgentReportingIntervalSeconds = 60
 )
 
 var (
-	requestsMilliCores           = cfgv1.DefaultRequestsMilliCores
-	hostTargetUtilizationPercent = cfgv1.DefaultTargetUtilizationPercent
-	requestsMultiplier           float64
+	requestsMilliCores = cfgv1.DefaultRequestsMilliCores
+	requestsMultiplier float64
 )
 
 type TargetLoadPacking struct {
@@ -57,43 +52,74 @@ type TargetLoadPacking struct {
 	eventHandler *trimaran.PodAssignEventHandler
 	collector    *trimaran.Collector
 	args         *pluginConfig.TargetLoadPackingArgs
+	// resources to bin pack
+	resources []packedResource
+	// usage profiles of the workloads, nil if disabled
+	usageProfiles *trimaran.UsageProfiles
 }
 
 var _ framework.ScorePlugin = &TargetLoadPacking{}
+var _ framework.FilterPlugin = &TargetLoadPacking{}
 
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the TargetLoadPacking plugin")
 	// cast object into plugin arguments object
 	args, ok := obj.(*pluginConfig.TargetLoadPackingArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type TargetLoadPackingArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(&args.TrimaranSpec)
+	collector, err := trimaran.GetCollector(ctx, &args.TrimaranSpec)
 	if err != nil {
 		return nil, err
 	}
 
-	hostTargetUtilizationPercent = args.TargetUtilization
 	requestsMilliCores = args.DefaultRequests.Cpu().MilliValue()
 	requestsMultiplier, err = strconv.ParseFloat(args.DefaultRequestsMultiplier, 64)
 	if err != nil {
 		return nil, errors.New("unable to parse DefaultRequestsMultiplier: " + err.Error())
 	}
+	resources, err := newPackedResources(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTargetUtilizationBounds(args); err != nil {
+		return nil, err
+	}
 
 	klog.V(4).InfoS("Using TargetLoadPackingArgs",
 		"requestsMilliCores", requestsMilliCores,
 		"requestsMultiplier", requestsMultiplier,
-		"targetUtilization", hostTargetUtilizationPercent)
-
-	podAssignEventHandler := trimaran.New()
-	podAssignEventHandler.AddToHandle(handle)
+		"targetUtilization", args.TargetUtilization,
+		"resources", args.Resources,
+		"usageProfiles", args.UsageProfiles,
+		"minTargetUtilization", args.MinTargetUtilization,
+		"maxTargetUtilization", args.MaxTargetUtilization)
+
+	var usageProfiles *trimaran.UsageProfiles
+	if args.UsageProfiles != nil {
+		source, err := trimaran.NewMetricsAPIPodUsageSource(handle.KubeConfig())
+		if err != nil {
+			return nil, err
+		}
+		usageProfiles, err = trimaran.NewUsageProfiles(ctx, args.UsageProfiles, source,
+			handle.SharedInformerFactory().Core().V1().Pods().Lister())
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	pl := &TargetLoadPacking{
-		handle:       handle,
-		eventHandler: podAssignEventHandler,
-		collector:    collector,
-		args:         args,
+		handle:        handle,
+		collector:     collector,
+		args:          args,
+		resources:     resources,
+		usageProfiles: usageProfiles,
 	}
+
+	// the predicted utilization of the pods scheduled recently is aggregated by node as they are bound
+	pl.eventHandler = trimaran.New(ctx, &args.TrimaranSpec)
+	pl.eventHandler.SetPodUsagePredictor(pl.predictPodUsage)
+	pl.eventHandler.AddToHandle(handle)
 	return pl, nil
 }
 
@@ -109,82 +135,97 @@ func (pl *TargetLoadPacking) Score(ctx context.Context, cycleState *framework.Cy
 	}
 
 	// get node metrics
-	metrics, allMetrics := pl.collector.GetNodeMetrics(nodeName)
+	metrics, allMetrics := pl.collector.GetPredictedNodeMetrics(nodeName)
+	fallbackToRequests := false
 	if metrics == nil {
-		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
-		// Avoid the node by scoring minimum
-		return score, nil
-		// TODO(aqadeer): If this happens for a long time, fall back to allocation based packing. This could mean maintaining failure state across cycles if scheduler doesn't provide this state
-
-	}
-
-	var curPodCPUUsage int64
-	for _, container := range pod.Spec.Containers {
-		curPodCPUUsage += PredictUtilisation(&container)
-	}
-	klog.V(6).InfoS("Predicted utilization for pod", "podName", pod.Name, "cpuUsage", curPodCPUUsage)
-	if pod.Spec.Overhead != nil {
-		curPodCPUUsage += pod.Spec.Overhead.Cpu().MilliValue()
+		switch pl.args.StaleMetricsPolicy {
+		case pluginConfig.StaleMetricsNeutralScore:
+			klog.InfoS("Failed to get fresh metrics for node; using neutral score", "nodeName", nodeName)
+			return trimaran.NeutralScore, nil
+		case pluginConfig.StaleMetricsFallbackToRequests:
+			klog.InfoS("Failed to get fresh metrics for node; using requests", "nodeName", nodeName)
+			fallbackToRequests = true
+		default:
+			klog.InfoS("Failed to get fresh metrics for node; using minimum score", "nodeName", nodeName)
+			// Avoid the node by scoring minimum
+			return score, nil
+		}
 	}
 
-	var nodeCPUUtilPercent float64
-	var cpuMetricFound bool
-	for _, metric := range metrics {
-		if metric.Type == watcher.CPU {
-			if metric.Operator == watcher.Average || metric.Operator == watcher.Latest {
-				nodeCPUUtilPercent = metric.Value
-				cpuMetricFound = true
-			}
+	// utilization of the node by packed resource, in millicores for CPU
+	nodeUtil := make(map[v1.ResourceName]float64, len(pl.resources))
+	nodeCapacity := nodeInfo.Node().Status.Capacity
+	for _, r := range pl.resources {
+		if fallbackToRequests {
+			// The requests of the pods on the node, including the pods scheduled recently, stand for its utilization.
+			nodeUtil[r.name] = float64(trimaran.RequestedValue(nodeInfo.Requested, r.name))
+			continue
 		}
+		utilPercent, found := getUtilisationPercent(metrics, r.metricType)
+		if !found {
+			klog.V(6).InfoS("Resource metric not found in node metrics", "nodeName", nodeName, "resource", r.name, "nodeMetrics", metrics)
+			continue
+		}
+		nodeUtil[r.name] = (utilPercent / 100) * float64(trimaran.QuantityValue(r.name, nodeCapacity[r.name]))
 	}
-
-	if !cpuMetricFound {
-		klog.ErrorS(nil, "Cpu metric not found in node metrics", "nodeName", nodeName, "nodeMetrics", metrics)
+	if len(nodeUtil) == 0 {
+		klog.ErrorS(nil, "Metrics of packed resources not found in node metrics", "nodeName", nodeName, "nodeMetrics", metrics)
 		return score, nil
 	}
-	nodeCPUCapMillis := float64(nodeInfo.Node().Status.Capacity.Cpu().MilliValue())
-	nodeCPUUtilMillis := (nodeCPUUtilPercent / 100) * nodeCPUCapMillis
-
-	klog.V(6).InfoS("Calculating CPU utilization and capacity", "nodeName", nodeName, "cpuUtilMillis", nodeCPUUtilMillis, "cpuCapMillis", nodeCPUCapMillis)
-
-	var missingCPUUtilMillis int64 = 0
-	pl.eventHandler.RLock()
-	for _, info := range pl.eventHandler.ScheduledPodsCache[nodeName] {
-		// If the time stamp of the scheduled pod is outside fetched metrics window, or it is within metrics reporting interval seconds, we predict util.
-		// Note that the second condition doesn't guarantee metrics for that pod are not reported yet as the 0 <= t <= 2*metricsAgentReportingIntervalSeconds
-		// t = metricsAgentReportingIntervalSeconds is taken as average case and it doesn't hurt us much if we are
-		// counting metrics twice in case actual t is less than metricsAgentReportingIntervalSeconds
-		if info.Timestamp.Unix() > allMetrics.Window.End || info.Timestamp.Unix() <= allMetrics.Window.End &&
-			(allMetrics.Window.End-info.Timestamp.Unix()) < metricsAgentReportingIntervalSeconds {
-			for _, container := range info.Pod.Spec.Containers {
-				missingCPUUtilMillis += PredictUtilisation(&container)
-			}
-			missingCPUUtilMillis += info.Pod.Spec.Overhead.Cpu().MilliValue()
-			klog.V(6).InfoS("Missing utilization for pod", "podName", info.Pod.Name, "missingCPUUtilMillis", missingCPUUtilMillis)
+
+	missingUtil := make(map[v1.ResourceName]int64, len(nodeUtil))
+	if !fallbackToRequests {
+		missingDelta := pl.eventHandler.GetPredictedDelta(nodeName, allMetrics.Window.End)
+		for resourceName := range nodeUtil {
+			missingUtil[resourceName] = trimaran.RequestedValue(missingDelta, resourceName)
 		}
+		klog.V(6).InfoS("Missing utilization for node", "nodeName", nodeName, "missingUtil", missingUtil)
 	}
-	pl.eventHandler.RUnlock()
-	klog.V(6).InfoS("Missing utilization for node", "nodeName", nodeName, "missingCPUUtilMillis", missingCPUUtilMillis)
 
-	var predictedCPUUsage float64
-	if nodeCPUCapMillis != 0 {
-		predictedCPUUsage = 100 * (nodeCPUUtilMillis + float64(curPodCPUUsage) + float64(missingCPUUtilMillis)) / nodeCPUCapMillis
-	}
-	if predictedCPUUsage > float64(hostTargetUtilizationPercent) {
-		if predictedCPUUsage > 100 {
-			return score, framework.NewStatus(framework.Success, "")
+	// the pod may override the target utilization of the args, within the bounds of the args,
+	// which scales the target utilization of every packed resource by the same ratio
+	targetOverride, overridden := trimaran.GetArgOverride(pod, trimaran.AnnotationKeyTargetUtilization,
+		float64(pl.args.MinTargetUtilization), float64(pl.args.MaxTargetUtilization))
+
+	scores := make([]resourceScoreInfo, 0, len(nodeUtil))
+	for _, r := range pl.resources {
+		util, ok := nodeUtil[r.name]
+		if !ok {
+			continue
+		}
+		targetUtilization := r.targetUtilization
+		if overridden {
+			targetUtilization = overriddenTargetUtilization(r.targetUtilization, pl.args.TargetUtilization, targetOverride)
 		}
-		penalisedScore := int64(math.Round(float64(hostTargetUtilizationPercent) * (100 - predictedCPUUsage) / (100 - float64(hostTargetUtilizationPercent))))
-		klog.V(6).InfoS("Penalised score for host", "nodeName", nodeName, "penalisedScore", penalisedScore)
-		return penalisedScore, framework.NewStatus(framework.Success, "")
+		podUsage := pl.predictPodUtilisation(pod, r.name)
+		capacity := float64(trimaran.QuantityValue(r.name, nodeCapacity[r.name]))
+		klog.V(6).InfoS("Calculating utilization and capacity", "nodeName", nodeName, "resource", r.name,
+			"util", util, "capacity", capacity, "podUsage", podUsage)
+
+		var predictedUsage float64
+		if capacity != 0 {
+			predictedUsage = 100 * (util + float64(podUsage) + float64(missingUtil[r.name])) / capacity
+		}
+		score := resourceScore(predictedUsage, targetUtilization)
+		klog.V(6).InfoS("Score for resource", "nodeName", nodeName, "resource", r.name,
+			"predictedUsage", predictedUsage, "targetUtilization", targetUtilization, "score", score)
+		scores = append(scores, resourceScoreInfo{
+			score:      score,
+			weight:     r.weight,
+			overTarget: predictedUsage > float64(targetUtilization),
+		})
 	}
 
-	score = int64(math.Round((100-float64(hostTargetUtilizationPercent))*
-		predictedCPUUsage/float64(hostTargetUtilizationPercent) + float64(hostTargetUtilizationPercent)))
+	score = combineScores(scores)
 	klog.V(6).InfoS("Score for host", "nodeName", nodeName, "score", score)
 	return score, framework.NewStatus(framework.Success, "")
 }
 
+// Filter : filter out the node if its metrics are missing or stale and the StaleMetricsPolicy is Filter
+func (pl *TargetLoadPacking) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	return trimaran.FilterStaleNode(pl.collector, pl.args.StaleMetricsPolicy, nodeInfo.Node().Name)
+}
+
 func (pl *TargetLoadPacking) ScoreExtensions() framework.ScoreExtensions {
 	return pl
 }
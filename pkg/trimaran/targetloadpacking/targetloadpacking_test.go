@@ -25,6 +25,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	"github.com/stretchr/testify/assert"
@@ -32,8 +33,12 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	testClientSet "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config"
@@ -46,6 +51,7 @@ import (
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	cfgv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
 )
 
 var _ framework.SharedLister = &testSharedLister{}
@@ -109,6 +115,147 @@ func TestNew(t *testing.T) {
 	p, err := New(ctx, &targetLoadPackingArgs, fh)
 	assert.NotNil(t, p)
 	assert.Nil(t, err)
+
+	for _, bounds := range [][2]int64{{0, 60}, {70, 60}, {10, 100}} {
+		badArgs := targetLoadPackingArgs
+		badArgs.MinTargetUtilization, badArgs.MaxTargetUtilization = bounds[0], bounds[1]
+		_, err = New(ctx, &badArgs, fh)
+		assert.Error(t, err, "bounds %v", bounds)
+	}
+}
+
+func TestTargetLoadPackingTargetUtilizationOverride(t *testing.T) {
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		tf.RegisterScorePlugin(Name, New, 1),
+	}
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	// an idle node scores the target utilization
+	watcherResponse := watcher.WatcherMetrics{
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Value:    0,
+							Operator: watcher.Latest,
+						},
+						{
+							Type:     watcher.Memory,
+							Value:    0,
+							Operator: watcher.Average,
+						},
+					},
+				},
+			},
+		},
+	}
+	// the targets of the resources are scaled by the ratio of the override to the target utilization of the args
+	resources := []pluginConfig.TargetLoadPackingResource{
+		{Name: v1.ResourceCPU, TargetUtilization: cfgv1.DefaultTargetUtilizationPercent, Weight: 1},
+		{Name: v1.ResourceMemory, TargetUtilization: cfgv1.DefaultTargetUtilizationPercent / 2, Weight: 1},
+	}
+
+	tests := []struct {
+		test                 string
+		annotations          map[string]string
+		resources            []pluginConfig.TargetLoadPackingResource
+		maxTargetUtilization int64
+		expected             int64
+	}{
+		{
+			test:                 "target utilization overridden",
+			annotations:          map[string]string{trimaran.AnnotationKeyTargetUtilization: "20"},
+			maxTargetUtilization: 60,
+			expected:             20,
+		},
+		{
+			test:                 "target utilization overridden up to the max",
+			annotations:          map[string]string{trimaran.AnnotationKeyTargetUtilization: "90"},
+			maxTargetUtilization: 60,
+			expected:             60,
+		},
+		{
+			test:                 "target utilization overridden down to the min",
+			annotations:          map[string]string{trimaran.AnnotationKeyTargetUtilization: "1"},
+			maxTargetUtilization: 60,
+			expected:             10,
+		},
+		{
+			test:                 "target utilization overridden for resources with different targets",
+			annotations:          map[string]string{trimaran.AnnotationKeyTargetUtilization: "20"},
+			resources:            resources,
+			maxTargetUtilization: 60,
+			expected:             (20 + 10) / 2,
+		},
+		{
+			test:                 "target utilization overridden up to the max for resources with different targets",
+			annotations:          map[string]string{trimaran.AnnotationKeyTargetUtilization: "90"},
+			resources:            resources,
+			maxTargetUtilization: 60,
+			expected:             (60 + 30) / 2,
+		},
+		{
+			test:                 "no annotation",
+			maxTargetUtilization: 60,
+			expected:             cfgv1.DefaultTargetUtilizationPercent,
+		},
+		{
+			test:                 "no annotation for resources with different targets",
+			resources:            resources,
+			maxTargetUtilization: 60,
+			expected:             (cfgv1.DefaultTargetUtilizationPercent + cfgv1.DefaultTargetUtilizationPercent/2) / 2,
+		},
+		{
+			test:        "overrides disabled",
+			annotations: map[string]string{trimaran.AnnotationKeyTargetUtilization: "20"},
+			expected:    cfgv1.DefaultTargetUtilizationPercent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				bytes, err := json.Marshal(watcherResponse)
+				assert.Nil(t, err)
+				resp.Write(bytes)
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+			targetLoadPackingArgs := pluginConfig.TargetLoadPackingArgs{
+				TrimaranSpec:              pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+				TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
+				DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+				Resources:                 tt.resources,
+				MinTargetUtilization:      10,
+				MaxTargetUtilization:      tt.maxTargetUtilization,
+			}
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister(nil, nodes)
+			fh, err := testutil.NewFramework(ctx, registeredPlugins,
+				[]config.PluginConfig{{Name: Name, Args: &targetLoadPackingArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &targetLoadPackingArgs, fh)
+			assert.Nil(t, err)
+
+			pod := st.MakePod().Name("p").Annotations(tt.annotations).Obj()
+			score, status := p.(framework.ScorePlugin).Score(context.Background(), framework.NewCycleState(), pod, "node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expected, score)
+		})
+	}
 }
 
 func TestTargetLoadPackingScoring(t *testing.T) {
@@ -274,6 +421,298 @@ func TestTargetLoadPackingScoring(t *testing.T) {
 	}
 }
 
+func TestTargetLoadPackingStaleMetrics(t *testing.T) {
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		tf.RegisterScorePlugin(Name, New, 1),
+	}
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+	nodePod := getPodWithContainersAndOverhead(0, 300)
+	nodePod.Spec.NodeName = "node-1"
+
+	// The metrics were collected 10 minutes ago, and are stale after 1 minute.
+	watcherResponse := watcher.WatcherMetrics{
+		Window: watcher.Window{
+			End: time.Now().Add(-10 * time.Minute).Unix(),
+		},
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Value:    0,
+							Operator: watcher.Latest,
+						},
+					},
+				},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		test           string
+		policy         pluginConfig.StaleMetricsPolicyType
+		expectedScore  int64
+		expectedFilter framework.Code
+	}{
+		{
+			test:           "minimum score",
+			policy:         pluginConfig.StaleMetricsMinScore,
+			expectedScore:  framework.MinNodeScore,
+			expectedFilter: framework.Success,
+		},
+		{
+			test:   "fallback to requests",
+			policy: pluginConfig.StaleMetricsFallbackToRequests,
+			// 300m requested on the node and 200m predicted for the pod is above the target utilization.
+			expectedScore:  33,
+			expectedFilter: framework.Success,
+		},
+		{
+			test:           "neutral score",
+			policy:         pluginConfig.StaleMetricsNeutralScore,
+			expectedScore:  50,
+			expectedFilter: framework.Success,
+		},
+		{
+			test:           "filter",
+			policy:         pluginConfig.StaleMetricsFilter,
+			expectedScore:  framework.MinNodeScore,
+			expectedFilter: framework.UnschedulableAndUnresolvable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			targetLoadPackingArgs := pluginConfig.TargetLoadPackingArgs{
+				TrimaranSpec: pluginConfig.TrimaranSpec{
+					WatcherAddress:                   server.URL,
+					MetricsStalenessThresholdSeconds: 60,
+					StaleMetricsPolicy:               tt.policy,
+				},
+				TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
+				DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+			}
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister([]*v1.Pod{nodePod}, nodes)
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{{Name: Name, Args: &targetLoadPackingArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &targetLoadPackingArgs, fh)
+			assert.Nil(t, err)
+
+			pod := getPodWithContainersAndOverhead(0, 200)
+			score, status := p.(framework.ScorePlugin).Score(ctx, framework.NewCycleState(), pod, "node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expectedScore, score)
+
+			nodeInfo, err := snapshot.Get("node-1")
+			assert.Nil(t, err)
+			status = p.(framework.FilterPlugin).Filter(ctx, framework.NewCycleState(), pod, nodeInfo)
+			assert.Equal(t, tt.expectedFilter, status.Code())
+		})
+	}
+}
+
+func TestTargetLoadPackingMultiResourceScoring(t *testing.T) {
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		tf.RegisterScorePlugin(Name, New, 1),
+	}
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+		"nvidia.com/gpu":  "4",
+	}
+	nodeMetrics := func(memoryPercent float64) []watcher.Metric {
+		return []watcher.Metric{
+			{Type: watcher.CPU, Operator: watcher.Average, Value: 10},
+			{Type: watcher.Memory, Operator: watcher.Average, Value: memoryPercent},
+			{Type: "nvidia.com/gpu", Operator: watcher.Average, Value: 50},
+		}
+	}
+
+	tests := []struct {
+		test      string
+		resources []pluginConfig.TargetLoadPackingResource
+		metrics   []watcher.Metric
+		expected  int64
+	}{
+		{
+			test: "weighted average of resources below target",
+			resources: []pluginConfig.TargetLoadPackingResource{
+				{Name: v1.ResourceCPU, TargetUtilization: 40, Weight: 1},
+				{Name: v1.ResourceMemory, TargetUtilization: 60, Weight: 1},
+			},
+			metrics: nodeMetrics(30),
+			// cpu: 55, memory: 80
+			expected: 68,
+		},
+		{
+			test: "tightest resource above target",
+			resources: []pluginConfig.TargetLoadPackingResource{
+				{Name: v1.ResourceCPU, TargetUtilization: 40, Weight: 1},
+				{Name: v1.ResourceMemory, TargetUtilization: 60, Weight: 1},
+			},
+			metrics: nodeMetrics(80),
+			// cpu: 55, memory: penalised 30
+			expected: 30,
+		},
+		{
+			test: "extended resource reported by the metric provider",
+			resources: []pluginConfig.TargetLoadPackingResource{
+				{Name: v1.ResourceCPU, TargetUtilization: 40, Weight: 1},
+				{Name: "nvidia.com/gpu", TargetUtilization: 50, Weight: 3},
+			},
+			metrics: nodeMetrics(30),
+			// cpu: 55, gpu: 100
+			expected: 89,
+		},
+		{
+			test: "resource without metrics is ignored",
+			resources: []pluginConfig.TargetLoadPackingResource{
+				{Name: v1.ResourceCPU, TargetUtilization: 40, Weight: 1},
+				{Name: v1.ResourceMemory, TargetUtilization: 60, Weight: 1},
+			},
+			metrics:  []watcher.Metric{{Type: watcher.CPU, Operator: watcher.Average, Value: 10}},
+			expected: 55,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			watcherResponse := watcher.WatcherMetrics{
+				Data: watcher.Data{
+					NodeMetricsMap: map[string]watcher.NodeMetrics{
+						"node-1": {Metrics: tt.metrics},
+					},
+				},
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				bytes, err := json.Marshal(watcherResponse)
+				assert.Nil(t, err)
+				resp.Write(bytes)
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+			targetLoadPackingArgs := pluginConfig.TargetLoadPackingArgs{
+				TrimaranSpec:              pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+				TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
+				DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+				Resources:                 tt.resources,
+			}
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister(nil, nodes)
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{{Name: Name, Args: &targetLoadPackingArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &targetLoadPackingArgs, fh)
+			assert.Nil(t, err)
+
+			score, status := p.(framework.ScorePlugin).Score(ctx, framework.NewCycleState(), st.MakePod().Name("p").Obj(), "node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expected, score)
+		})
+	}
+}
+
+func TestNewPackedResources(t *testing.T) {
+	args := &pluginConfig.TargetLoadPackingArgs{
+		TargetUtilization: 40,
+	}
+	resources, err := newPackedResources(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []packedResource{{name: v1.ResourceCPU, metricType: watcher.CPU, targetUtilization: 40, weight: 1}}, resources)
+
+	args.Resources = []pluginConfig.TargetLoadPackingResource{{Name: v1.ResourceMemory}, {Name: "nvidia.com/gpu", TargetUtilization: 70, Weight: 2}}
+	resources, err = newPackedResources(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []packedResource{
+		{name: v1.ResourceMemory, metricType: watcher.Memory, targetUtilization: 40, weight: 1},
+		{name: "nvidia.com/gpu", metricType: "nvidia.com/gpu", targetUtilization: 70, weight: 2},
+	}, resources)
+
+	args.Resources = []pluginConfig.TargetLoadPackingResource{{Name: v1.ResourceMemory, TargetUtilization: 100}}
+	_, err = newPackedResources(args)
+	assert.EqualError(t, err, "invalid target utilization of resource memory, got 100")
+}
+
+// staticPodUsageSource : pod usage source standing in for the resource metrics API
+type staticPodUsageSource struct {
+	usage map[types.NamespacedName]map[v1.ResourceName]int64
+}
+
+func (s *staticPodUsageSource) GetPodsUsage(context.Context) (map[types.NamespacedName]map[v1.ResourceName]int64, error) {
+	return s.usage, nil
+}
+
+func TestPredictPodUtilisationUsageProfiles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	isController := true
+	runningPod := st.MakePod().Namespace("default").Name("db-0").Obj()
+	runningPod.OwnerReferences = []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &isController}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.Nil(t, indexer.Add(runningPod))
+	source := &staticPodUsageSource{
+		usage: map[types.NamespacedName]map[v1.ResourceName]int64{
+			{Namespace: "default", Name: "db-0"}: {v1.ResourceCPU: 300},
+		},
+	}
+	usageProfiles, err := trimaran.NewUsageProfiles(ctx, &pluginConfig.UsageProfilesSpec{
+		UpdateIntervalSeconds: 60,
+		Smoothing:             cfgv1.DefaultUsageProfilesSmoothing,
+		MinSamples:            1,
+	}, source, corelisters.NewPodLister(indexer))
+	assert.Nil(t, err)
+	pl := &TargetLoadPacking{
+		args:          &pluginConfig.TargetLoadPackingArgs{},
+		usageProfiles: usageProfiles,
+	}
+
+	// a new replica of the workload is predicted to use as much as the running replica, rather than its limits
+	newReplica := st.MakePod().Namespace("default").Name("db-1").Obj()
+	newReplica.OwnerReferences = runningPod.OwnerReferences
+	newReplica.Spec.Containers = []v1.Container{{
+		Resources: v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+		},
+	}}
+	assert.Eventually(t, func() bool {
+		return pl.predictPodUtilisation(newReplica, v1.ResourceCPU) == 300
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the pods of unknown workloads are predicted from their requests and limits
+	otherPod := newReplica.DeepCopy()
+	otherPod.OwnerReferences = []metav1.OwnerReference{{Kind: "StatefulSet", Name: "cache", Controller: &isController}}
+	assert.Equal(t, int64(1000), pl.predictPodUtilisation(otherPod, v1.ResourceCPU))
+}
+
 func BenchmarkTargetLoadPackingPlugin(b *testing.B) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// usageProfileRetention : time after which the usage profile of a workload without any pod observed is forgotten
+const usageProfileRetention = 24 * time.Hour
+
+// PodUsageSource : source of the actual usage of the running pods
+type PodUsageSource interface {
+	// GetPodsUsage : get the usage of the running pods, by namespace and name, in millicores for CPU
+	GetPodsUsage(ctx context.Context) (map[types.NamespacedName]map[v1.ResourceName]int64, error)
+}
+
+// metricsAPIPodUsageSource : pod usage source querying the resource metrics API, e.g. served by the metrics server
+type metricsAPIPodUsageSource struct {
+	client metricsv.Interface
+}
+
+// NewMetricsAPIPodUsageSource : create a pod usage source querying the resource metrics API
+func NewMetricsAPIPodUsageSource(config *rest.Config) (PodUsageSource, error) {
+	if config == nil {
+		return nil, fmt.Errorf("unable to create resource metrics client: missing kube config")
+	}
+	client, err := metricsv.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create resource metrics client: %v", err)
+	}
+	return &metricsAPIPodUsageSource{client: client}, nil
+}
+
+// GetPodsUsage : get the usage of the running pods from the resource metrics API
+func (s *metricsAPIPodUsageSource) GetPodsUsage(ctx context.Context) (map[types.NamespacedName]map[v1.ResourceName]int64, error) {
+	podMetricsList, err := s.client.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[types.NamespacedName]map[v1.ResourceName]int64, len(podMetricsList.Items))
+	for _, podMetrics := range podMetricsList.Items {
+		podUsage := make(map[v1.ResourceName]int64)
+		for _, container := range podMetrics.Containers {
+			for resourceName, quantity := range container.Usage {
+				podUsage[resourceName] += QuantityValue(resourceName, quantity)
+			}
+		}
+		usage[types.NamespacedName{Namespace: podMetrics.Namespace, Name: podMetrics.Name}] = podUsage
+	}
+	return usage, nil
+}
+
+// workloadKey : identity of a workload, i.e. of the pods with the same controller
+type workloadKey struct {
+	namespace string
+	kind      string
+	name      string
+}
+
+// usageProfile : actual usage of the pods of a workload
+type usageProfile struct {
+	// smoothed usage of a pod, in millicores for CPU
+	usage map[v1.ResourceName]float64
+	// number of updates the workload was observed in
+	samples int64
+	// time of the last update the workload was observed in
+	lastSeen time.Time
+}
+
+// UsageProfiles : usage profiles of the workloads, learned from the actual usage of their pods
+//
+// The usage of a pod of a workload is the average usage of its running pods, smoothed exponentially across
+// the updates. The pods of the successive ReplicaSets of a Deployment belong to the same workload.
+type UsageProfiles struct {
+	source    PodUsageSource
+	podLister corelisters.PodLister
+	// usage profiles, by workload
+	profiles map[workloadKey]*usageProfile
+	// for safe access to profiles
+	mu sync.RWMutex
+
+	// weight of the latest usage
+	smoothing float64
+	// number of updates a workload is observed in before its usage profile is used
+	minSamples int64
+}
+
+// NewUsageProfiles : create the usage profiles of the workloads, updating them periodically until ctx is done
+func NewUsageProfiles(ctx context.Context, usageProfilesSpec *pluginConfig.UsageProfilesSpec, source PodUsageSource,
+	podLister corelisters.PodLister) (*UsageProfiles, error) {
+	if usageProfilesSpec.Smoothing <= 0 || usageProfilesSpec.Smoothing > 1 {
+		return nil, fmt.Errorf("invalid UsageProfiles.Smoothing, got %v", usageProfilesSpec.Smoothing)
+	}
+	u := &UsageProfiles{
+		source:     source,
+		podLister:  podLister,
+		profiles:   make(map[workloadKey]*usageProfile),
+		smoothing:  usageProfilesSpec.Smoothing,
+		minSamples: usageProfilesSpec.MinSamples,
+	}
+	updateInterval := time.Duration(usageProfilesSpec.UpdateIntervalSeconds) * time.Second
+	if updateInterval <= 0 {
+		return nil, fmt.Errorf("invalid UsageProfiles.UpdateIntervalSeconds, got %v", usageProfilesSpec.UpdateIntervalSeconds)
+	}
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := u.update(ctx); err != nil {
+			klog.ErrorS(err, "Unable to update usage profiles")
+		}
+	}, updateInterval)
+	return u, nil
+}
+
+// update : update the usage profiles with the latest usage of the pods
+func (u *UsageProfiles) update(ctx context.Context) error {
+	podsUsage, err := u.source.GetPodsUsage(ctx)
+	if err != nil {
+		return err
+	}
+	pods, err := u.podLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	// average usage of the pods of each workload
+	usageSums := make(map[workloadKey]map[v1.ResourceName]int64)
+	podCounts := make(map[workloadKey]int64)
+	for _, pod := range pods {
+		podUsage, ok := podsUsage[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
+		if !ok {
+			continue
+		}
+		key, ok := getWorkloadKey(pod)
+		if !ok {
+			continue
+		}
+		if _, ok := usageSums[key]; !ok {
+			usageSums[key] = make(map[v1.ResourceName]int64)
+		}
+		for resourceName, usage := range podUsage {
+			usageSums[key][resourceName] += usage
+		}
+		podCounts[key]++
+	}
+
+	now := time.Now()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for key, usageSum := range usageSums {
+		profile, ok := u.profiles[key]
+		if !ok {
+			profile = &usageProfile{usage: make(map[v1.ResourceName]float64)}
+			u.profiles[key] = profile
+		}
+		for resourceName, sum := range usageSum {
+			usage := float64(sum) / float64(podCounts[key])
+			if previous, ok := profile.usage[resourceName]; ok {
+				usage = u.smoothing*usage + (1-u.smoothing)*previous
+			}
+			profile.usage[resourceName] = usage
+		}
+		profile.samples++
+		profile.lastSeen = now
+		klog.V(6).InfoS("Updated usage profile", "namespace", key.namespace, "kind", key.kind, "name", key.name,
+			"pods", podCounts[key], "usage", profile.usage, "samples", profile.samples)
+	}
+	for key, profile := range u.profiles {
+		if now.Sub(profile.lastSeen) > usageProfileRetention {
+			delete(u.profiles, key)
+		}
+	}
+	return nil
+}
+
+// GetPodUsage : get the usage of a resource by the pod from the usage profile of its workload, in millicores for CPU;
+// false if the workload is unknown or not observed long enough
+func (u *UsageProfiles) GetPodUsage(pod *v1.Pod, resourceName v1.ResourceName) (int64, bool) {
+	key, ok := getWorkloadKey(pod)
+	if !ok {
+		return 0, false
+	}
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	profile, ok := u.profiles[key]
+	if !ok || profile.samples < u.minSamples {
+		return 0, false
+	}
+	usage, ok := profile.usage[resourceName]
+	if !ok {
+		return 0, false
+	}
+	return int64(usage), true
+}
+
+// getWorkloadKey : get the workload of a pod from its controller, the Deployment of the ReplicaSet if any;
+// false if the pod has no controller
+func getWorkloadKey(pod *v1.Pod) (workloadKey, bool) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return workloadKey{}, false
+	}
+	key := workloadKey{namespace: pod.Namespace, kind: owner.Kind, name: owner.Name}
+	// The ReplicaSets of a Deployment are named after it and the hash of their pod template.
+	if hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok && owner.Kind == "ReplicaSet" &&
+		strings.HasSuffix(owner.Name, "-"+hash) {
+		key.kind = "Deployment"
+		key.name = strings.TrimSuffix(owner.Name, "-"+hash)
+	}
+	return key, true
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// staticPodUsageSource : pod usage source standing in for the resource metrics API
+type staticPodUsageSource struct {
+	usage map[types.NamespacedName]map[v1.ResourceName]int64
+}
+
+func (s *staticPodUsageSource) GetPodsUsage(context.Context) (map[types.NamespacedName]map[v1.ResourceName]int64, error) {
+	return s.usage, nil
+}
+
+func makeWorkloadPod(name, ownerKind, ownerName, hash string) *v1.Pod {
+	pod := st.MakePod().Namespace("default").Name(name).Obj()
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &[]bool{true}[0]}}
+	}
+	if hash != "" {
+		pod.Labels = map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash}
+	}
+	return pod
+}
+
+func newPodLister(t *testing.T, pods ...*v1.Pod) corelisters.PodLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, pod := range pods {
+		assert.Nil(t, indexer.Add(pod))
+	}
+	return corelisters.NewPodLister(indexer)
+}
+
+func TestGetWorkloadKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		pod         *v1.Pod
+		expectedKey workloadKey
+		expectedOk  bool
+	}{
+		{
+			name:        "pod of a Deployment",
+			pod:         makeWorkloadPod("web-5d8f-x", "ReplicaSet", "web-5d8f", "5d8f"),
+			expectedKey: workloadKey{namespace: "default", kind: "Deployment", name: "web"},
+			expectedOk:  true,
+		},
+		{
+			name:        "pod of a bare ReplicaSet",
+			pod:         makeWorkloadPod("web-x", "ReplicaSet", "web", ""),
+			expectedKey: workloadKey{namespace: "default", kind: "ReplicaSet", name: "web"},
+			expectedOk:  true,
+		},
+		{
+			name:        "pod of a StatefulSet",
+			pod:         makeWorkloadPod("db-0", "StatefulSet", "db", ""),
+			expectedKey: workloadKey{namespace: "default", kind: "StatefulSet", name: "db"},
+			expectedOk:  true,
+		},
+		{
+			name: "pod without controller",
+			pod:  makeWorkloadPod("bare", "", "", ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := getWorkloadKey(tt.pod)
+			assert.Equal(t, tt.expectedOk, ok)
+			assert.Equal(t, tt.expectedKey, key)
+		})
+	}
+}
+
+func TestUsageProfiles(t *testing.T) {
+	web1 := makeWorkloadPod("web-abc-1", "ReplicaSet", "web-abc", "abc")
+	web2 := makeWorkloadPod("web-abc-2", "ReplicaSet", "web-abc", "abc")
+	db := makeWorkloadPod("db-0", "StatefulSet", "db", "")
+	bare := makeWorkloadPod("bare", "", "", "")
+	source := &staticPodUsageSource{
+		usage: map[types.NamespacedName]map[v1.ResourceName]int64{
+			{Namespace: "default", Name: "web-abc-1"}: {v1.ResourceCPU: 100, v1.ResourceMemory: 1000},
+			{Namespace: "default", Name: "web-abc-2"}: {v1.ResourceCPU: 200, v1.ResourceMemory: 3000},
+			{Namespace: "default", Name: "bare"}:      {v1.ResourceCPU: 500},
+		},
+	}
+	u := &UsageProfiles{
+		source:     source,
+		podLister:  newPodLister(t, web1, web2, db, bare),
+		profiles:   make(map[workloadKey]*usageProfile),
+		smoothing:  0.5,
+		minSamples: 2,
+	}
+	// a new replica of the Deployment, from a new ReplicaSet
+	newReplica := makeWorkloadPod("web-def-1", "ReplicaSet", "web-def", "def")
+
+	assert.Nil(t, u.update(context.TODO()))
+	_, ok := u.GetPodUsage(newReplica, v1.ResourceCPU)
+	assert.False(t, ok, "workload observed in a single update")
+
+	source.usage[types.NamespacedName{Namespace: "default", Name: "web-abc-1"}] = map[v1.ResourceName]int64{v1.ResourceCPU: 250}
+	source.usage[types.NamespacedName{Namespace: "default", Name: "web-abc-2"}] = map[v1.ResourceName]int64{v1.ResourceCPU: 250}
+	assert.Nil(t, u.update(context.TODO()))
+	usage, ok := u.GetPodUsage(newReplica, v1.ResourceCPU)
+	assert.True(t, ok)
+	// 0.5 * 250 + 0.5 * 150
+	assert.Equal(t, int64(200), usage)
+	// the memory is missing from the latest usage
+	usage, ok = u.GetPodUsage(newReplica, v1.ResourceMemory)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2000), usage)
+
+	_, ok = u.GetPodUsage(db, v1.ResourceCPU)
+	assert.False(t, ok, "workload without usage")
+	_, ok = u.GetPodUsage(bare, v1.ResourceCPU)
+	assert.False(t, ok, "pod without controller")
+
+	// the profiles of the workloads without pods observed for a while are forgotten
+	u.profiles[workloadKey{namespace: "default", kind: "Deployment", name: "web"}].lastSeen = time.Now().Add(-48 * time.Hour)
+	source.usage = nil
+	assert.Nil(t, u.update(context.TODO()))
+	assert.Empty(t, u.profiles)
+}
+
+func TestNewUsageProfiles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pod := makeWorkloadPod("db-0", "StatefulSet", "db", "")
+	source := &staticPodUsageSource{
+		usage: map[types.NamespacedName]map[v1.ResourceName]int64{
+			{Namespace: "default", Name: "db-0"}: {v1.ResourceCPU: 300},
+		},
+	}
+	usageProfilesSpec := pluginConfig.UsageProfilesSpec{
+		UpdateIntervalSeconds: 60,
+		Smoothing:             0.3,
+		MinSamples:            1,
+	}
+	u, err := NewUsageProfiles(ctx, &usageProfilesSpec, source, newPodLister(t, pod))
+	assert.Nil(t, err)
+	// the profiles are updated right away
+	assert.Eventually(t, func() bool {
+		usage, ok := u.GetPodUsage(pod, v1.ResourceCPU)
+		return ok && usage == 300
+	}, 5*time.Second, 10*time.Millisecond)
+
+	usageProfilesSpec.Smoothing = 0
+	_, err = NewUsageProfiles(ctx, &usageProfilesSpec, source, newPodLister(t, pod))
+	assert.EqualError(t, err, "invalid UsageProfiles.Smoothing, got 0")
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+var (
+	debugServersLock sync.Mutex
+	// debugServers are the debug servers of the process by address, shared by the endpoints served on the same address.
+	debugServers = make(map[string]*debugServer)
+)
+
+// debugServer serves the handlers of the debug endpoints registered on its address by path.
+type debugServer struct {
+	server *http.Server
+
+	lock     sync.RWMutex
+	handlers map[string]http.Handler
+}
+
+// ServeDebug serves the handler of a debug endpoint on the path and the address until ctx is done.
+// The endpoints served on the same address share a single server, which is closed once none is left.
+// It fails if the address can't be listened on, or the path is already served on the address.
+// The endpoint is not authenticated, so the address should be reachable only from trusted clients.
+func ServeDebug(ctx context.Context, lh logr.Logger, address, path string, handler http.Handler) error {
+	debugServersLock.Lock()
+	defer debugServersLock.Unlock()
+
+	server, ok := debugServers[address]
+	if !ok {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return fmt.Errorf("cannot listen on debug address %v: %w", address, err)
+		}
+		server = newDebugServer(address)
+		debugServers[address] = server
+		go func() {
+			if err := server.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				lh.Error(err, "cannot serve debug endpoints", "address", address)
+			}
+		}()
+	}
+	if !server.handle(path, handler) {
+		return fmt.Errorf("debug path %v is already served on %v", path, address)
+	}
+	lh.V(4).Info("serving debug endpoint", "address", address, "path", path)
+
+	go func() {
+		<-ctx.Done()
+		debugServersLock.Lock()
+		defer debugServersLock.Unlock()
+		if server.remove(path) == 0 {
+			delete(debugServers, address)
+			server.server.Close()
+		}
+	}()
+	return nil
+}
+
+// ProfileDebugPath returns the path of the debug endpoint of the scheduler profile of the handle, so that
+// the profiles enabling the same plugin can share the debug address. The handles of the scheduler are the
+// frameworks of the profiles, the path is returned as is for the other ones.
+func ProfileDebugPath(path string, handle framework.Handle) string {
+	fwk, ok := handle.(framework.Framework)
+	if !ok {
+		return path
+	}
+	return path + "/" + fwk.ProfileName()
+}
+
+func newDebugServer(address string) *debugServer {
+	server := &debugServer{
+		handlers: make(map[string]http.Handler),
+	}
+	server.server = &http.Server{
+		Addr:              address,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server
+}
+
+// handle registers the handler of the path, it returns false if the path already has one.
+func (s *debugServer) handle(path string, handler http.Handler) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.handlers[path]; ok {
+		return false
+	}
+	s.handlers[path] = handler
+	return true
+}
+
+// remove unregisters the handler of the path, and returns the number of handlers left.
+func (s *debugServer) remove(path string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.handlers, path)
+	return len(s.handlers)
+}
+
+func (s *debugServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.lock.RLock()
+	handler, ok := s.handlers[req.URL.Path]
+	s.lock.RUnlock()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	handler.ServeHTTP(w, req)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestDebugServer(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := newDebugServer("localhost:0")
+	if !server.handle("/debug/test", handler) {
+		t.Fatalf("cannot register the handler")
+	}
+	if server.handle("/debug/test", handler) {
+		t.Errorf("expected the handler of a served path not to be registered")
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "debug path",
+			path:       "/debug/test",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "other path",
+			path:       "/metrics",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestServeDebug(t *testing.T) {
+	const address = "localhost:0"
+	handler := http.NotFoundHandler()
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+
+	// The endpoints on the same address share a server.
+	if err := ServeDebug(ctx1, logr.Discard(), address, "/debug/a", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ServeDebug(ctx2, logr.Discard(), address, "/debug/b", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ServeDebug(ctx2, logr.Discard(), address, "/debug/a", handler); err == nil {
+		t.Errorf("expected an error serving a path twice on the same address")
+	}
+	debugServersLock.Lock()
+	server := debugServers[address]
+	debugServersLock.Unlock()
+
+	// The server is closed once none of its endpoints is left.
+	cancel1()
+	waitForDebugServer(t, address, func(s *debugServer) bool {
+		if s != server {
+			return false
+		}
+		s.lock.RLock()
+		defer s.lock.RUnlock()
+		_, ok := s.handlers["/debug/a"]
+		return !ok && len(s.handlers) == 1
+	})
+	cancel2()
+	waitForDebugServer(t, address, func(s *debugServer) bool {
+		return s == nil
+	})
+}
+
+func TestServeDebugListenError(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	if err := ServeDebug(context.Background(), logr.Discard(), listener.Addr().String(), "/debug/test", http.NotFoundHandler()); err == nil {
+		t.Errorf("expected an error serving on an address in use")
+	}
+}
+
+func waitForDebugServer(t *testing.T, address string, condition func(*debugServer) bool) {
+	t.Helper()
+	err := wait.PollUntilContextTimeout(context.Background(), 10*time.Millisecond, wait.ForeverTestTimeout, true, func(context.Context) (bool, error) {
+		debugServersLock.Lock()
+		defer debugServersLock.Unlock()
+		return condition(debugServers[address]), nil
+	})
+	if err != nil {
+		t.Errorf("debug server on %v not in the expected state: %v", address, err)
+	}
+}
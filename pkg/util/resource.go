@@ -44,15 +44,25 @@ func ResourceList(r *framework.Resource) v1.ResourceList {
 
 // GetPodEffectiveRequest gets the effective request resource of a pod to the origin resource.
 // The Pod's effective request is the higher of:
-// - the sum of all app containers(spec.Containers) request for a resource.
+// - the sum of all app containers(spec.Containers) and restartable init containers (sidecars) request for a resource.
 // - the effective init containers(spec.InitContainers) request for a resource.
-// The effective init containers request is the highest request on all init containers.
+// The effective init containers request is the highest request on all init containers, including the requests of
+// the restartable init containers started before each of them.
 func GetPodEffectiveRequest(pod *v1.Pod) v1.ResourceList {
 	initResources := make(v1.ResourceList)
+	sidecarResources := make(v1.ResourceList)
 	resources := make(v1.ResourceList)
 
 	for _, container := range pod.Spec.InitContainers {
+		if IsRestartableInitContainer(&container) {
+			addResourceList(sidecarResources, container.Resources.Requests)
+			addResourceList(resources, container.Resources.Requests)
+			continue
+		}
 		for name, quantity := range container.Resources.Requests {
+			if q, ok := sidecarResources[name]; ok {
+				quantity.Add(q)
+			}
 			if q, ok := initResources[name]; ok && quantity.Cmp(q) <= 0 {
 				continue
 			}
@@ -60,12 +70,7 @@ func GetPodEffectiveRequest(pod *v1.Pod) v1.ResourceList {
 		}
 	}
 	for _, container := range pod.Spec.Containers {
-		for name, quantity := range container.Resources.Requests {
-			if q, ok := resources[name]; ok {
-				quantity.Add(q)
-			}
-			resources[name] = quantity
-		}
+		addResourceList(resources, container.Resources.Requests)
 	}
 	for name, quantity := range initResources {
 		if q, ok := resources[name]; ok && quantity.Cmp(q) <= 0 {
@@ -75,3 +80,21 @@ func GetPodEffectiveRequest(pod *v1.Pod) v1.ResourceList {
 	}
 	return resources
 }
+
+// IsRestartableInitContainer returns true if the init container is a restartable one, aka a sidecar,
+// which keeps running alongside the app containers.
+func IsRestartableInitContainer(initContainer *v1.Container) bool {
+	return initContainer.RestartPolicy != nil && *initContainer.RestartPolicy == v1.ContainerRestartPolicyAlways
+}
+
+// addResourceList adds the resources in newList to list
+func addResourceList(list, newList v1.ResourceList) {
+	for name, quantity := range newList {
+		if value, ok := list[name]; !ok {
+			list[name] = quantity.DeepCopy()
+		} else {
+			value.Add(quantity)
+			list[name] = value
+		}
+	}
+}
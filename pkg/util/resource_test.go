@@ -36,7 +36,9 @@ func TestGetPodEffectiveRequest(t *testing.T) {
 		name                 string
 		containerRequest     []v1.ResourceList
 		initContainerRequest []v1.ResourceList
-		want                 v1.ResourceList
+		// sidecars flags the restartable init containers, by index in initContainerRequest
+		sidecars []bool
+		want     v1.ResourceList
 	}{
 		{
 			name: "1 container",
@@ -101,6 +103,44 @@ func TestGetPodEffectiveRequest(t *testing.T) {
 			},
 			want: makeResourceList(10, 4),
 		},
+		{
+			name: "2 containers and 1 sidecar",
+			containerRequest: []v1.ResourceList{
+				makeResourceList(1, 1),
+				makeResourceList(2, 3),
+			},
+			initContainerRequest: []v1.ResourceList{
+				makeResourceList(1, 2),
+			},
+			sidecars: []bool{true},
+			want:     makeResourceList(4, 6),
+		},
+		{
+			name: "2 containers, 1 sidecar and 1 init container started after the sidecar",
+			containerRequest: []v1.ResourceList{
+				makeResourceList(1, 1),
+				makeResourceList(2, 3),
+			},
+			initContainerRequest: []v1.ResourceList{
+				makeResourceList(2, 1),
+				makeResourceList(5, 1),
+			},
+			sidecars: []bool{true, false},
+			want:     makeResourceList(7, 5),
+		},
+		{
+			name: "2 containers, 1 init container started before 1 sidecar",
+			containerRequest: []v1.ResourceList{
+				makeResourceList(1, 1),
+				makeResourceList(2, 3),
+			},
+			initContainerRequest: []v1.ResourceList{
+				makeResourceList(5, 1),
+				makeResourceList(2, 1),
+			},
+			sidecars: []bool{false, true},
+			want:     makeResourceList(5, 5),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -112,12 +152,17 @@ func TestGetPodEffectiveRequest(t *testing.T) {
 					},
 				})
 			}
-			for _, request := range tt.initContainerRequest {
-				pod.Spec.InitContainers = append(pod.Spec.InitContainers, v1.Container{
+			for idx, request := range tt.initContainerRequest {
+				container := v1.Container{
 					Resources: v1.ResourceRequirements{
 						Requests: request,
 					},
-				})
+				}
+				if idx < len(tt.sidecars) && tt.sidecars[idx] {
+					restartPolicy := v1.ContainerRestartPolicyAlways
+					container.RestartPolicy = &restartPolicy
+				}
+				pod.Spec.InitContainers = append(pod.Spec.InitContainers, container)
 			}
 			if got := GetPodEffectiveRequest(pod); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetPodEffectiveRequest() = %v, want %v", got, tt.want)